@@ -10,6 +10,7 @@ import (
 	"syscall"
 	"time"
 
+	metrics "github.com/armon/go-metrics"
 	"github.com/gogo/protobuf/proto"
 	abci "github.com/tendermint/tendermint/abci/types"
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
@@ -408,7 +409,7 @@ func (app *BaseApp) Query(req abci.RequestQuery) (res abci.ResponseQuery) {
 
 	path := splitPath(req.Path)
 	if len(path) == 0 {
-		sdkerrors.QueryResult(sdkerrors.Wrap(sdkerrors.ErrUnknownRequest, "no query path provided"))
+		return sdkerrors.QueryResult(sdkerrors.Wrap(sdkerrors.ErrUnknownRequest, "no query path provided"))
 	}
 
 	switch path[0] {
@@ -582,7 +583,7 @@ func gRPCErrorToSDKError(err error) error {
 		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
 	case codes.FailedPrecondition:
 		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
-	case codes.Unauthenticated:
+	case codes.Unauthenticated, codes.PermissionDenied:
 		return sdkerrors.Wrap(sdkerrors.ErrUnauthorized, err.Error())
 	default:
 		return sdkerrors.Wrap(sdkerrors.ErrUnknownRequest, err.Error())
@@ -608,8 +609,18 @@ func (app *BaseApp) createQueryContext(height int64, prove bool) (sdk.Context, e
 	}
 
 	// when a client did not provide a query height, manually inject the latest
+	lastBlockHeight := app.LastBlockHeight()
 	if height == 0 {
-		height = app.LastBlockHeight()
+		height = lastBlockHeight
+	}
+
+	// the state of a height which was not committed yet would read as empty
+	if height > lastBlockHeight {
+		return sdk.Context{},
+			sdkerrors.Wrapf(
+				sdkerrors.ErrInvalidHeight,
+				"cannot query with height %d in the future (latest height: %d)", height, lastBlockHeight,
+			)
 	}
 
 	if height <= 1 && prove {
@@ -629,10 +640,11 @@ func (app *BaseApp) createQueryContext(height int64, prove bool) (sdk.Context, e
 			)
 	}
 
-	// cache wrap the commit-multistore for safety
+	// cache wrap the commit-multistore for safety, the context being at the
+	// height the query resolved to
 	ctx := sdk.NewContext(
 		cacheMS, app.checkState.ctx.BlockHeader(), true, app.logger,
-	).WithMinGasPrices(app.minGasPrices)
+	).WithMinGasPrices(app.minGasPrices).WithBlockHeight(height)
 
 	return ctx, nil
 }
@@ -836,17 +848,27 @@ func handleQueryCustom(app *BaseApp, path []string, req abci.RequestQuery) abci.
 		return sdkerrors.QueryResult(sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "no custom querier found for route %s", path[1]))
 	}
 
+	// Queriers dispatch on the first element of the path they are given, so
+	// they must not be called with an empty one.
+	if len(path) < 3 || path[2] == "" {
+		return sdkerrors.QueryResult(sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "no endpoint specified for custom query route %s", path[1]))
+	}
+
 	ctx, err := app.createQueryContext(req.Height, req.Prove)
 	if err != nil {
 		return sdkerrors.QueryResult(err)
 	}
 
+	labels := []metrics.Label{telemetry.NewLabel("route", path[1])}
+	defer telemetry.MeasureSinceWithLabels([]string{"query", "custom"}, time.Now(), labels)
+
 	// Passes the rest of the path as an argument to the querier.
 	//
 	// For example, in the path "custom/gov/proposal/test", the gov querier gets
 	// []string{"proposal", "test"} as the path.
 	resBytes, err := querier(ctx, path[2:], req)
 	if err != nil {
+		telemetry.IncrCounterWithLabels([]string{"query", "custom", "errors"}, 1, labels)
 		res := sdkerrors.QueryResult(err)
 		res.Height = req.Height
 		return res
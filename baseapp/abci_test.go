@@ -1,6 +1,7 @@
 package baseapp
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -10,6 +11,7 @@ import (
 	dbm "github.com/tendermint/tm-db"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 )
 
 func TestGetBlockRentionHeight(t *testing.T) {
@@ -139,3 +141,23 @@ func TestBaseAppCreateQueryContextRejectsNegativeHeights(t *testing.T) {
 		})
 	}
 }
+
+func TestBaseAppCreateQueryContextRejectsFutureHeights(t *testing.T) {
+	t.Parallel()
+
+	app := NewBaseApp(t.Name(), defaultLogger(), dbm.NewMemDB(), nil)
+	capKey := sdk.NewKVStoreKey("main")
+	app.MountStores(capKey)
+	require.NoError(t, app.LoadLatestVersion())
+
+	app.InitChain(abci.RequestInitChain{})
+	app.BeginBlock(abci.RequestBeginBlock{Header: tmprototypes.Header{Height: 1}})
+	app.Commit()
+
+	_, err := app.createQueryContext(1, false)
+	require.NoError(t, err)
+
+	sctx, err := app.createQueryContext(2, false)
+	require.True(t, errors.Is(err, sdkerrors.ErrInvalidHeight), err)
+	require.Equal(t, sdk.Context{}, sctx)
+}
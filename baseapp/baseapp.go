@@ -59,6 +59,7 @@ type BaseApp struct { // nolint: maligned
 	txDecoder         sdk.TxDecoder // unmarshal []byte into sdk.Tx
 
 	anteHandler    sdk.AnteHandler  // ante handler for fee and auth
+	postHandler    sdk.AnteHandler  // post handler, run after the msgs of a tx succeeded
 	initChainer    sdk.InitChainer  // initialize state with validators and state blob
 	beginBlocker   sdk.BeginBlocker // logic to run before any txs
 	endBlocker     sdk.EndBlocker   // logic to run after all txs, and to determine valset changes
@@ -663,6 +664,19 @@ func (app *BaseApp) runTx(mode runTxMode, txBytes []byte) (gInfo sdk.GasInfo, re
 	// and we're in DeliverTx. Note, runMsgs will never return a reference to a
 	// Result if any single message fails or does not have a registered Handler.
 	result, err = app.runMsgs(runMsgCtx, msgs, mode)
+
+	// The post handler only runs once all the msgs were executed successfully,
+	// on the same cache-wrapped MultiStore, so that its state changes are
+	// discarded along with theirs if it fails.
+	if err == nil && app.postHandler != nil && mode != runTxModeCheck && mode != runTxModeReCheck {
+		postCtx := runMsgCtx.WithEventManager(sdk.NewEventManager())
+		if _, err = app.postHandler(postCtx, tx, mode == runTxModeSimulate); err != nil {
+			return gInfo, nil, err
+		}
+
+		result.Events = append(result.Events, postCtx.EventManager().ABCIEvents()...)
+	}
+
 	if err == nil && mode == runTxModeDeliver {
 		msCache.Write()
 
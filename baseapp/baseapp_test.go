@@ -2,6 +2,7 @@ package baseapp
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
@@ -530,6 +531,9 @@ func TestBaseAppOptionSeal(t *testing.T) {
 	require.Panics(t, func() {
 		app.SetAnteHandler(nil)
 	})
+	require.Panics(t, func() {
+		app.SetPostHandler(nil)
+	})
 	require.Panics(t, func() {
 		app.SetAddrPeerFilter(nil)
 	})
@@ -1007,6 +1011,89 @@ func TestDeliverTx(t *testing.T) {
 	}
 }
 
+// Test that the post handler runs after the msgs of a tx succeeded, and that
+// its failure discards their state changes but not the ante handler's.
+func TestPostHandler(t *testing.T) {
+	failPost := false
+
+	// each handler writes under its name and the tx counter
+	setKey := func(ctx sdk.Context, name string, tx sdk.Tx) {
+		ctx.KVStore(capKey1).Set([]byte(fmt.Sprintf("%s-%d", name, tx.(txTest).Counter)), []byte(name))
+	}
+
+	anteOpt := func(bapp *BaseApp) {
+		bapp.SetAnteHandler(func(ctx sdk.Context, tx sdk.Tx, simulate bool) (sdk.Context, error) {
+			setKey(ctx, "ante", tx)
+			return ctx, nil
+		})
+		bapp.SetPostHandler(func(ctx sdk.Context, tx sdk.Tx, simulate bool) (sdk.Context, error) {
+			if failPost {
+				return ctx, sdkerrors.Wrap(sdkerrors.ErrUnauthorized, "post handler failure")
+			}
+
+			setKey(ctx, "post", tx)
+			ctx.EventManager().EmitEvents(counterEvent("post_handler", tx.(txTest).Counter))
+			return ctx, nil
+		})
+	}
+	routerOpt := func(bapp *BaseApp) {
+		bapp.Router().AddRoute(sdk.NewRoute(routeMsgCounter, func(ctx sdk.Context, msg sdk.Msg) (*sdk.Result, error) {
+			m := msg.(*msgCounter)
+			if m.FailOnHandler {
+				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "message handler failure")
+			}
+
+			ctx.KVStore(capKey1).Set([]byte(fmt.Sprintf("deliver-%d", m.Counter)), []byte("deliver"))
+			return &sdk.Result{}, nil
+		}))
+	}
+
+	app := setupBaseApp(t, anteOpt, routerOpt)
+	app.InitChain(abci.RequestInitChain{})
+
+	testCases := []struct {
+		name       string
+		failMsg    bool
+		failPost   bool
+		expWritten []string
+		expSkipped []string
+	}{
+		{"msgs and post handler succeed", false, false, []string{"ante", "deliver", "post"}, nil},
+		{"post handler fails", false, true, []string{"ante"}, []string{"deliver", "post"}},
+		{"msgs fail", true, false, []string{"ante"}, []string{"deliver", "post"}},
+	}
+
+	for i, tc := range testCases {
+		counter := int64(i)
+		header := tmproto.Header{Height: counter + 1}
+		app.BeginBlock(abci.RequestBeginBlock{Header: header})
+
+		failPost = tc.failPost
+		tx := newTxCounter(counter, counter)
+		tx.setFailOnHandler(tc.failMsg)
+
+		_, result, err := app.Deliver(aminoTxEncoder(), tx)
+		if tc.failMsg || tc.failPost {
+			require.Error(t, err, tc.name)
+			require.Nil(t, result, tc.name)
+		} else {
+			require.NoError(t, err, tc.name)
+			require.Contains(t, result.Events, counterEvent("post_handler", counter).ToABCIEvents()[0], tc.name)
+		}
+
+		store := app.deliverState.ctx.KVStore(capKey1)
+		for _, name := range tc.expWritten {
+			require.NotNil(t, store.Get([]byte(fmt.Sprintf("%s-%d", name, counter))), "%s: %s", tc.name, name)
+		}
+		for _, name := range tc.expSkipped {
+			require.Nil(t, store.Get([]byte(fmt.Sprintf("%s-%d", name, counter))), "%s: %s", tc.name, name)
+		}
+
+		app.EndBlock(abci.RequestEndBlock{})
+		app.Commit()
+	}
+}
+
 // Number of messages doesn't matter to CheckTx.
 func TestMultiMsgCheckTx(t *testing.T) {
 	// TODO: ensure we get the same results
@@ -1699,6 +1786,26 @@ func TestQuery(t *testing.T) {
 	require.Equal(t, value, res.Value)
 }
 
+func TestQueryEmptyPath(t *testing.T) {
+	app := setupBaseApp(t)
+	app.InitChain(abci.RequestInitChain{})
+
+	// the querier reads the first path element, as module queriers do
+	app.QueryRouter().AddRoute("test", func(_ sdk.Context, path []string, _ abci.RequestQuery) ([]byte, error) {
+		return []byte(path[0]), nil
+	})
+
+	res := app.Query(abci.RequestQuery{Path: "/custom/test/endpoint"})
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, []byte("endpoint"), res.Value)
+
+	for _, path := range []string{"", "/", "/custom/test", "/custom/test/"} {
+		res := app.Query(abci.RequestQuery{Path: path})
+		require.Equal(t, sdkerrors.ErrUnknownRequest.ABCICode(), res.Code, "path %q: %s", path, res.Log)
+		require.Equal(t, sdkerrors.ErrUnknownRequest.Codespace(), res.Codespace, path)
+	}
+}
+
 func TestGRPCQuery(t *testing.T) {
 	grpcQueryOpt := func(bapp *BaseApp) {
 		testdata.RegisterQueryServer(
@@ -1733,6 +1840,56 @@ func TestGRPCQuery(t *testing.T) {
 	require.Equal(t, "Hello foo!", res.Greeting)
 }
 
+// countingQueryServer counts the SayHello queries reaching it.
+type countingQueryServer struct {
+	testdata.QueryImpl
+	calls *int
+}
+
+func (q countingQueryServer) SayHello(ctx context.Context, req *testdata.SayHelloRequest) (*testdata.SayHelloResponse, error) {
+	*q.calls++
+	return q.QueryImpl.SayHello(ctx, req)
+}
+
+func TestGRPCQueryCacheLatestHeight(t *testing.T) {
+	var calls int
+	grpcQueryOpt := func(bapp *BaseApp) {
+		bapp.grpcQueryRouter = NewGRPCQueryRouter(WithCache(10))
+		testdata.RegisterQueryServer(
+			bapp.GRPCQueryRouter(),
+			countingQueryServer{calls: &calls},
+		)
+	}
+
+	app := setupBaseApp(t, grpcQueryOpt)
+	app.InitChain(abci.RequestInitChain{})
+
+	commit := func() {
+		header := tmproto.Header{Height: app.LastBlockHeight() + 1}
+		app.BeginBlock(abci.RequestBeginBlock{Header: header})
+		app.Commit()
+	}
+
+	reqBz, err := (&testdata.SayHelloRequest{Name: "foo"}).Marshal()
+	require.NoError(t, err)
+	reqQuery := abci.RequestQuery{Data: reqBz, Path: "/testdata.Query/SayHello"}
+
+	commit()
+	res := app.Query(reqQuery)
+	require.Equal(t, abci.CodeTypeOK, res.Code, res)
+	require.Equal(t, int64(1), res.Height)
+	res = app.Query(reqQuery)
+	require.Equal(t, abci.CodeTypeOK, res.Code, res)
+	require.Equal(t, 1, calls)
+
+	// the latest height moved, so the cached response must not be served
+	commit()
+	res = app.Query(reqQuery)
+	require.Equal(t, abci.CodeTypeOK, res.Code, res)
+	require.Equal(t, int64(2), res.Height)
+	require.Equal(t, 2, calls)
+}
+
 // Test p2p filter queries
 func TestP2PQuery(t *testing.T) {
 	addrPeerFilterOpt := func(bapp *BaseApp) {
@@ -2,25 +2,49 @@ package baseapp
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	metrics "github.com/armon/go-metrics"
 	gogogrpc "github.com/gogo/protobuf/grpc"
+	lru "github.com/hashicorp/golang-lru"
 	abci "github.com/tendermint/tendermint/abci/types"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/encoding"
 	"google.golang.org/grpc/encoding/proto"
+	"google.golang.org/grpc/status"
 
 	"github.com/cosmos/cosmos-sdk/client/grpc/reflection"
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/telemetry"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 )
 
 var protoCodec = encoding.GetCodec(proto.Name)
 
+// DefaultMaxQueryRequestBytes is the default size limit of the data of the
+// requests routed by a GRPCQueryRouter, matching the default limit on the size
+// of the messages received by a gRPC server.
+const DefaultMaxQueryRequestBytes = 4 << 20
+
 // GRPCQueryRouter routes ABCI Query requests to GRPC handlers
 type GRPCQueryRouter struct {
+	mtx               sync.RWMutex
+	cdc               encoding.Codec
 	routes            map[string]GRPCQueryHandler
+	streams           map[string]streamRoute
 	interfaceRegistry codectypes.InterfaceRegistry
 	serviceData       []serviceData
+
+	// maxRequestBytes is the size limit of request data, 0 if unlimited
+	maxRequestBytes int
+
+	// cache holds query responses at the latest queried height, nil if disabled
+	cache *queryCache
 }
 
 // serviceData represents a gRPC service, along with its handler.
@@ -29,13 +53,68 @@ type serviceData struct {
 	handler     interface{}
 }
 
+// streamRoute is a streaming method of a gRPC service, along with the handler
+// of the service.
+type streamRoute struct {
+	handler grpc.StreamHandler
+	srv     interface{}
+}
+
 var _ gogogrpc.Server = &GRPCQueryRouter{}
 
+// GRPCQueryRouterOption is an option for NewGRPCQueryRouter.
+type GRPCQueryRouterOption func(*GRPCQueryRouter)
+
+// WithCodec sets the codec used to decode query requests and encode query
+// responses. It defaults to the gRPC proto codec.
+func WithCodec(cdc encoding.Codec) GRPCQueryRouterOption {
+	return func(qrt *GRPCQueryRouter) {
+		qrt.cdc = cdc
+	}
+}
+
+// WithCache enables an LRU cache holding up to size query responses, keyed by
+// query path, height and request bytes. The height is the block height of the
+// query context, to which a query at the latest height resolves, so that its
+// responses are not served once a new block is committed. Cached responses are
+// dropped whenever a query at a different height is received. A size <= 0
+// disables the cache.
+func WithCache(size int) GRPCQueryRouterOption {
+	return func(qrt *GRPCQueryRouter) {
+		if size <= 0 {
+			qrt.cache = nil
+			return
+		}
+		qrt.cache = newQueryCache(size)
+	}
+}
+
+// WithMaxRequestBytes sets the size limit of the data of query requests, which
+// defaults to DefaultMaxQueryRequestBytes. Larger requests are rejected with
+// codes.InvalidArgument before being decoded. A limit <= 0 disables the check.
+func WithMaxRequestBytes(limit int) GRPCQueryRouterOption {
+	return func(qrt *GRPCQueryRouter) {
+		if limit <= 0 {
+			limit = 0
+		}
+		qrt.maxRequestBytes = limit
+	}
+}
+
 // NewGRPCQueryRouter creates a new GRPCQueryRouter
-func NewGRPCQueryRouter() *GRPCQueryRouter {
-	return &GRPCQueryRouter{
-		routes: map[string]GRPCQueryHandler{},
+func NewGRPCQueryRouter(opts ...GRPCQueryRouterOption) *GRPCQueryRouter {
+	qrt := &GRPCQueryRouter{
+		cdc:             protoCodec,
+		routes:          map[string]GRPCQueryHandler{},
+		streams:         map[string]streamRoute{},
+		maxRequestBytes: DefaultMaxQueryRequestBytes,
 	}
+
+	for _, opt := range opts {
+		opt(qrt)
+	}
+
+	return qrt
 }
 
 // GRPCQueryHandler defines a function type which handles ABCI Query requests
@@ -45,6 +124,9 @@ type GRPCQueryHandler = func(ctx sdk.Context, req abci.RequestQuery) (abci.Respo
 // Route returns the GRPCQueryHandler for a given query route path or nil
 // if not found
 func (qrt *GRPCQueryRouter) Route(path string) GRPCQueryHandler {
+	qrt.mtx.RLock()
+	defer qrt.mtx.RUnlock()
+
 	handler, found := qrt.routes[path]
 	if !found {
 		return nil
@@ -52,16 +134,49 @@ func (qrt *GRPCQueryRouter) Route(path string) GRPCQueryHandler {
 	return handler
 }
 
+// Routes returns the fully qualified names of all the routed query methods,
+// e.g. "/cosmos.bank.v1beta1.Query/Balance", in lexical order.
+func (qrt *GRPCQueryRouter) Routes() []string {
+	qrt.mtx.RLock()
+	defer qrt.mtx.RUnlock()
+
+	paths := make([]string, 0, len(qrt.routes))
+	for path := range qrt.routes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
 // RegisterService implements the gRPC Server.RegisterService method. sd is a gRPC
 // service description, handler is an object which implements that gRPC service/
 //
 // This functions PANICS:
 // - if a protobuf service is registered twice.
+// - if the service description has no methods.
 func (qrt *GRPCQueryRouter) RegisterService(sd *grpc.ServiceDesc, handler interface{}) {
+	qrt.mtx.Lock()
+	defer qrt.mtx.Unlock()
+
+	for _, data := range qrt.serviceData {
+		if data.serviceDesc.ServiceName == sd.ServiceName {
+			panic(fmt.Errorf("gRPC query service %s has already been registered", sd.ServiceName))
+		}
+	}
+
+	if len(sd.Methods) == 0 {
+		panic(fmt.Errorf("gRPC query service %s has no methods", sd.ServiceName))
+	}
+
 	// adds a top-level query handler based on the gRPC service name
 	for _, method := range sd.Methods {
 		fqName := fmt.Sprintf("/%s/%s", sd.ServiceName, method.MethodName)
 		methodHandler := method.Handler
+		labels := []metrics.Label{
+			telemetry.NewLabel("service", sd.ServiceName),
+			telemetry.NewLabel("method", method.MethodName),
+		}
 
 		// Check that each service is only registered once. If a service is
 		// registered more than once, then we should error. Since we can't
@@ -79,12 +194,28 @@ func (qrt *GRPCQueryRouter) RegisterService(sd *grpc.ServiceDesc, handler interf
 		}
 
 		qrt.routes[fqName] = func(ctx sdk.Context, req abci.RequestQuery) (abci.ResponseQuery, error) {
+			defer telemetry.MeasureSinceWithLabels([]string{"query", "grpc"}, time.Now(), labels)
+
+			// reject oversized requests before decoding allocates for them
+			if qrt.maxRequestBytes > 0 && len(req.Data) > qrt.maxRequestBytes {
+				telemetry.IncrCounterWithLabels([]string{"query", "grpc", "errors"}, 1, labels)
+				return abci.ResponseQuery{}, status.Errorf(
+					codes.InvalidArgument, "query request of %d bytes exceeds the limit of %d bytes", len(req.Data), qrt.maxRequestBytes,
+				)
+			}
+
+			if qrt.cache != nil {
+				if res, ok := qrt.cache.get(fqName, ctx.BlockHeight(), req.Data); ok {
+					return res, nil
+				}
+			}
+
 			// call the method handler from the service description with the handler object,
 			// a wrapped sdk.Context with proto-unmarshaled data from the ABCI request data
 			res, err := methodHandler(handler, sdk.WrapSDKContext(ctx), func(i interface{}) error {
-				err := protoCodec.Unmarshal(req.Data, i)
+				err := qrt.cdc.Unmarshal(req.Data, i)
 				if err != nil {
-					return err
+					return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
 				}
 				if qrt.interfaceRegistry != nil {
 					return codectypes.UnpackInterfaces(i, qrt.interfaceRegistry)
@@ -92,20 +223,36 @@ func (qrt *GRPCQueryRouter) RegisterService(sd *grpc.ServiceDesc, handler interf
 				return nil
 			}, nil)
 			if err != nil {
-				return abci.ResponseQuery{}, err
+				telemetry.IncrCounterWithLabels([]string{"query", "grpc", "errors"}, 1, labels)
+				return abci.ResponseQuery{}, sdkErrorToGRPCError(err)
 			}
 
 			// proto marshal the result bytes
-			resBytes, err := protoCodec.Marshal(res)
+			resBytes, err := qrt.cdc.Marshal(res)
 			if err != nil {
+				telemetry.IncrCounterWithLabels([]string{"query", "grpc", "errors"}, 1, labels)
 				return abci.ResponseQuery{}, err
 			}
 
 			// return the result bytes as the response value
-			return abci.ResponseQuery{
+			resp := abci.ResponseQuery{
 				Height: req.Height,
 				Value:  resBytes,
-			}, nil
+			}
+			if qrt.cache != nil {
+				qrt.cache.add(fqName, ctx.BlockHeight(), req.Data, resp)
+			}
+
+			return resp, nil
+		}
+	}
+
+	// streaming methods cannot be routed for ABCI queries, they are indexed for
+	// QueryServiceTestHelper
+	for _, stream := range sd.Streams {
+		qrt.streams[fmt.Sprintf("/%s/%s", sd.ServiceName, stream.StreamName)] = streamRoute{
+			handler: stream.Handler,
+			srv:     handler,
 		}
 	}
 
@@ -115,6 +262,77 @@ func (qrt *GRPCQueryRouter) RegisterService(sd *grpc.ServiceDesc, handler interf
 	})
 }
 
+// RemoveRoute removes either a single method, given its fully qualified name
+// (e.g. "/cosmos.bank.v1beta1.Query/Balance"), or a whole service, given its
+// name (e.g. "cosmos.bank.v1beta1.Query"), returning whether anything was
+// removed. Once all of its methods are removed a service may be registered
+// again.
+func (qrt *GRPCQueryRouter) RemoveRoute(path string) bool {
+	qrt.mtx.Lock()
+	defer qrt.mtx.Unlock()
+
+	_, isMethod := qrt.routes[path]
+	if isMethod {
+		delete(qrt.routes, path)
+	}
+
+	for i, data := range qrt.serviceData {
+		sd := data.serviceDesc
+		if isMethod {
+			// drop the service once none of its methods are routed anymore
+			if !strings.HasPrefix(path, "/"+sd.ServiceName+"/") || qrt.hasRoutes(sd) {
+				continue
+			}
+		} else if sd.ServiceName != path {
+			continue
+		}
+
+		for _, method := range sd.Methods {
+			delete(qrt.routes, fmt.Sprintf("/%s/%s", sd.ServiceName, method.MethodName))
+		}
+		for _, stream := range sd.Streams {
+			delete(qrt.streams, fmt.Sprintf("/%s/%s", sd.ServiceName, stream.StreamName))
+		}
+		qrt.serviceData = append(qrt.serviceData[:i], qrt.serviceData[i+1:]...)
+		return true
+	}
+
+	return isMethod
+}
+
+// hasRoutes returns whether any method of the service is routed. It must be
+// called with mtx held.
+func (qrt *GRPCQueryRouter) hasRoutes(sd *grpc.ServiceDesc) bool {
+	for _, method := range sd.Methods {
+		if _, found := qrt.routes[fmt.Sprintf("/%s/%s", sd.ServiceName, method.MethodName)]; found {
+			return true
+		}
+	}
+	return false
+}
+
+// sdkErrorToGRPCError converts an error returned by a query handler into a gRPC
+// status error, keeping the error's message. Errors which already carry a gRPC
+// status are returned unchanged.
+func sdkErrorToGRPCError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch {
+	case sdkerrors.ErrUnknownRequest.Is(err), sdkerrors.ErrKeyNotFound.Is(err):
+		return status.Error(codes.NotFound, err.Error())
+	case sdkerrors.ErrInvalidRequest.Is(err), sdkerrors.ErrInvalidAddress.Is(err),
+		sdkerrors.ErrInvalidCoins.Is(err), sdkerrors.ErrInvalidType.Is(err):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case sdkerrors.ErrUnauthorized.Is(err):
+		// the signer is known but not allowed, rather than unauthenticated
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return status.Error(codes.Unknown, err.Error())
+	}
+}
+
 // SetInterfaceRegistry sets the interface registry for the router. This will
 // also register the interface reflection gRPC service.
 func (qrt *GRPCQueryRouter) SetInterfaceRegistry(interfaceRegistry codectypes.InterfaceRegistry) {
@@ -127,3 +345,56 @@ func (qrt *GRPCQueryRouter) SetInterfaceRegistry(interfaceRegistry codectypes.In
 		reflection.NewReflectionServiceServer(interfaceRegistry),
 	)
 }
+
+// queryCache is an LRU cache of query responses for a single height.
+type queryCache struct {
+	mtx     sync.Mutex
+	height  int64
+	entries *lru.Cache
+}
+
+func newQueryCache(size int) *queryCache {
+	entries, err := lru.New(size)
+	if err != nil {
+		panic(fmt.Errorf("failed to create query cache: %s", err))
+	}
+
+	return &queryCache{entries: entries}
+}
+
+// get returns the cached response to the request data on the given path at
+// height, if any.
+func (c *queryCache) get(path string, height int64, data []byte) (abci.ResponseQuery, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.setHeight(height)
+
+	res, ok := c.entries.Get(queryCacheKey(path, height, data))
+	if !ok {
+		return abci.ResponseQuery{}, false
+	}
+	return res.(abci.ResponseQuery), true
+}
+
+// add caches the response to the request data on the given path at height.
+func (c *queryCache) add(path string, height int64, data []byte, res abci.ResponseQuery) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.setHeight(height)
+	c.entries.Add(queryCacheKey(path, height, data), res)
+}
+
+// setHeight purges the cache if height differs from the height of the cached
+// responses. It must be called with mtx held.
+func (c *queryCache) setHeight(height int64) {
+	if height != c.height {
+		c.entries.Purge()
+		c.height = height
+	}
+}
+
+func queryCacheKey(path string, height int64, data []byte) string {
+	return fmt.Sprintf("%s/%d/%X", path, height, data)
+}
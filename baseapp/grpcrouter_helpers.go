@@ -2,11 +2,17 @@ package baseapp
 
 import (
 	gocontext "context"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 
 	gogogrpc "github.com/gogo/protobuf/grpc"
 	abci "github.com/tendermint/tendermint/abci/types"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/cosmos/cosmos-sdk/codec/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -19,6 +25,16 @@ import (
 type QueryServiceTestHelper struct {
 	*GRPCQueryRouter
 	Ctx sdk.Context
+
+	// height is the block height queries are run at, 0 meaning the state of Ctx
+	height int64
+}
+
+// versionedMultiStore is implemented by multistores which can load the state
+// at a past height, such as the root multistore.
+type versionedMultiStore interface {
+	CacheMultiStoreWithVersion(version int64) (sdk.CacheMultiStore, error)
+	LastCommitID() sdk.CommitID
 }
 
 var (
@@ -26,6 +42,52 @@ var (
 	_ gogogrpc.ClientConn = &QueryServiceTestHelper{}
 )
 
+var (
+	// ErrInvalidMethodName is returned by QueryServiceTestHelper for a method
+	// name which is not of the form /service/method.
+	ErrInvalidMethodName = errors.New("invalid method name")
+
+	// ErrHandlerNotFound is returned by QueryServiceTestHelper for a method
+	// without a registered handler.
+	ErrHandlerNotFound = errors.New("handler not found")
+)
+
+// helperError wraps one of the QueryServiceTestHelper errors with details,
+// while carrying the gRPC status code a client would receive from a server.
+type helperError struct {
+	err  error
+	code codes.Code
+	msg  string
+}
+
+func (e helperError) Error() string              { return e.msg }
+func (e helperError) Unwrap() error              { return e.err }
+func (e helperError) GRPCStatus() *status.Status { return status.New(e.code, e.msg) }
+
+// checkMethod returns an error wrapping ErrInvalidMethodName if method is not
+// of the form /service/method.
+func checkMethod(method string) error {
+	segments := strings.Split(method, "/")
+	if len(segments) != 3 || segments[0] != "" || segments[1] == "" || segments[2] == "" {
+		return helperError{
+			err:  ErrInvalidMethodName,
+			code: codes.Unimplemented,
+			msg:  fmt.Sprintf("%s %q: expected /service/method", ErrInvalidMethodName, method),
+		}
+	}
+
+	return nil
+}
+
+// handlerNotFound returns an error wrapping ErrHandlerNotFound for method.
+func handlerNotFound(method string) error {
+	return helperError{
+		err:  ErrHandlerNotFound,
+		code: codes.NotFound,
+		msg:  fmt.Sprintf("%s for %s", ErrHandlerNotFound, method),
+	}
+}
+
 // NewQueryServerTestHelper creates a new QueryServiceTestHelper that wraps
 // the provided sdk.Context
 func NewQueryServerTestHelper(ctx sdk.Context, interfaceRegistry types.InterfaceRegistry) *QueryServiceTestHelper {
@@ -34,35 +96,307 @@ func NewQueryServerTestHelper(ctx sdk.Context, interfaceRegistry types.Interface
 	return &QueryServiceTestHelper{GRPCQueryRouter: qrt, Ctx: ctx}
 }
 
-// Invoke implements the grpc ClientConn.Invoke method
-func (q *QueryServiceTestHelper) Invoke(_ gocontext.Context, method string, args, reply interface{}, _ ...grpc.CallOption) error {
+// WithHeight returns a copy of the helper which runs queries against the state
+// at the given height. Ctx's multistore must be able to load past versions,
+// otherwise Invoke returns an error.
+func (q *QueryServiceTestHelper) WithHeight(height int64) *QueryServiceTestHelper {
+	return &QueryServiceTestHelper{GRPCQueryRouter: q.GRPCQueryRouter, Ctx: q.Ctx, height: height}
+}
+
+// Connect registers services with register and returns the helper as the
+// connection to hand to a generated client constructor, so that a test gets a
+// ready client in one call:
+//
+//	queryClient := types.NewQueryClient(helper.Connect(func(s gogogrpc.Server) {
+//		types.RegisterQueryServer(s, keeper)
+//	}))
+//
+// The client's calls go through Invoke and NewStream.
+func (q *QueryServiceTestHelper) Connect(register func(gogogrpc.Server)) gogogrpc.ClientConn {
+	register(q)
+	return q
+}
+
+// queryContext returns the context queries are run with.
+func (q *QueryServiceTestHelper) queryContext() (sdk.Context, error) {
+	if q.height == 0 {
+		return q.Ctx, nil
+	}
+
+	ms, ok := q.Ctx.MultiStore().(versionedMultiStore)
+	if !ok {
+		return sdk.Context{}, fmt.Errorf("cannot query height %d: multistore %T does not support versioned queries", q.height, q.Ctx.MultiStore())
+	}
+
+	if latest := ms.LastCommitID().Version; q.height < 0 || q.height > latest {
+		return sdk.Context{}, fmt.Errorf("cannot query height %d: latest available height is %d", q.height, latest)
+	}
+
+	cacheMS, err := ms.CacheMultiStoreWithVersion(q.height)
+	if err != nil {
+		return sdk.Context{}, fmt.Errorf("failed to load state at height %d: %w", q.height, err)
+	}
+
+	return q.Ctx.WithMultiStore(cacheMS).WithBlockHeight(q.height), nil
+}
+
+// Invoke implements the grpc ClientConn.Invoke method. The query is run with
+// goCtx's deadline and cancellation, and returns a Canceled or DeadlineExceeded
+// status error as soon as goCtx is done, without waiting for the query to return.
+func (q *QueryServiceTestHelper) Invoke(goCtx gocontext.Context, method string, args, reply interface{}, _ ...grpc.CallOption) error {
+	_, err := q.InvokeWithHeight(goCtx, method, args, reply)
+	return err
+}
+
+// InvokeWithHeight is like Invoke, but also returns the block height the query
+// was run at, as an ABCI query response would: the block height of Ctx, or the
+// height set by WithHeight.
+func (q *QueryServiceTestHelper) InvokeWithHeight(goCtx gocontext.Context, method string, args, reply interface{}) (int64, error) {
+	if err := goCtx.Err(); err != nil {
+		return 0, status.FromContextError(err).Err()
+	}
+
+	if err := checkMethod(method); err != nil {
+		return 0, err
+	}
+
 	querier := q.Route(method)
 	if querier == nil {
-		return fmt.Errorf("handler not found for %s", method)
+		return 0, handlerNotFound(method)
 	}
-	reqBz, err := protoCodec.Marshal(args)
+	reqBz, err := q.cdc.Marshal(args)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	res, err := querier(q.Ctx, abci.RequestQuery{Data: reqBz})
+	ctx, err := q.queryContext()
 	if err != nil {
-		return err
+		return 0, err
+	}
+
+	// run the querier aside so that a done context returns promptly, the
+	// querier observing the deadline through its sdk.Context
+	type result struct {
+		res   abci.ResponseQuery
+		err   error
+		panic interface{}
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{panic: r}
+			}
+		}()
+		res, err := querier(ctx.WithContext(goCtx), abci.RequestQuery{Data: reqBz, Height: q.height})
+		done <- result{res: res, err: err}
+	}()
+
+	var res abci.ResponseQuery
+	select {
+	case <-goCtx.Done():
+		return 0, status.FromContextError(goCtx.Err()).Err()
+	case r := <-done:
+		if r.panic != nil {
+			panic(r.panic)
+		}
+		if r.err != nil {
+			return 0, r.err
+		}
+		res = r.res
 	}
 
-	err = protoCodec.Unmarshal(res.Value, reply)
+	err = q.cdc.Unmarshal(res.Value, reply)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if q.interfaceRegistry != nil {
-		return types.UnpackInterfaces(reply, q.interfaceRegistry)
+		if err := types.UnpackInterfaces(reply, q.interfaceRegistry); err != nil {
+			return 0, err
+		}
+	}
+
+	return ctx.BlockHeight(), nil
+}
+
+// NewStream implements the grpc ClientConn.NewStream method. Only server
+// streaming methods are supported: the stream handler is run in-process once
+// the request has been sent, and its responses are then delivered by RecvMsg.
+func (q *QueryServiceTestHelper) NewStream(ctx gocontext.Context, desc *grpc.StreamDesc, method string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+	if desc.ClientStreams {
+		return nil, fmt.Errorf("client streaming is not supported for %s", method)
+	}
+
+	if err := checkMethod(method); err != nil {
+		return nil, err
+	}
+
+	handler, srv := q.streamHandler(method)
+	if handler == nil {
+		return nil, handlerNotFound(method)
+	}
+
+	return &testClientStream{
+		ctx:           ctx,
+		helper:        q,
+		method:        method,
+		handler:       handler,
+		srv:           srv,
+		serverStreams: desc.ServerStreams,
+	}, nil
+}
+
+// streamHandler returns the stream handler registered for the given fully
+// qualified method name, along with the service implementation it is called with.
+func (q *QueryServiceTestHelper) streamHandler(method string) (grpc.StreamHandler, interface{}) {
+	q.mtx.RLock()
+	defer q.mtx.RUnlock()
+
+	route, found := q.streams[method]
+	if !found {
+		return nil, nil
 	}
 
+	return route.handler, route.srv
+}
+
+// testClientStream is an in-process grpc.ClientStream used by
+// QueryServiceTestHelper. The handler is run synchronously the first time a
+// response is requested, and all of its responses are buffered.
+type testClientStream struct {
+	ctx           gocontext.Context
+	helper        *QueryServiceTestHelper
+	method        string
+	handler       grpc.StreamHandler
+	srv           interface{}
+	serverStreams bool
+
+	req       []byte
+	ran       bool
+	responses [][]byte
+	err       error
+}
+
+var _ grpc.ClientStream = &testClientStream{}
+
+func (s *testClientStream) Header() (metadata.MD, error) { return metadata.MD{}, nil }
+func (s *testClientStream) Trailer() metadata.MD         { return metadata.MD{} }
+func (s *testClientStream) Context() gocontext.Context   { return s.ctx }
+
+// CloseSend runs the stream handler, as the full request has been sent.
+func (s *testClientStream) CloseSend() error {
+	s.run()
+	return nil
+}
+
+// SendMsg records the request passed to the stream handler.
+func (s *testClientStream) SendMsg(m interface{}) error {
+	if s.req != nil {
+		return fmt.Errorf("client streaming is not supported for %s", s.method)
+	}
+
+	bz, err := s.helper.cdc.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	s.req = bz
+	return nil
+}
+
+// RecvMsg delivers the next buffered response. It returns io.EOF once all
+// responses have been read, or the handler's error if it failed.
+func (s *testClientStream) RecvMsg(m interface{}) error {
+	s.run()
+
+	if len(s.responses) == 0 {
+		if s.err != nil {
+			return s.err
+		}
+		return io.EOF
+	}
+
+	bz := s.responses[0]
+	s.responses = s.responses[1:]
+
+	if err := s.helper.cdc.Unmarshal(bz, m); err != nil {
+		return err
+	}
+
+	if s.helper.interfaceRegistry != nil {
+		return types.UnpackInterfaces(m, s.helper.interfaceRegistry)
+	}
+
+	return nil
+}
+
+func (s *testClientStream) run() {
+	if s.ran {
+		return
+	}
+	s.ran = true
+
+	ctx, err := s.helper.queryContext()
+	if err != nil {
+		s.err = err
+		return
+	}
+
+	// the handler observes the client's cancellation through its context
+	stream := &testServerStream{ctx: sdk.WrapSDKContext(ctx.WithContext(s.ctx)), client: s}
+	s.err = s.handler(s.srv, stream)
+
+	if !s.serverStreams && len(s.responses) > 1 {
+		s.err = fmt.Errorf("%s is not a server streaming method but sent %d responses", s.method, len(s.responses))
+		s.responses = nil
+	}
+}
+
+// testServerStream is the grpc.ServerStream handed to stream handlers by
+// testClientStream.
+type testServerStream struct {
+	ctx    gocontext.Context
+	client *testClientStream
+	read   bool
+}
+
+var _ grpc.ServerStream = &testServerStream{}
+
+func (s *testServerStream) SetHeader(metadata.MD) error  { return nil }
+func (s *testServerStream) SendHeader(metadata.MD) error { return nil }
+func (s *testServerStream) SetTrailer(metadata.MD)       {}
+func (s *testServerStream) Context() gocontext.Context   { return s.ctx }
+
+// SendMsg buffers a response for the client.
+func (s *testServerStream) SendMsg(m interface{}) error {
+	bz, err := s.client.helper.cdc.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	s.client.responses = append(s.client.responses, bz)
 	return nil
 }
 
-// NewStream implements the grpc ClientConn.NewStream method
-func (q *QueryServiceTestHelper) NewStream(gocontext.Context, *grpc.StreamDesc, string, ...grpc.CallOption) (grpc.ClientStream, error) {
-	return nil, fmt.Errorf("not supported")
+// RecvMsg hands the client's request to the handler.
+func (s *testServerStream) RecvMsg(m interface{}) error {
+	if s.read {
+		return io.EOF
+	}
+	s.read = true
+
+	if s.client.req == nil {
+		return fmt.Errorf("no request sent for %s", s.client.method)
+	}
+
+	if err := s.client.helper.cdc.Unmarshal(s.client.req, m); err != nil {
+		return err
+	}
+
+	if s.client.helper.interfaceRegistry != nil {
+		return types.UnpackInterfaces(m, s.client.helper.interfaceRegistry)
+	}
+
+	return nil
 }
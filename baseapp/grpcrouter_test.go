@@ -2,18 +2,35 @@ package baseapp_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	metrics "github.com/armon/go-metrics"
+	gogogrpc "github.com/gogo/protobuf/grpc"
 	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/libs/log"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 	dbm "github.com/tendermint/tm-db"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/proto"
+	"google.golang.org/grpc/status"
 
 	"github.com/cosmos/cosmos-sdk/baseapp"
 	"github.com/cosmos/cosmos-sdk/codec/types"
 	"github.com/cosmos/cosmos-sdk/simapp"
+	"github.com/cosmos/cosmos-sdk/store/rootmulti"
 	"github.com/cosmos/cosmos-sdk/testutil/testdata"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 )
 
 func TestGRPCGatewayRouter(t *testing.T) {
@@ -74,3 +91,658 @@ func TestRegisterQueryServiceTwice(t *testing.T) {
 		)
 	})
 }
+
+// echoStreamHandler answers an EchoRequest with the message repeated three
+// times, each in its own response.
+func echoStreamHandler(_ interface{}, stream grpc.ServerStream) error {
+	var req testdata.EchoRequest
+	if err := stream.RecvMsg(&req); err != nil {
+		return err
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := stream.SendMsg(&testdata.EchoResponse{Message: fmt.Sprintf("%s %d", req.Message, i)}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var echoStreamServiceDesc = grpc.ServiceDesc{
+	ServiceName: "testdata.EchoStream",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Echo",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				var req testdata.EchoRequest
+				if err := dec(&req); err != nil {
+					return nil, err
+				}
+				return &testdata.EchoResponse{Message: req.Message}, nil
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "EchoStream",
+			Handler:       echoStreamHandler,
+			ServerStreams: true,
+		},
+	},
+}
+
+func TestQueryServiceTestHelperStream(t *testing.T) {
+	helper := baseapp.NewQueryServerTestHelper(sdk.Context{}.WithContext(context.Background()), testdata.NewTestInterfaceRegistry())
+	helper.RegisterService(&echoStreamServiceDesc, struct{}{})
+
+	stream, err := helper.NewStream(context.Background(), &echoStreamServiceDesc.Streams[0], "/testdata.EchoStream/EchoStream")
+	require.NoError(t, err)
+	require.NoError(t, stream.SendMsg(&testdata.EchoRequest{Message: "hello"}))
+	require.NoError(t, stream.CloseSend())
+
+	for i := 0; i < 3; i++ {
+		var res testdata.EchoResponse
+		require.NoError(t, stream.RecvMsg(&res))
+		require.Equal(t, fmt.Sprintf("hello %d", i), res.Message)
+	}
+
+	var res testdata.EchoResponse
+	require.Equal(t, io.EOF, stream.RecvMsg(&res))
+
+	// a unary method descriptor must not yield several responses
+	unary := grpc.StreamDesc{StreamName: "EchoStream"}
+	stream, err = helper.NewStream(context.Background(), &unary, "/testdata.EchoStream/EchoStream")
+	require.NoError(t, err)
+	require.NoError(t, stream.SendMsg(&testdata.EchoRequest{Message: "hello"}))
+	require.Error(t, stream.RecvMsg(&res))
+
+	// client streaming and unknown methods are rejected
+	_, err = helper.NewStream(context.Background(), &grpc.StreamDesc{ClientStreams: true}, "/testdata.EchoStream/EchoStream")
+	require.Error(t, err)
+	_, err = helper.NewStream(context.Background(), &echoStreamServiceDesc.Streams[0], "/testdata.EchoStream/Unknown")
+	require.Error(t, err)
+
+	// so are the streams of a removed service
+	require.True(t, helper.RemoveRoute("testdata.EchoStream"))
+	_, err = helper.NewStream(context.Background(), &echoStreamServiceDesc.Streams[0], "/testdata.EchoStream/EchoStream")
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGRPCQueryRouterRegisterService(t *testing.T) {
+	qr := baseapp.NewGRPCQueryRouter()
+
+	// First registration succeeds and routes every method.
+	require.NotPanics(t, func() {
+		qr.RegisterService(&echoStreamServiceDesc, struct{}{})
+	})
+	require.NotNil(t, qr.Route("/testdata.EchoStream/Echo"))
+
+	// Registering the same service again panics, naming the service.
+	require.PanicsWithError(t, "gRPC query service testdata.EchoStream has already been registered", func() {
+		qr.RegisterService(&echoStreamServiceDesc, struct{}{})
+	})
+
+	// A service without methods is rejected.
+	require.Panics(t, func() {
+		qr.RegisterService(&grpc.ServiceDesc{ServiceName: "testdata.Empty"}, struct{}{})
+	})
+}
+
+func TestGRPCQueryRouterConcurrency(t *testing.T) {
+	qr := baseapp.NewGRPCQueryRouter()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+
+		sd := echoStreamServiceDesc
+		sd.ServiceName = fmt.Sprintf("testdata.EchoStream%d", i)
+		go func() {
+			defer wg.Done()
+			qr.RegisterService(&sd, struct{}{})
+		}()
+		go func() {
+			defer wg.Done()
+			qr.Route(fmt.Sprintf("/%s/Echo", sd.ServiceName))
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 50; i++ {
+		require.NotNil(t, qr.Route(fmt.Sprintf("/testdata.EchoStream%d/Echo", i)))
+	}
+}
+
+var storeKey = sdk.NewKVStoreKey("echo")
+
+// echoStoreServiceDesc answers an EchoRequest with the value stored under the
+// requested key.
+var echoStoreServiceDesc = grpc.ServiceDesc{
+	ServiceName: "testdata.EchoStore",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Echo",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				var req testdata.EchoRequest
+				if err := dec(&req); err != nil {
+					return nil, err
+				}
+				store := sdk.UnwrapSDKContext(ctx).KVStore(storeKey)
+				return &testdata.EchoResponse{Message: string(store.Get([]byte(req.Message)))}, nil
+			},
+		},
+	},
+}
+
+func TestQueryServiceTestHelperWithHeight(t *testing.T) {
+	cms := rootmulti.NewStore(dbm.NewMemDB())
+	cms.MountStoreWithDB(storeKey, sdk.StoreTypeIAVL, nil)
+	require.NoError(t, cms.LoadLatestVersion())
+
+	ctx := sdk.NewContext(cms, tmproto.Header{}, false, log.NewNopLogger())
+	helper := baseapp.NewQueryServerTestHelper(ctx, testdata.NewTestInterfaceRegistry())
+	helper.RegisterService(&echoStoreServiceDesc, struct{}{})
+
+	store := cms.GetKVStore(storeKey)
+	store.Set([]byte("key"), []byte("height 1"))
+	cms.Commit()
+	store.Set([]byte("key"), []byte("height 2"))
+	cms.Commit()
+
+	echo := func(helper *baseapp.QueryServiceTestHelper) (string, error) {
+		var res testdata.EchoResponse
+		err := helper.Invoke(context.Background(), "/testdata.EchoStore/Echo", &testdata.EchoRequest{Message: "key"}, &res)
+		return res.Message, err
+	}
+
+	msg, err := echo(helper)
+	require.NoError(t, err)
+	require.Equal(t, "height 2", msg)
+
+	msg, err = echo(helper.WithHeight(1))
+	require.NoError(t, err)
+	require.Equal(t, "height 1", msg)
+
+	_, err = echo(helper.WithHeight(10))
+	require.Error(t, err)
+
+	// a context without a versioned multistore cannot serve past heights
+	helper.Ctx = ctx.WithMultiStore(cms.CacheMultiStore())
+	_, err = echo(helper.WithHeight(1))
+	require.Error(t, err)
+}
+
+func TestQueryServiceTestHelperInvokeWithHeight(t *testing.T) {
+	cms := rootmulti.NewStore(dbm.NewMemDB())
+	cms.MountStoreWithDB(storeKey, sdk.StoreTypeIAVL, nil)
+	require.NoError(t, cms.LoadLatestVersion())
+	cms.Commit()
+	cms.Commit()
+
+	ctx := sdk.NewContext(cms, tmproto.Header{Height: 3}, false, log.NewNopLogger())
+	helper := baseapp.NewQueryServerTestHelper(ctx, testdata.NewTestInterfaceRegistry())
+	helper.RegisterService(&echoStoreServiceDesc, struct{}{})
+
+	var res testdata.EchoResponse
+	height, err := helper.InvokeWithHeight(context.Background(), "/testdata.EchoStore/Echo", &testdata.EchoRequest{Message: "key"}, &res)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), height)
+
+	height, err = helper.WithHeight(1).InvokeWithHeight(context.Background(), "/testdata.EchoStore/Echo", &testdata.EchoRequest{Message: "key"}, &res)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), height)
+
+	height, err = helper.InvokeWithHeight(context.Background(), "/testdata.Unknown/Echo", &testdata.EchoRequest{}, &res)
+	require.Error(t, err)
+	require.Zero(t, height)
+}
+
+func TestQueryServiceTestHelperErrors(t *testing.T) {
+	helper := baseapp.NewQueryServerTestHelper(sdk.Context{}.WithContext(context.Background()), testdata.NewTestInterfaceRegistry())
+	helper.RegisterService(&echoStoreServiceDesc, struct{}{})
+
+	var res testdata.EchoResponse
+	for _, method := range []string{"", "/", "testdata.EchoStore/Echo", "/testdata.EchoStore", "/testdata.EchoStore/", "//Echo", "/testdata.EchoStore/Echo/Extra"} {
+		err := helper.Invoke(context.Background(), method, &testdata.EchoRequest{}, &res)
+		require.True(t, errors.Is(err, baseapp.ErrInvalidMethodName), method)
+		require.Contains(t, err.Error(), fmt.Sprintf("%q", method))
+		require.Equal(t, codes.Unimplemented, status.Code(err))
+
+		_, err = helper.NewStream(context.Background(), &grpc.StreamDesc{ServerStreams: true}, method)
+		require.True(t, errors.Is(err, baseapp.ErrInvalidMethodName), method)
+	}
+
+	err := helper.Invoke(context.Background(), "/testdata.EchoStore/Unknown", &testdata.EchoRequest{}, &res)
+	require.True(t, errors.Is(err, baseapp.ErrHandlerNotFound))
+	require.False(t, errors.Is(err, baseapp.ErrInvalidMethodName))
+	require.Equal(t, "handler not found for /testdata.EchoStore/Unknown", err.Error())
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = helper.NewStream(context.Background(), &grpc.StreamDesc{ServerStreams: true}, "/testdata.EchoStore/Unknown")
+	require.True(t, errors.Is(err, baseapp.ErrHandlerNotFound))
+}
+
+func TestQueryServiceTestHelperConnect(t *testing.T) {
+	helper := baseapp.NewQueryServerTestHelper(sdk.Context{}.WithContext(context.Background()), testdata.NewTestInterfaceRegistry())
+	client := testdata.NewQueryClient(helper.Connect(func(s gogogrpc.Server) {
+		testdata.RegisterQueryServer(s, testdata.QueryImpl{})
+	}))
+
+	res, err := client.Echo(context.Background(), &testdata.EchoRequest{Message: "hello"})
+	require.NoError(t, err)
+	require.Equal(t, "hello", res.Message)
+}
+
+// recordingCodec is a proto codec which records how often it is used.
+type recordingCodec struct {
+	encoding.Codec
+	marshals, unmarshals int
+}
+
+func (c *recordingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.marshals++
+	return c.Codec.Marshal(v)
+}
+
+func (c *recordingCodec) Unmarshal(data []byte, v interface{}) error {
+	c.unmarshals++
+	return c.Codec.Unmarshal(data, v)
+}
+
+func TestGRPCQueryRouterWithCodec(t *testing.T) {
+	cdc := &recordingCodec{Codec: encoding.GetCodec(proto.Name)}
+	qr := baseapp.NewGRPCQueryRouter(baseapp.WithCodec(cdc))
+	testdata.RegisterQueryServer(qr, testdata.QueryImpl{})
+
+	helper := &baseapp.QueryServiceTestHelper{
+		GRPCQueryRouter: qr,
+		Ctx:             sdk.Context{}.WithContext(context.Background()),
+	}
+	client := testdata.NewQueryClient(helper)
+
+	res, err := client.Echo(context.Background(), &testdata.EchoRequest{Message: "hello"})
+	require.NoError(t, err)
+	require.Equal(t, "hello", res.Message)
+
+	// the helper encodes the request and decodes the response, the router
+	// decodes the request and encodes the response
+	require.Equal(t, 2, cdc.marshals)
+	require.Equal(t, 2, cdc.unmarshals)
+}
+
+// echoErrorServiceDesc fails every request with the error named by the request
+// message.
+var echoErrorServiceDesc = grpc.ServiceDesc{
+	ServiceName: "testdata.EchoError",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Echo",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				var req testdata.EchoRequest
+				if err := dec(&req); err != nil {
+					return nil, err
+				}
+				switch req.Message {
+				case "unknown":
+					return nil, sdkerrors.Wrap(sdkerrors.ErrUnknownRequest, "unknown")
+				case "invalid":
+					return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, "invalid")
+				case "unauthorized":
+					return nil, sdkerrors.Wrap(sdkerrors.ErrUnauthorized, "unauthorized")
+				case "status":
+					return nil, status.Error(codes.FailedPrecondition, "status")
+				default:
+					return nil, errors.New("plain")
+				}
+			},
+		},
+	},
+}
+
+func TestGRPCQueryRouterStatusCodes(t *testing.T) {
+	helper := baseapp.NewQueryServerTestHelper(sdk.Context{}.WithContext(context.Background()), testdata.NewTestInterfaceRegistry())
+	helper.RegisterService(&echoErrorServiceDesc, struct{}{})
+
+	testCases := []struct {
+		msg     string
+		expCode codes.Code
+		expMsg  string
+	}{
+		{"unknown", codes.NotFound, "unknown: unknown request"},
+		{"invalid", codes.InvalidArgument, "invalid: invalid address"},
+		{"unauthorized", codes.PermissionDenied, "unauthorized: unauthorized"},
+		{"status", codes.FailedPrecondition, "status"},
+		{"plain", codes.Unknown, "plain"},
+	}
+
+	for _, tc := range testCases {
+		var res testdata.EchoResponse
+		err := helper.Invoke(context.Background(), "/testdata.EchoError/Echo", &testdata.EchoRequest{Message: tc.msg}, &res)
+		require.Equal(t, tc.expCode, status.Code(err), tc.msg)
+		require.Equal(t, tc.expMsg, status.Convert(err).Message(), tc.msg)
+	}
+
+	// unknown paths
+	var res testdata.EchoResponse
+	err := helper.Invoke(context.Background(), "/testdata.EchoError/Unknown", &testdata.EchoRequest{}, &res)
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	// undecodable requests
+	_, err = helper.Route("/testdata.EchoError/Echo")(sdk.Context{}.WithContext(context.Background()), abci.RequestQuery{Data: []byte("garbage")})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// countingServiceDesc echoes requests, counting how many it has served.
+func countingServiceDesc(calls *int) *grpc.ServiceDesc {
+	return &grpc.ServiceDesc{
+		ServiceName: "testdata.Counting",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "Echo",
+				Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+					var req testdata.EchoRequest
+					if err := dec(&req); err != nil {
+						return nil, err
+					}
+					*calls++
+					return &testdata.EchoResponse{Message: req.Message}, nil
+				},
+			},
+		},
+	}
+}
+
+// multiMethodServiceDesc has methods answering with their own name.
+func multiMethodServiceDesc(names ...string) *grpc.ServiceDesc {
+	sd := &grpc.ServiceDesc{ServiceName: "testdata.MultiMethod", HandlerType: (*interface{})(nil)}
+	for _, name := range names {
+		name := name
+		sd.Methods = append(sd.Methods, grpc.MethodDesc{
+			MethodName: name,
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				var req testdata.EchoRequest
+				if err := dec(&req); err != nil {
+					return nil, err
+				}
+				return &testdata.EchoResponse{Message: name + " " + req.Message}, nil
+			},
+		})
+	}
+	return sd
+}
+
+func TestGRPCQueryRouterMethodRouting(t *testing.T) {
+	ctx := sdk.Context{}.WithContext(context.Background())
+	reqBz, err := (&testdata.EchoRequest{Message: "hello"}).Marshal()
+	require.NoError(t, err)
+
+	qr := baseapp.NewGRPCQueryRouter()
+	qr.RegisterService(multiMethodServiceDesc("Gamma", "Alpha", "Beta"), struct{}{})
+	qr.RegisterService(&echoStreamServiceDesc, struct{}{})
+
+	// each method is routed on its own, streaming methods excluded
+	require.Equal(t, []string{
+		"/testdata.EchoStream/Echo",
+		"/testdata.MultiMethod/Alpha",
+		"/testdata.MultiMethod/Beta",
+		"/testdata.MultiMethod/Gamma",
+	}, qr.Routes())
+
+	for _, name := range []string{"Alpha", "Beta", "Gamma"} {
+		handler := qr.Route("/testdata.MultiMethod/" + name)
+		require.NotNil(t, handler, name)
+
+		res, err := handler(ctx, abci.RequestQuery{Data: reqBz})
+		require.NoError(t, err)
+
+		var echo testdata.EchoResponse
+		require.NoError(t, echo.Unmarshal(res.Value))
+		require.Equal(t, name+" hello", echo.Message)
+	}
+	require.Nil(t, qr.Route("/testdata.MultiMethod/Delta"))
+	require.Nil(t, qr.Route("/testdata.EchoStream/EchoStream"))
+
+	require.True(t, qr.RemoveRoute("/testdata.MultiMethod/Beta"))
+	require.Equal(t, []string{
+		"/testdata.EchoStream/Echo",
+		"/testdata.MultiMethod/Alpha",
+		"/testdata.MultiMethod/Gamma",
+	}, qr.Routes())
+
+	require.True(t, qr.RemoveRoute("testdata.MultiMethod"))
+	require.True(t, qr.RemoveRoute("testdata.EchoStream"))
+	require.Empty(t, qr.Routes())
+}
+
+func TestGRPCQueryRouterCache(t *testing.T) {
+	reqBz, err := (&testdata.EchoRequest{Message: "hello"}).Marshal()
+	require.NoError(t, err)
+	ctx := sdk.Context{}.WithContext(context.Background()).WithBlockHeight(1)
+
+	var calls int
+	qr := baseapp.NewGRPCQueryRouter(baseapp.WithCache(10))
+	qr.RegisterService(countingServiceDesc(&calls), struct{}{})
+	handler := qr.Route("/testdata.Counting/Echo")
+
+	res, err := handler(ctx, abci.RequestQuery{Data: reqBz, Height: 1})
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+
+	// same path, height and request: served from the cache
+	cached, err := handler(ctx, abci.RequestQuery{Data: reqBz, Height: 1})
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+	require.Equal(t, res, cached)
+
+	// different request bytes miss the cache
+	otherBz, err := (&testdata.EchoRequest{Message: "other"}).Marshal()
+	require.NoError(t, err)
+	_, err = handler(ctx, abci.RequestQuery{Data: otherBz, Height: 1})
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+
+	// a new height evicts the cached responses
+	_, err = handler(ctx.WithBlockHeight(2), abci.RequestQuery{Data: reqBz, Height: 2})
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+	_, err = handler(ctx, abci.RequestQuery{Data: reqBz, Height: 1})
+	require.NoError(t, err)
+	require.Equal(t, 4, calls)
+
+	// latest-height queries are keyed on the height they resolved to
+	_, err = handler(ctx.WithBlockHeight(3), abci.RequestQuery{Data: reqBz})
+	require.NoError(t, err)
+	require.Equal(t, 5, calls)
+	_, err = handler(ctx.WithBlockHeight(4), abci.RequestQuery{Data: reqBz})
+	require.NoError(t, err)
+	require.Equal(t, 6, calls)
+
+	// without the option every query reaches the handler
+	calls = 0
+	qr = baseapp.NewGRPCQueryRouter()
+	qr.RegisterService(countingServiceDesc(&calls), struct{}{})
+	handler = qr.Route("/testdata.Counting/Echo")
+	for i := 0; i < 2; i++ {
+		_, err = handler(ctx, abci.RequestQuery{Data: reqBz, Height: 1})
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, calls)
+}
+
+func TestGRPCQueryRouterMaxRequestBytes(t *testing.T) {
+	ctx := sdk.Context{}.WithContext(context.Background())
+	smallBz, err := (&testdata.EchoRequest{Message: "hello"}).Marshal()
+	require.NoError(t, err)
+	largeBz, err := (&testdata.EchoRequest{Message: strings.Repeat("a", 100)}).Marshal()
+	require.NoError(t, err)
+
+	var calls int
+	qr := baseapp.NewGRPCQueryRouter(baseapp.WithMaxRequestBytes(50))
+	qr.RegisterService(countingServiceDesc(&calls), struct{}{})
+	handler := qr.Route("/testdata.Counting/Echo")
+
+	_, err = handler(ctx, abci.RequestQuery{Data: smallBz})
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+
+	// the oversized request never reaches the handler
+	_, err = handler(ctx, abci.RequestQuery{Data: largeBz})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Equal(t, 1, calls)
+
+	// the default limit is finite, but can be disabled
+	hugeBz, err := (&testdata.EchoRequest{Message: strings.Repeat("a", baseapp.DefaultMaxQueryRequestBytes)}).Marshal()
+	require.NoError(t, err)
+	oversized := abci.RequestQuery{Data: hugeBz}
+	qr = baseapp.NewGRPCQueryRouter()
+	qr.RegisterService(countingServiceDesc(&calls), struct{}{})
+	_, err = qr.Route("/testdata.Counting/Echo")(ctx, oversized)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Equal(t, 1, calls)
+
+	qr = baseapp.NewGRPCQueryRouter(baseapp.WithMaxRequestBytes(0))
+	qr.RegisterService(countingServiceDesc(&calls), struct{}{})
+	_, err = qr.Route("/testdata.Counting/Echo")(ctx, oversized)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+}
+
+func TestGRPCQueryRouterTelemetry(t *testing.T) {
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	cfg := metrics.DefaultConfig("")
+	cfg.EnableHostname = false
+	cfg.EnableRuntimeMetrics = false
+	_, err := metrics.NewGlobal(cfg, sink)
+	require.NoError(t, err)
+	defer metrics.NewGlobal(cfg, &metrics.BlackholeSink{}) //nolint:errcheck
+
+	var calls int
+	qr := baseapp.NewGRPCQueryRouter()
+	qr.RegisterService(countingServiceDesc(&calls), struct{}{})
+	handler := qr.Route("/testdata.Counting/Echo")
+
+	reqBz, err := (&testdata.EchoRequest{Message: "hello"}).Marshal()
+	require.NoError(t, err)
+	_, err = handler(sdk.Context{}.WithContext(context.Background()), abci.RequestQuery{Data: reqBz})
+	require.NoError(t, err)
+
+	_, err = handler(sdk.Context{}.WithContext(context.Background()), abci.RequestQuery{Data: []byte("garbage")})
+	require.Error(t, err)
+
+	var samples, errCount int
+	for _, interval := range sink.Data() {
+		if sample, ok := interval.Samples["query.grpc;service=testdata.Counting;method=Echo"]; ok {
+			samples += sample.Count
+		}
+		if counter, ok := interval.Counters["query.grpc.errors;service=testdata.Counting;method=Echo"]; ok {
+			errCount += counter.Count
+		}
+	}
+	require.Equal(t, 2, samples)
+	require.Equal(t, 1, errCount)
+}
+
+// echoWaitServiceDesc blocks every request until its context is done.
+var echoWaitServiceDesc = grpc.ServiceDesc{
+	ServiceName: "testdata.EchoWait",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Echo",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				var req testdata.EchoRequest
+				if err := dec(&req); err != nil {
+					return nil, err
+				}
+				sdkCtx := sdk.UnwrapSDKContext(ctx)
+				if _, ok := sdkCtx.Context().Deadline(); !ok {
+					return nil, errors.New("no deadline")
+				}
+				<-sdkCtx.Context().Done()
+				return nil, sdkCtx.Context().Err()
+			},
+		},
+	},
+}
+
+func TestQueryServiceTestHelperContext(t *testing.T) {
+	helper := baseapp.NewQueryServerTestHelper(sdk.Context{}.WithContext(context.Background()), testdata.NewTestInterfaceRegistry())
+	helper.RegisterService(&echoWaitServiceDesc, struct{}{})
+
+	var res testdata.EchoResponse
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := helper.Invoke(ctx, "/testdata.EchoWait/Echo", &testdata.EchoRequest{}, &res)
+	require.Equal(t, codes.Canceled, status.Code(err))
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = helper.Invoke(ctx, "/testdata.EchoWait/Echo", &testdata.EchoRequest{}, &res)
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestQueryServiceTestHelperBlockingQuerier(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	desc := grpc.ServiceDesc{
+		ServiceName: "testdata.EchoBlocking",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "Echo",
+				Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+					// ignores the cancellation of its context
+					<-release
+					return &testdata.EchoResponse{}, nil
+				},
+			},
+		},
+	}
+	helper := baseapp.NewQueryServerTestHelper(sdk.Context{}.WithContext(context.Background()), testdata.NewTestInterfaceRegistry())
+	helper.RegisterService(&desc, struct{}{})
+
+	var res testdata.EchoResponse
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	returned := make(chan error, 1)
+	go func() { returned <- helper.Invoke(ctx, "/testdata.EchoBlocking/Echo", &testdata.EchoRequest{}, &res) }()
+
+	select {
+	case err := <-returned:
+		require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	case <-time.After(time.Second):
+		t.Fatal("Invoke is still waiting on the querier after the deadline")
+	}
+}
+
+func TestGRPCQueryRouterRemoveRoute(t *testing.T) {
+	qr := baseapp.NewGRPCQueryRouter()
+	qr.RegisterService(&echoStreamServiceDesc, struct{}{})
+	qr.RegisterService(&echoStoreServiceDesc, struct{}{})
+
+	// removing a single method
+	require.True(t, qr.RemoveRoute("/testdata.EchoStore/Echo"))
+	require.Nil(t, qr.Route("/testdata.EchoStore/Echo"))
+	require.False(t, qr.RemoveRoute("/testdata.EchoStore/Echo"))
+
+	// removing a whole service
+	require.True(t, qr.RemoveRoute("testdata.EchoStream"))
+	require.Nil(t, qr.Route("/testdata.EchoStream/Echo"))
+	require.False(t, qr.RemoveRoute("testdata.EchoStream"))
+
+	require.False(t, qr.RemoveRoute("/testdata.Unknown/Echo"))
+
+	// removed services can be registered again
+	require.NotPanics(t, func() {
+		qr.RegisterService(&echoStreamServiceDesc, struct{}{})
+		qr.RegisterService(&echoStoreServiceDesc, struct{}{})
+	})
+	require.NotNil(t, qr.Route("/testdata.EchoStream/Echo"))
+	require.NotNil(t, qr.Route("/testdata.EchoStore/Echo"))
+}
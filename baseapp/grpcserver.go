@@ -22,9 +22,9 @@ func (app *BaseApp) GRPCQueryRouter() *GRPCQueryRouter { return app.grpcQueryRou
 
 // RegisterGRPCServer registers gRPC services directly with the gRPC server.
 func (app *BaseApp) RegisterGRPCServer(server gogogrpc.Server) {
-	// Define an interceptor for all gRPC queries: this interceptor will create
-	// a new sdk.Context, and pass it into the query handler.
-	interceptor := func(grpcCtx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	// queryContext creates a new sdk.Context for a gRPC query and attaches it
+	// to the query's context.
+	queryContext := func(grpcCtx context.Context) (context.Context, error) {
 		// If there's some metadata in the context, retrieve it.
 		md, ok := metadata.FromIncomingContext(grpcCtx)
 		if !ok {
@@ -34,6 +34,7 @@ func (app *BaseApp) RegisterGRPCServer(server gogogrpc.Server) {
 		// Get height header from the request context, if present.
 		var height int64
 		if heightHeaders := md.Get(grpctypes.GRPCBlockHeightHeader); len(heightHeaders) > 0 {
+			var err error
 			height, err = strconv.ParseInt(heightHeaders[0], 10, 64)
 			if err != nil {
 				return nil, sdkerrors.Wrapf(
@@ -46,10 +47,12 @@ func (app *BaseApp) RegisterGRPCServer(server gogogrpc.Server) {
 		}
 
 		// Create the sdk.Context. Passing false as 2nd arg, as we can't
-		// actually support proofs with gRPC right now.
+		// actually support proofs with gRPC right now. The height being valid,
+		// this only fails when the state at that height is not available,
+		// e.g. because it was not committed yet.
 		sdkCtx, err := app.createQueryContext(height, false)
 		if err != nil {
-			return nil, err
+			return nil, status.Error(codes.NotFound, err.Error())
 		}
 
 		// Attach the sdk.Context into the gRPC's context.Context.
@@ -62,9 +65,31 @@ func (app *BaseApp) RegisterGRPCServer(server gogogrpc.Server) {
 		md = metadata.Pairs(grpctypes.GRPCBlockHeightHeader, strconv.FormatInt(height, 10))
 		grpc.SetHeader(grpcCtx, md)
 
+		return grpcCtx, nil
+	}
+
+	// Define an interceptor for all gRPC queries: this interceptor will create
+	// a new sdk.Context, and pass it into the query handler.
+	interceptor := func(grpcCtx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		grpcCtx, err = queryContext(grpcCtx)
+		if err != nil {
+			return nil, err
+		}
+
 		return handler(grpcCtx, req)
 	}
 
+	// Streaming queries get their sdk.Context the same way, through the
+	// context of the stream.
+	streamInterceptor := func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		grpcCtx, err := queryContext(stream.Context())
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &queryServerStream{ServerStream: stream, ctx: grpcCtx})
+	}
+
 	// Loop through all services and methods, add the interceptor, and register
 	// the service.
 	for _, data := range app.GRPCQueryRouter().serviceData {
@@ -84,14 +109,35 @@ func (app *BaseApp) RegisterGRPCServer(server gogogrpc.Server) {
 			}
 		}
 
+		newStreams := make([]grpc.StreamDesc, len(desc.Streams))
+		for i, stream := range desc.Streams {
+			streamHandler := stream.Handler
+			newStreams[i] = stream
+			newStreams[i].Handler = func(srv interface{}, stream grpc.ServerStream) error {
+				return grpcmiddleware.ChainStreamServer(
+					grpcrecovery.StreamServerInterceptor(),
+					streamInterceptor,
+				)(srv, stream, nil, streamHandler)
+			}
+		}
+
 		newDesc := &grpc.ServiceDesc{
 			ServiceName: desc.ServiceName,
 			HandlerType: desc.HandlerType,
 			Methods:     newMethods,
-			Streams:     desc.Streams,
+			Streams:     newStreams,
 			Metadata:    desc.Metadata,
 		}
 
 		server.RegisterService(newDesc, data.handler)
 	}
 }
+
+// queryServerStream is a grpc.ServerStream whose context carries the
+// sdk.Context of the query.
+type queryServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *queryServerStream) Context() context.Context { return s.ctx }
@@ -2,21 +2,46 @@ package baseapp
 
 import (
 	"fmt"
+	"sync"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"google.golang.org/grpc/encoding"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 )
 
 type QueryRouter struct {
+	mtx    sync.RWMutex
+	cdc    encoding.Codec
 	routes map[string]sdk.Querier
 }
 
 var _ sdk.QueryRouter = NewQueryRouter()
 
+// QueryRouterOption is an option for NewQueryRouter.
+type QueryRouterOption func(*QueryRouter)
+
+// WithQuerierCodec sets the codec used by Invoke to encode query requests and
+// decode query responses. It defaults to the gRPC proto codec.
+func WithQuerierCodec(cdc encoding.Codec) QueryRouterOption {
+	return func(qrt *QueryRouter) {
+		qrt.cdc = cdc
+	}
+}
+
 // NewQueryRouter returns a reference to a new QueryRouter.
-func NewQueryRouter() *QueryRouter {
-	return &QueryRouter{
+func NewQueryRouter(opts ...QueryRouterOption) *QueryRouter {
+	qrt := &QueryRouter{
+		cdc:    protoCodec,
 		routes: map[string]sdk.Querier{},
 	}
+
+	for _, opt := range opts {
+		opt(qrt)
+	}
+
+	return qrt
 }
 
 // AddRoute adds a query path to the router with a given Querier. It will panic
@@ -26,6 +51,9 @@ func (qrt *QueryRouter) AddRoute(path string, q sdk.Querier) sdk.QueryRouter {
 		panic("route expressions can only contain alphanumeric characters")
 	}
 
+	qrt.mtx.Lock()
+	defer qrt.mtx.Unlock()
+
 	if qrt.routes[path] != nil {
 		panic(fmt.Sprintf("route %s has already been initialized", path))
 	}
@@ -35,7 +63,68 @@ func (qrt *QueryRouter) AddRoute(path string, q sdk.Querier) sdk.QueryRouter {
 	return qrt
 }
 
+// AddRouteOverride adds a query path to the router with a given Querier,
+// replacing the Querier already registered for that path if any. It is meant
+// for test setups which register their routes more than once; applications
+// should use AddRoute. The route must be alphanumeric.
+func (qrt *QueryRouter) AddRouteOverride(path string, q sdk.Querier) sdk.QueryRouter {
+	if !sdk.IsAlphaNumeric(path) {
+		panic("route expressions can only contain alphanumeric characters")
+	}
+
+	qrt.mtx.Lock()
+	defer qrt.mtx.Unlock()
+
+	qrt.routes[path] = q
+
+	return qrt
+}
+
 // Route returns the Querier for a given query route path.
 func (qrt *QueryRouter) Route(path string) sdk.Querier {
+	qrt.mtx.RLock()
+	defer qrt.mtx.RUnlock()
+
 	return qrt.routes[path]
 }
+
+// RemoveRoute removes the Querier registered for the given query route path,
+// returning whether one was registered.
+func (qrt *QueryRouter) RemoveRoute(path string) bool {
+	qrt.mtx.Lock()
+	defer qrt.mtx.Unlock()
+
+	if qrt.routes[path] == nil {
+		return false
+	}
+
+	delete(qrt.routes, path)
+	return true
+}
+
+// Invoke calls the Querier registered for route with args, encoded with the
+// router's codec, as the request data, and decodes the Querier's response into
+// reply with the same codec. This lets legacy Queriers returning
+// proto-marshaled bytes be called the same way as gRPC query services. Route
+// still gives access to the raw bytes.
+func (qrt *QueryRouter) Invoke(ctx sdk.Context, route string, path []string, args, reply interface{}) error {
+	querier := qrt.Route(route)
+	if querier == nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "no custom querier found for route %s", route)
+	}
+
+	var data []byte
+	if args != nil {
+		var err error
+		if data, err = qrt.cdc.Marshal(args); err != nil {
+			return err
+		}
+	}
+
+	res, err := querier(ctx, path, abci.RequestQuery{Data: data})
+	if err != nil {
+		return err
+	}
+
+	return qrt.cdc.Unmarshal(res, reply)
+}
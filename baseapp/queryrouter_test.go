@@ -1,13 +1,18 @@
 package baseapp
 
 import (
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	abci "github.com/tendermint/tendermint/abci/types"
 
+	"github.com/cosmos/cosmos-sdk/testutil/testdata"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 )
 
 var testQuerier = func(_ sdk.Context, _ []string, _ abci.RequestQuery) ([]byte, error) {
@@ -31,3 +36,102 @@ func TestQueryRouter(t *testing.T) {
 		qr.AddRoute("testRoute", testQuerier)
 	})
 }
+
+func TestQueryRouterConcurrency(t *testing.T) {
+	qr := NewQueryRouter()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+
+		path := fmt.Sprintf("route%d", i)
+		go func() {
+			defer wg.Done()
+			qr.AddRoute(path, testQuerier)
+		}()
+		go func() {
+			defer wg.Done()
+			qr.Route(path)
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 50; i++ {
+		require.NotNil(t, qr.Route(fmt.Sprintf("route%d", i)))
+	}
+}
+
+func TestQueryRouterRemoveRoute(t *testing.T) {
+	qr := NewQueryRouter()
+	qr.AddRoute("testRoute", testQuerier)
+
+	require.True(t, qr.RemoveRoute("testRoute"))
+	require.Nil(t, qr.Route("testRoute"))
+	require.False(t, qr.RemoveRoute("testRoute"))
+
+	// the route can be registered again once removed
+	require.NotPanics(t, func() {
+		qr.AddRoute("testRoute", testQuerier)
+	})
+}
+
+func TestQueryRouterAddRouteOverride(t *testing.T) {
+	qr := NewQueryRouter()
+
+	// require panic on invalid route
+	require.Panics(t, func() {
+		qr.AddRouteOverride("*", testQuerier)
+	})
+
+	// a new route is added as by AddRoute
+	qr.AddRouteOverride("testRoute", testQuerier)
+	bz, err := qr.Route("testRoute")(sdk.Context{}, nil, abci.RequestQuery{})
+	require.NoError(t, err)
+	require.Nil(t, bz)
+
+	// an existing route is replaced
+	require.NotPanics(t, func() {
+		qr.AddRouteOverride("testRoute", func(_ sdk.Context, _ []string, _ abci.RequestQuery) ([]byte, error) {
+			return []byte("override"), nil
+		})
+	})
+	bz, err = qr.Route("testRoute")(sdk.Context{}, nil, abci.RequestQuery{})
+	require.NoError(t, err)
+	require.Equal(t, []byte("override"), bz)
+
+	// AddRoute still panics on duplicates
+	require.Panics(t, func() {
+		qr.AddRoute("testRoute", testQuerier)
+	})
+}
+
+func TestQueryRouterInvoke(t *testing.T) {
+	qr := NewQueryRouter()
+
+	// a legacy querier answering with proto-marshaled bytes
+	qr.AddRoute("echo", func(_ sdk.Context, path []string, req abci.RequestQuery) ([]byte, error) {
+		var echo testdata.EchoRequest
+		if err := echo.Unmarshal(req.Data); err != nil {
+			return nil, err
+		}
+
+		res := testdata.EchoResponse{Message: fmt.Sprintf("%s %v", echo.Message, path)}
+		return res.Marshal()
+	})
+
+	var res testdata.EchoResponse
+	err := qr.Invoke(sdk.Context{}, "echo", []string{"a", "b"}, &testdata.EchoRequest{Message: "hello"}, &res)
+	require.NoError(t, err)
+	require.Equal(t, "hello [a b]", res.Message)
+
+	// the raw bytes are still available through the route
+	req, err := (&testdata.EchoRequest{Message: "raw"}).Marshal()
+	require.NoError(t, err)
+	bz, err := qr.Route("echo")(sdk.Context{}, nil, abci.RequestQuery{Data: req})
+	require.NoError(t, err)
+	require.NoError(t, res.Unmarshal(bz))
+	require.Equal(t, "raw []", res.Message)
+
+	err = qr.Invoke(sdk.Context{}, "unknown", nil, &testdata.EchoRequest{}, &res)
+	require.True(t, errors.Is(err, sdkerrors.ErrUnknownRequest))
+}
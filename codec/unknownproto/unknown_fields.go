@@ -20,6 +20,13 @@ import (
 
 const bit11NonCritical = 1 << 10
 
+// MaxGroupDepth is the maximum nesting depth of (deprecated) proto2 groups
+// that will be traversed when skipping over field values.
+const MaxGroupDepth = 100
+
+// ErrMaxGroupDepth is returned when group fields are nested deeper than MaxGroupDepth.
+var ErrMaxGroupDepth = errors.New("max group nesting depth exceeded")
+
 type descriptorIface interface {
 	Descriptor() ([]byte, []int)
 }
@@ -91,10 +98,10 @@ func RejectUnknownFields(bz []byte, msg proto.Message, allowUnknownNonCriticals
 
 		// Skip over the bytes that store fieldNumber and wireType bytes.
 		bz = bz[m:]
-		n := protowire.ConsumeFieldValue(tagNum, wireType, bz)
-		if n < 0 {
+		n, cerr := consumeFieldValue(tagNum, wireType, bz, 0)
+		if cerr != nil {
 			err = fmt.Errorf("could not consume field value for tagNum: %d, wireType: %q; %w",
-				tagNum, wireTypeToString(wireType), protowire.ParseError(n))
+				tagNum, wireTypeToString(wireType), cerr)
 			return hasUnknownNonCriticals, err
 		}
 		fieldBytes := bz[:n]
@@ -162,6 +169,43 @@ func RejectUnknownFields(bz []byte, msg proto.Message, allowUnknownNonCriticals
 	return hasUnknownNonCriticals, nil
 }
 
+// consumeFieldValue behaves like protowire.ConsumeFieldValue, except that it
+// walks groups itself so that their nesting depth can be bounded by MaxGroupDepth.
+func consumeFieldValue(num protowire.Number, typ protowire.Type, bz []byte, depth int) (int, error) {
+	if typ != protowire.StartGroupType {
+		n := protowire.ConsumeFieldValue(num, typ, bz)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		return n, nil
+	}
+
+	if depth >= MaxGroupDepth {
+		return 0, ErrMaxGroupDepth
+	}
+
+	n0 := len(bz)
+	for {
+		num2, typ2, n := protowire.ConsumeTag(bz)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		bz = bz[n:]
+		if typ2 == protowire.EndGroupType {
+			if num != num2 {
+				return 0, fmt.Errorf("mismatching end group marker: got %d, want %d", num2, num)
+			}
+			return n0 - len(bz), nil
+		}
+
+		n, err := consumeFieldValue(num2, typ2, bz, depth+1)
+		if err != nil {
+			return 0, err
+		}
+		bz = bz[n:]
+	}
+}
+
 var protoMessageForTypeNameMu sync.RWMutex
 var protoMessageForTypeNameCache = make(map[string]proto.Message)
 
@@ -1,12 +1,14 @@
 package unknownproto
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/gogo/protobuf/proto"
+	"google.golang.org/protobuf/encoding/protowire"
 
 	"github.com/cosmos/cosmos-sdk/codec/types"
 	"github.com/cosmos/cosmos-sdk/testutil/testdata"
@@ -664,6 +666,34 @@ func TestPackedEncoding(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestRejectUnknownFieldsNestedGroups(t *testing.T) {
+	const nonCriticalTag = protowire.Number(bit11NonCritical | 7)
+
+	nestedGroups := func(depth int, closed bool) []byte {
+		var bz []byte
+		for i := 0; i < depth; i++ {
+			bz = protowire.AppendTag(bz, nonCriticalTag, protowire.StartGroupType)
+		}
+		for i := 0; closed && i < depth; i++ {
+			bz = protowire.AppendTag(bz, nonCriticalTag, protowire.EndGroupType)
+		}
+		return bz
+	}
+
+	// groups within the depth limit are skipped over
+	hasUnknownNonCriticals, err := RejectUnknownFields(nestedGroups(MaxGroupDepth, true), new(testdata.TestVersion1), true, DefaultAnyResolver{})
+	require.NoError(t, err)
+	require.True(t, hasUnknownNonCriticals)
+
+	// one more level of nesting is rejected
+	_, err = RejectUnknownFields(nestedGroups(MaxGroupDepth+1, true), new(testdata.TestVersion1), true, DefaultAnyResolver{})
+	require.True(t, errors.Is(err, ErrMaxGroupDepth))
+
+	// a pathological run of group starts fails fast instead of recursing through the whole buffer
+	_, err = RejectUnknownFields(nestedGroups(1<<20, false), new(testdata.TestVersion1), true, DefaultAnyResolver{})
+	require.True(t, errors.Is(err, ErrMaxGroupDepth))
+}
+
 func mustMarshal(msg proto.Message) []byte {
 	blob, err := proto.Marshal(msg)
 	if err != nil {
@@ -5,13 +5,17 @@ package grpc_test
 import (
 	"context"
 	"fmt"
+	"io"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
 
 	clienttx "github.com/cosmos/cosmos-sdk/client/tx"
 	"github.com/cosmos/cosmos-sdk/testutil/network"
@@ -23,6 +27,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/types/tx/signing"
 	authclient "github.com/cosmos/cosmos-sdk/x/auth/client"
 	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	feegranttypes "github.com/cosmos/cosmos-sdk/x/feegrant/types"
 )
 
 type IntegrationTestSuite struct {
@@ -95,6 +100,21 @@ func (s *IntegrationTestSuite) TestGRPCServer_BankBalance() {
 	s.Require().Equal([]string{"1"}, blockHeight)
 }
 
+func (s *IntegrationTestSuite) TestGRPCServer_Stream() {
+	// streaming queries are run with an sdk.Context too
+	queryClient := feegranttypes.NewQueryClient(s.conn)
+	stream, err := queryClient.AllowancesStream(context.Background(), &feegranttypes.QueryAllowancesStreamRequest{})
+	s.Require().NoError(err)
+
+	_, err = stream.Recv()
+	s.Require().Equal(io.EOF, err)
+
+	header, err := stream.Header()
+	s.Require().NoError(err)
+	blockHeight := header.Get(grpctypes.GRPCBlockHeightHeader)
+	s.Require().NotEmpty(blockHeight)
+}
+
 func (s *IntegrationTestSuite) TestGRPCServer_Reflection() {
 	// Test server reflection
 	reflectClient := rpb.NewServerReflectionClient(s.conn)
@@ -173,6 +193,33 @@ func (s *IntegrationTestSuite) TestGRPCServer_BroadcastTx() {
 	s.Require().Equal(uint32(0), grpcRes.TxResponse.Code)
 }
 
+func (s *IntegrationTestSuite) TestGRPCServer_UnavailableHeight() {
+	val0 := s.network.Validators[0]
+	denom := fmt.Sprintf("%stoken", val0.Moniker)
+	bankClient := banktypes.NewQueryClient(s.conn)
+	req := &banktypes.QueryBalanceRequest{Address: val0.Address.String(), Denom: denom}
+
+	atHeight := func(height int64) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), grpctypes.GRPCBlockHeightHeader, strconv.FormatInt(height, 10))
+	}
+
+	var header metadata.MD
+	_, err := bankClient.Balance(context.Background(), req, grpc.Header(&header))
+	s.Require().NoError(err)
+	latest, err := strconv.ParseInt(header.Get(grpctypes.GRPCBlockHeightHeader)[0], 10, 64)
+	s.Require().NoError(err)
+
+	// the state of a past height is still available
+	res, err := bankClient.Balance(atHeight(latest-1), req)
+	s.Require().NoError(err)
+	s.Require().Equal(sdk.NewCoin(denom, s.network.Config.AccountTokens), *res.Balance)
+
+	// the state of a height which was not committed is not
+	_, err = bankClient.Balance(atHeight(latest+1000), req)
+	s.Require().Error(err)
+	s.Require().Equal(codes.NotFound, status.Code(err))
+}
+
 // Test and enforce that we upfront reject any connections to baseapp containing
 // invalid initial x-cosmos-block-height that aren't positive  and in the range [0, max(int64)]
 // See issue https://github.com/cosmos/cosmos-sdk/issues/7662.
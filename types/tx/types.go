@@ -86,6 +86,10 @@ func (t *Tx) ValidateBasic() error {
 		}
 	}
 
+	if _, err := fee.GetFeeGranter(); err != nil {
+		return err
+	}
+
 	sigs := t.Signatures
 
 	if len(sigs) == 0 {
@@ -102,6 +106,21 @@ func (t *Tx) ValidateBasic() error {
 	return nil
 }
 
+// GetFeeGranter returns the address of the account granting the fee, or nil if
+// no fee granter is set. It returns an error wrapping ErrInvalidAddress if the
+// granter is not a valid address.
+func (m *Fee) GetFeeGranter() (sdk.AccAddress, error) {
+	if m.Granter == "" {
+		return nil, nil
+	}
+
+	granter, err := sdk.AccAddressFromBech32(m.Granter)
+	if err != nil {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "Invalid fee granter address (%s)", err)
+	}
+	return granter, nil
+}
+
 // GetSigners retrieves all the signers of a tx.
 // This includes all unique signers of the messages (in order),
 // as well as the FeePayer (if specified and not already included).
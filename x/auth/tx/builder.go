@@ -142,15 +142,11 @@ func (w *wrapper) FeePayer() sdk.AccAddress {
 }
 
 func (w *wrapper) FeeGranter() sdk.AccAddress {
-	feePayer := w.tx.AuthInfo.Fee.Granter
-	if feePayer != "" {
-		granterAddr, err := sdk.AccAddressFromBech32(feePayer)
-		if err != nil {
-			panic(err)
-		}
-		return granterAddr
+	granter, err := w.tx.AuthInfo.Fee.GetFeeGranter()
+	if err != nil {
+		panic(err)
 	}
-	return nil
+	return granter
 }
 
 func (w *wrapper) GetMemo() string {
@@ -0,0 +1,19 @@
+package feegrant
+
+import (
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/keeper"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+// BeginBlocker prunes all fee allowances which expired before the current
+// block time, then warns about the ones about to expire.
+func BeginBlocker(ctx sdk.Context, k keeper.Keeper) {
+	defer telemetry.ModuleMeasureSince(types.ModuleName, time.Now(), telemetry.MetricKeyBeginBlocker)
+
+	k.RemoveExpiredAllowances(ctx)
+	k.WarnExpiringAllowances(ctx)
+}
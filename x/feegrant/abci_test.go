@@ -0,0 +1,228 @@
+package feegrant_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+func TestBeginBlockerPrunesExpiredAllowances(t *testing.T) {
+	app := simapp.Setup(false)
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: now})
+	k := app.FeeGrantKeeper
+
+	addrs := simapp.AddTestAddrsIncremental(app, ctx, 6, sdk.NewInt(30000000))
+	granter := addrs[0]
+	oneHour, oneDay := now.Add(time.Hour), now.Add(24*time.Hour)
+
+	allowances := map[string]struct {
+		grantee sdk.AccAddress
+		exp     *time.Time
+	}{
+		"expires in an hour":      {addrs[1], &oneHour},
+		"expires in a day":        {addrs[2], &oneDay},
+		"never expires":           {addrs[3], nil},
+		"extended to a day":       {addrs[4], &oneHour},
+		"revoked before expiring": {addrs[5], &oneHour},
+	}
+	for _, a := range allowances {
+		require.NoError(t, k.GrantFeeAllowance(ctx, granter, a.grantee, &types.BasicAllowance{Expiration: a.exp}))
+	}
+
+	// replacing a grant must drop its old expiration from the queue
+	require.NoError(t, k.GrantFeeAllowance(ctx, granter, addrs[4], &types.BasicAllowance{Expiration: &oneDay}))
+	require.NoError(t, k.RevokeFeeAllowance(ctx, granter, addrs[5]))
+
+	exists := func(ctx sdk.Context, grantee sdk.AccAddress) bool {
+		grant, err := k.GetFeeAllowance(ctx, granter, grantee)
+		require.NoError(t, err)
+		return grant != nil
+	}
+
+	// nothing is pruned while the grants are still valid, even at the exact
+	// expiration time
+	ctx = ctx.WithBlockTime(oneHour).WithEventManager(sdk.NewEventManager())
+	feegrant.BeginBlocker(ctx, k)
+	require.Empty(t, ctx.EventManager().Events())
+	require.True(t, exists(ctx, addrs[1]))
+
+	ctx = ctx.WithBlockTime(oneHour.Add(time.Second)).WithEventManager(sdk.NewEventManager())
+	feegrant.BeginBlocker(ctx, k)
+	require.Equal(t, sdk.Events{
+		sdk.NewEvent(types.EventTypePruneFeeGrant,
+			sdk.NewAttribute(types.AttributeKeyGranter, granter.String()),
+			sdk.NewAttribute(types.AttributeKeyGrantee, addrs[1].String()),
+		),
+	}, ctx.EventManager().Events())
+	require.False(t, exists(ctx, addrs[1]))
+	require.True(t, exists(ctx, addrs[2]))
+	require.True(t, exists(ctx, addrs[3]))
+	require.True(t, exists(ctx, addrs[4]))
+
+	ctx = ctx.WithBlockTime(oneDay.Add(time.Second)).WithEventManager(sdk.NewEventManager())
+	feegrant.BeginBlocker(ctx, k)
+	require.Len(t, ctx.EventManager().Events(), 2)
+	require.False(t, exists(ctx, addrs[2]))
+	require.True(t, exists(ctx, addrs[3]))
+	require.False(t, exists(ctx, addrs[4]))
+
+	// the queue is now empty
+	ctx = ctx.WithBlockTime(oneDay.Add(time.Hour)).WithEventManager(sdk.NewEventManager())
+	feegrant.BeginBlocker(ctx, k)
+	require.Empty(t, ctx.EventManager().Events())
+}
+
+func TestBeginBlockerPrunesHeightExpiredAllowances(t *testing.T) {
+	app := simapp.Setup(false)
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: now, Height: 10})
+	k := app.FeeGrantKeeper
+
+	addrs := simapp.AddTestAddrsIncremental(app, ctx, 5, sdk.NewInt(30000000))
+	granter := addrs[0]
+
+	require.NoError(t, k.GrantFeeAllowance(ctx, granter, addrs[1], &types.BasicAllowance{ExpirationHeight: 20}))
+	require.NoError(t, k.GrantFeeAllowance(ctx, granter, addrs[2], &types.BasicAllowance{ExpirationHeight: 30}))
+	require.NoError(t, k.GrantFeeAllowance(ctx, granter, addrs[3], &types.BasicAllowance{}))
+	// replacing a grant must drop its old expiration height from the queue
+	require.NoError(t, k.GrantFeeAllowance(ctx, granter, addrs[4], &types.BasicAllowance{ExpirationHeight: 20}))
+	require.NoError(t, k.GrantFeeAllowance(ctx, granter, addrs[4], &types.BasicAllowance{ExpirationHeight: 30}))
+	require.Equal(t, uint64(4), k.GetGrantsCount(ctx))
+
+	exists := func(ctx sdk.Context, grantee sdk.AccAddress) bool {
+		grant, err := k.GetFeeAllowance(ctx, granter, grantee)
+		require.NoError(t, err)
+		return grant != nil
+	}
+
+	// nothing is pruned at the expiration height, at which grants can be used
+	ctx = ctx.WithBlockHeight(20).WithEventManager(sdk.NewEventManager())
+	feegrant.BeginBlocker(ctx, k)
+	require.Empty(t, ctx.EventManager().Events())
+	require.True(t, exists(ctx, addrs[1]))
+
+	ctx = ctx.WithBlockHeight(21).WithEventManager(sdk.NewEventManager())
+	feegrant.BeginBlocker(ctx, k)
+	require.Equal(t, sdk.Events{
+		sdk.NewEvent(types.EventTypePruneFeeGrant,
+			sdk.NewAttribute(types.AttributeKeyGranter, granter.String()),
+			sdk.NewAttribute(types.AttributeKeyGrantee, addrs[1].String()),
+		),
+	}, ctx.EventManager().Events())
+	require.False(t, exists(ctx, addrs[1]))
+	require.True(t, exists(ctx, addrs[2]))
+	require.True(t, exists(ctx, addrs[4]))
+	require.Equal(t, uint64(3), k.GetGrantsCount(ctx))
+
+	ctx = ctx.WithBlockHeight(31).WithEventManager(sdk.NewEventManager())
+	feegrant.BeginBlocker(ctx, k)
+	require.Len(t, ctx.EventManager().Events(), 2)
+	require.False(t, exists(ctx, addrs[2]))
+	require.True(t, exists(ctx, addrs[3]))
+	require.False(t, exists(ctx, addrs[4]))
+	require.Equal(t, uint64(1), k.GetGrantsCount(ctx))
+
+	// the queue is now empty
+	ctx = ctx.WithBlockHeight(100).WithEventManager(sdk.NewEventManager())
+	feegrant.BeginBlocker(ctx, k)
+	require.Empty(t, ctx.EventManager().Events())
+}
+
+// expiryHooks records the grants the BeforeAllowanceExpires hook is called for.
+type expiryHooks struct {
+	warnings []string
+}
+
+func (h *expiryHooks) AfterGrant(sdk.Context, sdk.AccAddress, sdk.AccAddress, types.FeeAllowanceI) {}
+func (h *expiryHooks) AfterRevoke(sdk.Context, sdk.AccAddress, sdk.AccAddress)                     {}
+func (h *expiryHooks) AfterUseAllowance(sdk.Context, sdk.AccAddress, sdk.AccAddress, sdk.Coins)    {}
+
+func (h *expiryHooks) BeforeAllowanceExpires(_ sdk.Context, granter, grantee sdk.AccAddress, expiresAt time.Time) {
+	h.warnings = append(h.warnings, granter.String()+" "+grantee.String()+" "+expiresAt.String())
+}
+
+func TestBeginBlockerWarnsExpiringAllowances(t *testing.T) {
+	app := simapp.Setup(false)
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: now})
+
+	hooks := &expiryHooks{}
+	k := app.FeeGrantKeeper
+	k.SetHooks(hooks)
+
+	params := k.GetParams(ctx)
+	params.ExpiryWarningWindow = time.Hour
+	k.SetParams(ctx, params)
+
+	addrs := simapp.AddTestAddrsIncremental(app, ctx, 3, sdk.NewInt(30000000))
+	granter, grantee := addrs[0], addrs[1]
+	exp := now.Add(3 * time.Hour)
+	warning := granter.String() + " " + grantee.String() + " " + exp.String()
+
+	spendLimit := sdk.NewCoins(sdk.NewInt64Coin("atom", 100))
+	require.NoError(t, k.GrantFeeAllowance(ctx, granter, grantee, &types.BasicAllowance{SpendLimit: spendLimit, Expiration: &exp}))
+	require.NoError(t, k.GrantFeeAllowance(ctx, granter, addrs[2], &types.BasicAllowance{SpendLimit: spendLimit}))
+
+	beginBlock := func(blockTime time.Time) {
+		ctx = ctx.WithBlockTime(blockTime)
+		feegrant.BeginBlocker(ctx, k)
+	}
+
+	// nothing is warned about before the grant enters the window, which
+	// excludes its exact start
+	beginBlock(now.Add(time.Hour))
+	beginBlock(exp.Add(-time.Hour))
+	require.Empty(t, hooks.warnings)
+
+	beginBlock(exp.Add(-59 * time.Minute))
+	require.Equal(t, []string{warning}, hooks.warnings)
+
+	// the grant is warned about once, even when it is used in the meantime
+	_, _, err := k.UseGrantedFees(ctx, granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("atom", 1)), nil)
+	require.NoError(t, err)
+	beginBlock(exp.Add(-30 * time.Minute))
+	beginBlock(exp)
+	require.Equal(t, []string{warning}, hooks.warnings)
+
+	// extending the grant warns again about its new expiration, once
+	newExp := exp.Add(2 * time.Hour)
+	require.NoError(t, k.ExtendExpiration(ctx, granter, grantee, newExp))
+	beginBlock(exp.Add(30 * time.Minute))
+	require.Len(t, hooks.warnings, 1)
+	beginBlock(newExp.Add(-30 * time.Minute))
+	beginBlock(newExp.Add(-10 * time.Minute))
+	require.Equal(t, []string{warning, granter.String() + " " + grantee.String() + " " + newExp.String()}, hooks.warnings)
+
+	// pruning the grant drops the record of its warning
+	beginBlock(newExp.Add(time.Second))
+	iter := sdk.KVStorePrefixIterator(ctx.KVStore(app.GetKey(types.StoreKey)), types.ExpiryWarningKeyPrefix)
+	require.False(t, iter.Valid())
+	require.NoError(t, iter.Close())
+	require.Len(t, hooks.warnings, 2)
+}
+
+func TestBeginBlockerExpiryWarningDisabled(t *testing.T) {
+	app := simapp.Setup(false)
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: now})
+
+	hooks := &expiryHooks{}
+	k := app.FeeGrantKeeper
+	k.SetHooks(hooks)
+	require.Zero(t, k.GetParams(ctx).ExpiryWarningWindow)
+
+	addrs := simapp.AddTestAddrsIncremental(app, ctx, 2, sdk.NewInt(30000000))
+	exp := now.Add(time.Minute)
+	require.NoError(t, k.GrantFeeAllowance(ctx, addrs[0], addrs[1], &types.BasicAllowance{Expiration: &exp}))
+
+	feegrant.BeginBlocker(ctx.WithBlockTime(exp.Add(-time.Second)), k)
+	require.Empty(t, hooks.warnings)
+}
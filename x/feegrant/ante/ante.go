@@ -0,0 +1,66 @@
+package ante
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authante "github.com/cosmos/cosmos-sdk/x/auth/ante"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/keeper"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+// NewAnteHandler returns an AnteHandler that checks and increments sequence
+// numbers, checks signatures & account numbers, and deducts fees from the
+// fee payer, or from the fee granter if the fee payer was granted an allowance.
+// It is the auth module's AnteHandler with the DeductFeeDecorator replaced by a
+// DeductGrantedFeeDecorator.
+func NewAnteHandler(
+	ak authante.AccountKeeper, bankKeeper types.BankKeeper, feeGrantKeeper keeper.Keeper,
+	sigGasConsumer authante.SignatureVerificationGasConsumer,
+	signModeHandler authsigning.SignModeHandler,
+) sdk.AnteHandler {
+	return newAnteHandler(ak, NewDeductGrantedFeeDecorator(ak, bankKeeper, feeGrantKeeper), sigGasConsumer, signModeHandler)
+}
+
+// NewDeferredAnteHandler returns an AnteHandler like NewAnteHandler's, except
+// that it only checks fee allowances without using them, the fee payers paying
+// the whole fees. It must be paired with the post handler returned by
+// NewPostHandler, which uses the allowances and refunds the fee payers once the
+// msgs of a tx succeeded.
+func NewDeferredAnteHandler(
+	ak authante.AccountKeeper, bankKeeper types.BankKeeper, feeGrantKeeper keeper.Keeper,
+	sigGasConsumer authante.SignatureVerificationGasConsumer,
+	signModeHandler authsigning.SignModeHandler,
+) sdk.AnteHandler {
+	return newAnteHandler(ak, NewDeferredDeductGrantedFeeDecorator(ak, bankKeeper, feeGrantKeeper), sigGasConsumer, signModeHandler)
+}
+
+// NewPostHandler returns the post handler using the fee allowances checked by
+// the AnteHandler returned by NewDeferredAnteHandler, and refunding the fee
+// payers the fees they cover.
+func NewPostHandler(ak authante.AccountKeeper, bankKeeper types.BankKeeper, feeGrantKeeper keeper.Keeper) sdk.AnteHandler {
+	return sdk.ChainAnteDecorators(
+		NewUseGrantedFeesDecorator(ak, bankKeeper, feeGrantKeeper),
+	)
+}
+
+func newAnteHandler(
+	ak authante.AccountKeeper, deductFeeDecorator DeductGrantedFeeDecorator,
+	sigGasConsumer authante.SignatureVerificationGasConsumer,
+	signModeHandler authsigning.SignModeHandler,
+) sdk.AnteHandler {
+	return sdk.ChainAnteDecorators(
+		authante.NewSetUpContextDecorator(), // outermost AnteDecorator. SetUpContext must be called first
+		authante.NewRejectExtensionOptionsDecorator(),
+		authante.NewMempoolFeeDecorator(),
+		authante.NewValidateBasicDecorator(),
+		authante.TxTimeoutHeightDecorator{},
+		authante.NewValidateMemoDecorator(ak),
+		authante.NewConsumeGasForTxSizeDecorator(ak),
+		authante.NewSetPubKeyDecorator(ak), // SetPubKeyDecorator must be called before all signature verification decorators
+		authante.NewValidateSigCountDecorator(ak),
+		deductFeeDecorator,
+		authante.NewSigGasConsumeDecorator(ak, sigGasConsumer),
+		authante.NewSigVerificationDecorator(ak, signModeHandler),
+		authante.NewIncrementSequenceDecorator(ak),
+	)
+}
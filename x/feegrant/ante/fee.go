@@ -0,0 +1,171 @@
+package ante
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	authante "github.com/cosmos/cosmos-sdk/x/auth/ante"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/keeper"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+// DeductGrantedFeeDecorator deducts fees from the fee payer, or from the fee
+// granter if one is set on the tx and it has granted the fee payer a valid fee
+// allowance. It replaces the auth module's DeductFeeDecorator.
+// If the account paying the fees does not have the funds, it returns an
+// InsufficientFunds error. A module account granter, e.g. a protocol owned
+// faucet, pays through the bank keeper's module account path.
+// CONTRACT: Tx must implement FeeTx interface to use DeductGrantedFeeDecorator
+type DeductGrantedFeeDecorator struct {
+	ak authante.AccountKeeper
+	k  keeper.Keeper
+	bk types.BankKeeper
+
+	// deferred is set if the allowance is only checked, the fee payer paying
+	// the whole fee until a UseGrantedFeesDecorator uses the allowance once the
+	// msgs succeeded
+	deferred bool
+}
+
+func NewDeductGrantedFeeDecorator(ak authante.AccountKeeper, bk types.BankKeeper, k keeper.Keeper) DeductGrantedFeeDecorator {
+	return DeductGrantedFeeDecorator{
+		ak: ak,
+		k:  k,
+		bk: bk,
+	}
+}
+
+// NewDeferredDeductGrantedFeeDecorator returns a DeductGrantedFeeDecorator
+// which checks that the allowance covers the fee, but deducts the whole fee
+// from the fee payer and does not update the allowance. The allowance must then
+// be used by a UseGrantedFeesDecorator in the app's post handler, which has the
+// granter refund the covered part of the fee. A tx whose msgs fail is thus paid
+// for by the fee payer without consuming the allowance, and the granter never
+// pays more than the allowance accepted.
+func NewDeferredDeductGrantedFeeDecorator(ak authante.AccountKeeper, bk types.BankKeeper, k keeper.Keeper) DeductGrantedFeeDecorator {
+	d := NewDeductGrantedFeeDecorator(ak, bk, k)
+	d.deferred = true
+	return d
+}
+
+// AnteHandle performs a decorated ante-handler responsible for deducting transaction
+// fees. Fees will be deducted from the account designated by the FeePayer on a
+// transaction by default. However, if the fee granter field is set, the fees
+// will be deducted from the granter's account, provided the granter has issued
+// a fee allowance to the fee payer which accepts this fee and these messages.
+// A granter which has not granted the fee payer an allowance fails the tx with
+// ErrNoAllowance. Why the allowance refused the fee is recorded, see
+// keeper.GetRejection.
+func (d DeductGrantedFeeDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (newCtx sdk.Context, err error) {
+	feeTx, ok := tx.(sdk.FeeTx)
+	if !ok {
+		return ctx, sdkerrors.Wrap(sdkerrors.ErrTxDecode, "Tx must be a FeeTx")
+	}
+
+	if addr := d.ak.GetModuleAddress(authtypes.FeeCollectorName); addr == nil {
+		panic(fmt.Sprintf("%s module account has not been set", authtypes.FeeCollectorName))
+	}
+
+	fee := feeTx.GetFee()
+	feePayer := feeTx.FeePayer()
+	feeGranter := feeTx.FeeGranter()
+
+	payerFee := fee
+
+	// if a fee granter was set, deduct the fee from the fee granter's account
+	// as long as the fee payer has been granted an allowance covering it
+	if feeGranter != nil && !feeGranter.Equals(feePayer) {
+		logger := d.k.Logger(ctx).With("granter", feeGranter.String(), "grantee", feePayer.String(), "fee", fee.String())
+
+		covered, outcome, err := d.useGrantedFees(ctx, feeGranter, feePayer, fee, tx.GetMsgs())
+		if err != nil {
+			// rejections are logged at info level so that operators can tell
+			// which allowance refused the fee
+			logger.Info("fee allowance rejected fee", "outcome", "rejected", "err", err)
+			d.k.RecordRejection(ctx, feeGranter, feePayer, err, simulate)
+			return ctx, sdkerrors.Wrapf(err, "%s not allowed to pay fees from %s", feePayer, feeGranter)
+		}
+
+		logger.Debug("fee allowance accepted fee", "outcome", outcome, "covered", covered.String())
+
+		// the granter of a deferred allowance only refunds the fee payer once
+		// the msgs succeeded, see UseGrantedFeesDecorator
+		if !d.deferred {
+			if err := d.deductFeesFrom(ctx, feeGranter, covered); err != nil {
+				return ctx, err
+			}
+
+			// the fee payer pays whatever part of the fee the allowance does
+			// not cover
+			remainder, hasNeg := fee.SafeSub(covered)
+			if hasNeg {
+				return ctx, sdkerrors.Wrapf(sdkerrors.ErrInvalidCoins, "covered fee %s exceeds fee %s", covered, fee)
+			}
+			payerFee = remainder
+		}
+	}
+
+	if err := d.deductFeesFrom(ctx, feePayer, payerFee); err != nil {
+		return ctx, err
+	}
+
+	return next(ctx, tx, simulate)
+}
+
+// useGrantedFees uses the allowance for the fee, or only checks that it covers
+// the fee if the decorator is deferred. It returns the part of the fee covered
+// and what became of the allowance.
+func (d DeductGrantedFeeDecorator) useGrantedFees(ctx sdk.Context, feeGranter, feePayer sdk.AccAddress, fee sdk.Coins, msgs []sdk.Msg) (sdk.Coins, string, error) {
+	if d.deferred {
+		covered, err := d.k.CanUseGrantedFees(ctx, feeGranter, feePayer, fee, msgs)
+		return covered, "deferred", err
+	}
+
+	covered, removed, err := d.k.UseGrantedFees(ctx, feeGranter, feePayer, fee, msgs)
+	if removed {
+		return covered, "removed", err
+	}
+	return covered, "spent", err
+}
+
+// deductFeesFrom deducts fees from the account at addr, which must exist.
+func (d DeductGrantedFeeDecorator) deductFeesFrom(ctx sdk.Context, addr sdk.AccAddress, fees sdk.Coins) error {
+	acc := d.ak.GetAccount(ctx, addr)
+	if acc == nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrUnknownAddress, "fee payer address: %s does not exist", addr)
+	}
+
+	if fees.IsZero() {
+		return nil
+	}
+
+	return d.deductFees(ctx, acc, fees)
+}
+
+// deductFees sends fees from acc to the fee collector, going from module to
+// module if acc is a module account. A module account whose module is not
+// registered with the account keeper cannot pay fees.
+func (d DeductGrantedFeeDecorator) deductFees(ctx sdk.Context, acc authtypes.AccountI, fees sdk.Coins) error {
+	macc, ok := acc.(authtypes.ModuleAccountI)
+	if !ok {
+		return authante.DeductFees(d.bk, ctx, acc, fees)
+	}
+
+	// the bank keeper panics on a module it does not know of
+	if !acc.GetAddress().Equals(d.ak.GetModuleAddress(macc.GetName())) {
+		return sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "module account %s cannot pay fees", macc.GetName())
+	}
+
+	if !fees.IsValid() {
+		return sdkerrors.Wrapf(sdkerrors.ErrInsufficientFee, "invalid fee amount: %s", fees)
+	}
+
+	err := d.bk.SendCoinsFromModuleToModule(ctx, macc.GetName(), authtypes.FeeCollectorName, fees)
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInsufficientFunds, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,355 @@
+package ante_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tendermint/tendermint/libs/log"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/ante"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+type setFeeGranter interface {
+	SetFeeGranter(feeGranter sdk.AccAddress)
+}
+
+type AnteTestSuite struct {
+	suite.Suite
+
+	app         *simapp.SimApp
+	ctx         sdk.Context
+	txConfig    client.TxConfig
+	anteHandler sdk.AnteHandler
+	addrs       []sdk.AccAddress
+}
+
+func (suite *AnteTestSuite) SetupTest() {
+	suite.app = simapp.Setup(false)
+	suite.ctx = suite.app.BaseApp.NewContext(false, tmproto.Header{})
+	suite.app.AccountKeeper.SetParams(suite.ctx, authtypes.DefaultParams())
+
+	suite.txConfig = simapp.MakeTestEncodingConfig().TxConfig
+	suite.anteHandler = sdk.ChainAnteDecorators(
+		ante.NewDeductGrantedFeeDecorator(suite.app.AccountKeeper, suite.app.BankKeeper, suite.app.FeeGrantKeeper),
+	)
+	suite.addrs = simapp.AddTestAddrsIncremental(suite.app, suite.ctx, 3, sdk.NewInt(1000))
+}
+
+func (suite *AnteTestSuite) newTx(feeGranter, feePayer sdk.AccAddress, fee sdk.Coins) sdk.Tx {
+	txBuilder := suite.txConfig.NewTxBuilder()
+	msg := banktypes.NewMsgSend(feePayer, feePayer, sdk.NewCoins(sdk.NewInt64Coin("stake", 1)))
+	suite.Require().NoError(txBuilder.SetMsgs(msg))
+	txBuilder.SetFeeAmount(fee)
+	txBuilder.(setFeeGranter).SetFeeGranter(feeGranter)
+
+	return txBuilder.GetTx()
+}
+
+func (suite *AnteTestSuite) TestDeductGrantedFees() {
+	granter, grantee, stranger := suite.addrs[0], suite.addrs[1], suite.addrs[2]
+	fee := sdk.NewCoins(sdk.NewInt64Coin("stake", 100))
+
+	err := suite.app.FeeGrantKeeper.GrantFeeAllowance(suite.ctx, granter, grantee, &types.BasicAllowance{
+		SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("stake", 150)),
+	})
+	suite.Require().NoError(err)
+
+	testCases := []struct {
+		name       string
+		feeGranter sdk.AccAddress
+		feePayer   sdk.AccAddress
+		fee        sdk.Coins
+		expErr     error
+		deductFrom sdk.AccAddress
+		expGrant   bool
+	}{
+		{
+			"no granter, fee payer pays",
+			nil, grantee, fee, nil, grantee, true,
+		},
+		{
+			"granter is the fee payer",
+			grantee, grantee, fee, nil, grantee, true,
+		},
+		{
+			"granter pays",
+			granter, grantee, fee, nil, granter, true,
+		},
+		{
+			"insufficient allowance",
+			granter, grantee, fee, types.ErrFeeLimitExceeded, nil, true,
+		},
+		{
+			"unauthorized granter",
+			granter, stranger, fee, types.ErrNoAllowance, nil, false,
+		},
+		{
+			"granter pays remaining allowance and grant is removed",
+			granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("stake", 50)), nil, granter, false,
+		},
+		{
+			"used up grant",
+			granter, grantee, fee, types.ErrNoAllowance, nil, false,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			var before sdk.Coins
+			if tc.deductFrom != nil {
+				before = suite.app.BankKeeper.GetAllBalances(suite.ctx, tc.deductFrom)
+			}
+
+			_, err := suite.anteHandler(suite.ctx, suite.newTx(tc.feeGranter, tc.feePayer, tc.fee), false)
+			if tc.expErr != nil {
+				suite.Require().Error(err)
+				suite.Require().True(errors.Is(err, tc.expErr))
+			} else {
+				suite.Require().NoError(err)
+				after := suite.app.BankKeeper.GetAllBalances(suite.ctx, tc.deductFrom)
+				suite.Require().Equal(before.Sub(tc.fee), after)
+			}
+
+			grant, err := suite.app.FeeGrantKeeper.GetFeeAllowance(suite.ctx, granter, tc.feePayer)
+			suite.Require().NoError(err)
+			suite.Require().Equal(tc.expGrant, grant != nil)
+		})
+	}
+}
+
+func (suite *AnteTestSuite) TestGranterWithoutFunds() {
+	granter, grantee := sdk.AccAddress("empty_granter_______"), suite.addrs[1]
+	suite.app.AccountKeeper.SetAccount(suite.ctx, suite.app.AccountKeeper.NewAccountWithAddress(suite.ctx, granter))
+
+	err := suite.app.FeeGrantKeeper.GrantFeeAllowance(suite.ctx, granter, grantee, &types.BasicAllowance{})
+	suite.Require().NoError(err)
+
+	_, err = suite.anteHandler(suite.ctx, suite.newTx(granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("stake", 100))), false)
+	suite.Require().True(errors.Is(err, sdkerrors.ErrInsufficientFunds))
+}
+
+func (suite *AnteTestSuite) TestDeductGrantedFeesLogging() {
+	granter, grantee := suite.addrs[0], suite.addrs[1]
+	err := suite.app.FeeGrantKeeper.GrantFeeAllowance(suite.ctx, granter, grantee, &types.BasicAllowance{
+		SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("stake", 150)),
+	})
+	suite.Require().NoError(err)
+
+	var buf bytes.Buffer
+	logger := log.NewFilter(log.NewTMLogger(log.NewSyncWriter(&buf)), log.AllowInfo())
+	ctx := suite.ctx.WithLogger(logger)
+
+	// accepted fees are logged at debug level only
+	_, err = suite.anteHandler(ctx, suite.newTx(granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("stake", 100))), false)
+	suite.Require().NoError(err)
+	suite.Require().Empty(buf.String())
+
+	_, err = suite.anteHandler(ctx, suite.newTx(granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("stake", 100))), false)
+	suite.Require().True(errors.Is(err, types.ErrFeeLimitExceeded))
+
+	line := buf.String()
+	suite.Require().Contains(line, "fee allowance rejected fee")
+	suite.Require().Contains(line, "outcome=rejected")
+	suite.Require().Contains(line, "granter="+granter.String())
+	suite.Require().Contains(line, "grantee="+grantee.String())
+	suite.Require().Contains(line, "fee=100stake")
+
+	// at debug level, accepted fees are logged with whether they used up the grant
+	buf.Reset()
+	ctx = suite.ctx.WithLogger(log.NewTMLogger(log.NewSyncWriter(&buf)))
+	_, err = suite.anteHandler(ctx, suite.newTx(granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("stake", 20))), false)
+	suite.Require().NoError(err)
+	suite.Require().Contains(buf.String(), "outcome=spent")
+
+	buf.Reset()
+	_, err = suite.anteHandler(ctx, suite.newTx(granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("stake", 30))), false)
+	suite.Require().NoError(err)
+	suite.Require().Contains(buf.String(), "outcome=removed")
+}
+
+func (suite *AnteTestSuite) TestDeductGrantedFeesFromModuleAccount() {
+	grantee := suite.addrs[1]
+	fee := sdk.NewCoins(sdk.NewInt64Coin("stake", 100))
+
+	macc := suite.app.AccountKeeper.GetModuleAccount(suite.ctx, distrtypes.ModuleName)
+	suite.Require().NoError(suite.app.BankKeeper.AddCoins(suite.ctx, macc.GetAddress(), sdk.NewCoins(sdk.NewInt64Coin("stake", 1000))))
+
+	err := suite.app.FeeGrantKeeper.GrantFeeAllowance(suite.ctx, macc.GetAddress(), grantee, &types.BasicAllowance{
+		SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("stake", 150)),
+	})
+	suite.Require().NoError(err)
+
+	feeCollector := suite.app.AccountKeeper.GetModuleAddress(authtypes.FeeCollectorName)
+	collected := suite.app.BankKeeper.GetAllBalances(suite.ctx, feeCollector)
+
+	_, err = suite.anteHandler(suite.ctx, suite.newTx(macc.GetAddress(), grantee, fee), false)
+	suite.Require().NoError(err)
+
+	suite.Require().Equal(sdk.NewCoins(sdk.NewInt64Coin("stake", 900)), suite.app.BankKeeper.GetAllBalances(suite.ctx, macc.GetAddress()))
+	suite.Require().Equal(collected.Add(fee...), suite.app.BankKeeper.GetAllBalances(suite.ctx, feeCollector))
+
+	remaining, err := suite.app.FeeGrantKeeper.GetFeeAllowance(suite.ctx, macc.GetAddress(), grantee)
+	suite.Require().NoError(err)
+	suite.Require().Equal(&types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("stake", 50))}, remaining)
+
+	// the module account cannot pay more than it holds
+	err = suite.app.FeeGrantKeeper.UpdateFeeAllowance(suite.ctx, macc.GetAddress(), grantee, &types.BasicAllowance{})
+	suite.Require().NoError(err)
+	_, err = suite.anteHandler(suite.ctx, suite.newTx(macc.GetAddress(), grantee, sdk.NewCoins(sdk.NewInt64Coin("stake", 1000))), false)
+	suite.Require().True(errors.Is(err, sdkerrors.ErrInsufficientFunds))
+}
+
+func (suite *AnteTestSuite) TestDeductGrantedFeesFromUnregisteredModuleAccount() {
+	grantee := suite.addrs[1]
+	fee := sdk.NewCoins(sdk.NewInt64Coin("stake", 100))
+
+	// a module account left in state by a module the app no longer has
+	macc := authtypes.NewEmptyModuleAccount("unregistered")
+	suite.app.AccountKeeper.SetAccount(suite.ctx, suite.app.AccountKeeper.NewAccount(suite.ctx, macc))
+	suite.Require().NoError(suite.app.BankKeeper.AddCoins(suite.ctx, macc.GetAddress(), sdk.NewCoins(sdk.NewInt64Coin("stake", 1000))))
+
+	err := suite.app.FeeGrantKeeper.GrantFeeAllowance(suite.ctx, macc.GetAddress(), grantee, &types.BasicAllowance{})
+	suite.Require().NoError(err)
+
+	suite.Require().NotPanics(func() {
+		_, err = suite.anteHandler(suite.ctx, suite.newTx(macc.GetAddress(), grantee, fee), false)
+	})
+	suite.Require().True(errors.Is(err, sdkerrors.ErrUnauthorized))
+	suite.Require().Equal(sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)), suite.app.BankKeeper.GetAllBalances(suite.ctx, macc.GetAddress()))
+}
+
+func (suite *AnteTestSuite) TestDeductCappedFractionOfFees() {
+	granter, grantee := suite.addrs[0], suite.addrs[1]
+
+	allowance, err := types.NewCappedFractionAllowance(&types.BasicAllowance{
+		SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("stake", 150)),
+	}, sdk.NewDecWithPrec(5, 1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.app.FeeGrantKeeper.GrantFeeAllowance(suite.ctx, granter, grantee, allowance))
+
+	granterBefore := suite.app.BankKeeper.GetAllBalances(suite.ctx, granter)
+	granteeBefore := suite.app.BankKeeper.GetAllBalances(suite.ctx, grantee)
+
+	// the granter covers half of the fee, rounded down, and the grantee pays the rest
+	_, err = suite.anteHandler(suite.ctx, suite.newTx(granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("stake", 101))), false)
+	suite.Require().NoError(err)
+
+	suite.Require().Equal(granterBefore.Sub(sdk.NewCoins(sdk.NewInt64Coin("stake", 50))), suite.app.BankKeeper.GetAllBalances(suite.ctx, granter))
+	suite.Require().Equal(granteeBefore.Sub(sdk.NewCoins(sdk.NewInt64Coin("stake", 51))), suite.app.BankKeeper.GetAllBalances(suite.ctx, grantee))
+
+	grant, err := suite.app.FeeGrantKeeper.GetFeeAllowance(suite.ctx, granter, grantee)
+	suite.Require().NoError(err)
+	remaining, err := grant.Remaining(suite.ctx)
+	suite.Require().NoError(err)
+	suite.Require().Equal(sdk.NewCoins(sdk.NewInt64Coin("stake", 100)), remaining)
+}
+
+func (suite *AnteTestSuite) TestDeferredDeductGrantedFees() {
+	granter, grantee := suite.addrs[0], suite.addrs[1]
+	limit := sdk.NewCoins(sdk.NewInt64Coin("stake", 150))
+	fee := sdk.NewCoins(sdk.NewInt64Coin("stake", 100))
+	suite.Require().NoError(suite.app.FeeGrantKeeper.GrantFeeAllowance(suite.ctx, granter, grantee, &types.BasicAllowance{SpendLimit: limit}))
+
+	anteHandler := sdk.ChainAnteDecorators(
+		ante.NewDeferredDeductGrantedFeeDecorator(suite.app.AccountKeeper, suite.app.BankKeeper, suite.app.FeeGrantKeeper),
+	)
+	postHandler := ante.NewPostHandler(suite.app.AccountKeeper, suite.app.BankKeeper, suite.app.FeeGrantKeeper)
+	tx := suite.newTx(granter, grantee, fee)
+	granterBefore := suite.app.BankKeeper.GetAllBalances(suite.ctx, granter)
+	granteeBefore := suite.app.BankKeeper.GetAllBalances(suite.ctx, grantee)
+
+	// the grantee pays, and the allowance is left untouched
+	_, err := anteHandler(suite.ctx, tx, false)
+	suite.Require().NoError(err)
+	suite.Require().Equal(granterBefore, suite.app.BankKeeper.GetAllBalances(suite.ctx, granter))
+	suite.Require().Equal(granteeBefore.Sub(fee), suite.app.BankKeeper.GetAllBalances(suite.ctx, grantee))
+
+	grant, err := suite.app.FeeGrantKeeper.GetFeeAllowance(suite.ctx, granter, grantee)
+	suite.Require().NoError(err)
+	suite.Require().Equal(&types.BasicAllowance{SpendLimit: limit}, grant)
+
+	// until the post handler uses it and has the granter refund the grantee
+	_, err = postHandler(suite.ctx, tx, false)
+	suite.Require().NoError(err)
+	suite.Require().Equal(granterBefore.Sub(fee), suite.app.BankKeeper.GetAllBalances(suite.ctx, granter))
+	suite.Require().Equal(granteeBefore, suite.app.BankKeeper.GetAllBalances(suite.ctx, grantee))
+
+	grant, err = suite.app.FeeGrantKeeper.GetFeeAllowance(suite.ctx, granter, grantee)
+	suite.Require().NoError(err)
+	suite.Require().Equal(&types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("stake", 50))}, grant)
+
+	// the allowance is still checked before the fee is deducted
+	_, err = anteHandler(suite.ctx, tx, false)
+	suite.Require().True(errors.Is(err, types.ErrFeeLimitExceeded))
+	suite.Require().Equal(granteeBefore, suite.app.BankKeeper.GetAllBalances(suite.ctx, grantee))
+
+	// and again by the post handler, which refunds nothing
+	_, err = postHandler(suite.ctx, tx, false)
+	suite.Require().True(errors.Is(err, types.ErrFeeLimitExceeded))
+	suite.Require().Equal(granterBefore.Sub(fee), suite.app.BankKeeper.GetAllBalances(suite.ctx, granter))
+}
+
+func (suite *AnteTestSuite) TestRecordRejections() {
+	granter, grantee := suite.addrs[0], suite.addrs[1]
+	k := suite.app.FeeGrantKeeper
+	fee := sdk.NewCoins(sdk.NewInt64Coin("stake", 100))
+	limit := sdk.NewCoins(sdk.NewInt64Coin("stake", 150))
+
+	_, found := k.GetRejection(granter, grantee)
+	suite.Require().False(found)
+
+	msgNotAllowed, err := types.NewAllowedMsgAllowance(&types.BasicAllowance{SpendLimit: limit}, []string{"/cosmos.gov.v1beta1.MsgVote"})
+	suite.Require().NoError(err)
+	denomNotAllowed, err := types.NewAllowedDenomAllowance(&types.BasicAllowance{}, []string{"atom"})
+	suite.Require().NoError(err)
+
+	testCases := []struct {
+		name      string
+		allowance types.FeeAllowanceI
+		simulate  bool
+		expReason types.RejectionReason
+	}{
+		{"no allowance", nil, false, types.RejectionNoAllowance},
+		{"expired", &types.BasicAllowance{SpendLimit: limit, ExpirationHeight: 5}, false, types.RejectionExpired},
+		{"exhausted", &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("stake", 10))}, false, types.RejectionExhausted},
+		{"msg not allowed", msgNotAllowed, false, types.RejectionMsgNotAllowed},
+		{"denom not allowed", denomNotAllowed, false, types.RejectionDenomNotAllowed},
+		{"simulated", msgNotAllowed, true, types.RejectionMsgNotAllowed},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			_ = k.RevokeFeeAllowance(suite.ctx, granter, grantee)
+			if tc.allowance != nil {
+				suite.Require().NoError(k.GrantFeeAllowance(suite.ctx, granter, grantee, tc.allowance))
+			}
+
+			ctx := suite.ctx.WithBlockHeight(10)
+			_, err := suite.anteHandler(ctx, suite.newTx(granter, grantee, fee), tc.simulate)
+			suite.Require().Error(err)
+
+			rejection, found := k.GetRejection(granter, grantee)
+			suite.Require().True(found)
+			suite.Require().Equal(tc.expReason, rejection.Reason)
+			suite.Require().Equal(types.RejectionReasonOf(err), rejection.Reason)
+			suite.Require().NotEmpty(rejection.Log)
+			suite.Require().Equal(int64(10), rejection.Height)
+			suite.Require().Equal(tc.simulate, rejection.Simulated)
+		})
+	}
+}
+
+func TestAnteTestSuite(t *testing.T) {
+	suite.Run(t, new(AnteTestSuite))
+}
@@ -0,0 +1,92 @@
+package ante
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	authante "github.com/cosmos/cosmos-sdk/x/auth/ante"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/keeper"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+// UseGrantedFeesDecorator uses the fee allowance granted to the fee payer of a
+// tx by its fee granter, if one is set, and has the granter refund the fee
+// payer the part of the fee the allowance covers. It is meant to run in the
+// post handler of an app whose ante handler was built with a deferred
+// DeductGrantedFeeDecorator, which only checked the allowance and deducted the
+// whole fee from the fee payer. Since the post handler only runs once the msgs
+// of the tx succeeded, a tx whose msgs fail does not consume the allowance.
+// CONTRACT: Tx must implement FeeTx interface to use UseGrantedFeesDecorator
+type UseGrantedFeesDecorator struct {
+	ak authante.AccountKeeper
+	k  keeper.Keeper
+	bk types.BankKeeper
+}
+
+func NewUseGrantedFeesDecorator(ak authante.AccountKeeper, bk types.BankKeeper, k keeper.Keeper) UseGrantedFeesDecorator {
+	return UseGrantedFeesDecorator{
+		ak: ak,
+		k:  k,
+		bk: bk,
+	}
+}
+
+// AnteHandle uses the fee allowance for the fee of the tx, and refunds the fee
+// payer the covered part of the fee. It fails the tx, and so reverts its msgs,
+// if the allowance no longer accepts the fee, e.g. because the msgs revoked it,
+// or if the granter cannot refund the fee payer.
+func (d UseGrantedFeesDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (newCtx sdk.Context, err error) {
+	feeTx, ok := tx.(sdk.FeeTx)
+	if !ok {
+		return ctx, sdkerrors.Wrap(sdkerrors.ErrTxDecode, "Tx must be a FeeTx")
+	}
+
+	feePayer := feeTx.FeePayer()
+	feeGranter := feeTx.FeeGranter()
+
+	if feeGranter != nil && !feeGranter.Equals(feePayer) {
+		covered, _, err := d.k.UseGrantedFees(ctx, feeGranter, feePayer, feeTx.GetFee(), tx.GetMsgs())
+		if err != nil {
+			d.k.RecordRejection(ctx, feeGranter, feePayer, err, simulate)
+			return ctx, sdkerrors.Wrapf(err, "%s not allowed to pay fees from %s", feePayer, feeGranter)
+		}
+
+		if err := d.refundFees(ctx, feeGranter, feePayer, covered); err != nil {
+			return ctx, err
+		}
+	}
+
+	return next(ctx, tx, simulate)
+}
+
+// refundFees sends fees from the granter to the fee payer, going from module
+// to account if the granter is a module account. A module account whose module
+// is not registered with the account keeper cannot refund fees.
+func (d UseGrantedFeesDecorator) refundFees(ctx sdk.Context, feeGranter, feePayer sdk.AccAddress, fees sdk.Coins) error {
+	if fees.IsZero() {
+		return nil
+	}
+
+	acc := d.ak.GetAccount(ctx, feeGranter)
+	if acc == nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrUnknownAddress, "fee granter address: %s does not exist", feeGranter)
+	}
+
+	var err error
+	if macc, ok := acc.(authtypes.ModuleAccountI); ok {
+		// the bank keeper panics on a module it does not know of
+		if !feeGranter.Equals(d.ak.GetModuleAddress(macc.GetName())) {
+			return sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "module account %s cannot pay fees", macc.GetName())
+		}
+
+		err = d.bk.SendCoinsFromModuleToAccount(ctx, macc.GetName(), feePayer, fees)
+	} else {
+		err = d.bk.SendCoins(ctx, feeGranter, feePayer, fees)
+	}
+
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInsufficientFunds, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package feegrant_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/testutil"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+func TestGrantedFeeTxs(t *testing.T) {
+	app, accounts := testutil.Setup(t, 3, sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)))
+	granter, grantee, recipient := accounts[0], accounts[1], accounts[2]
+
+	fee := sdk.NewCoins(sdk.NewInt64Coin("stake", 10))
+	send := banktypes.NewMsgSend(grantee.Address, recipient.Address, sdk.NewCoins(sdk.NewInt64Coin("stake", 1)))
+
+	_, err := app.GrantFeeAllowance(t, granter, grantee.Address, &types.BasicAllowance{
+		SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("stake", 25)),
+	}, fee)
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 990)), app.BankKeeper.GetAllBalances(app.Context(), granter.Address))
+
+	// the granter pays the fee and the allowance is reduced by it
+	_, err = app.DeliverGrantedFeeTx(t, granter.Address, grantee, fee, send)
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 980)), app.BankKeeper.GetAllBalances(app.Context(), granter.Address))
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 999)), app.BankKeeper.GetAllBalances(app.Context(), grantee.Address))
+
+	grant, err := app.FeeGrantKeeper.GetFeeAllowance(app.Context(), granter.Address, grantee.Address)
+	require.NoError(t, err)
+	require.Equal(t, &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("stake", 15))}, grant)
+
+	// a fee above the remaining allowance is rejected by the ante handler
+	_, err = app.DeliverGrantedFeeTx(t, granter.Address, grantee, sdk.NewCoins(sdk.NewInt64Coin("stake", 20)), send)
+	require.True(t, errors.Is(err, types.ErrFeeLimitExceeded))
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 980)), app.BankKeeper.GetAllBalances(app.Context(), granter.Address))
+
+	// using up the allowance removes the grant
+	_, err = app.DeliverGrantedFeeTx(t, granter.Address, grantee, sdk.NewCoins(sdk.NewInt64Coin("stake", 15)), send)
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 965)), app.BankKeeper.GetAllBalances(app.Context(), granter.Address))
+
+	grant, err = app.FeeGrantKeeper.GetFeeAllowance(app.Context(), granter.Address, grantee.Address)
+	require.NoError(t, err)
+	require.Nil(t, grant)
+
+	_, err = app.DeliverGrantedFeeTx(t, granter.Address, grantee, fee, send)
+	require.True(t, errors.Is(err, types.ErrNoAllowance))
+}
+
+func TestRevokedGrantNoLongerPaysFees(t *testing.T) {
+	app, accounts := testutil.Setup(t, 2, sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)))
+	granter, grantee := accounts[0], accounts[1]
+
+	_, err := app.GrantFeeAllowance(t, granter, grantee.Address, &types.BasicAllowance{}, nil)
+	require.NoError(t, err)
+
+	revoke := types.NewMsgRevokeFeeAllowance(granter.Address, grantee.Address)
+	_, err = app.SignAndDeliver(t, granter, nil, nil, &revoke)
+	require.NoError(t, err)
+
+	send := banktypes.NewMsgSend(grantee.Address, granter.Address, sdk.NewCoins(sdk.NewInt64Coin("stake", 1)))
+	_, err = app.DeliverGrantedFeeTx(t, granter.Address, grantee, sdk.NewCoins(sdk.NewInt64Coin("stake", 10)), send)
+	require.True(t, errors.Is(err, types.ErrNoAllowance))
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)), app.BankKeeper.GetAllBalances(app.Context(), granter.Address))
+}
+
+func TestGrantedFeeTxsIBCDenom(t *testing.T) {
+	ibcDenom := "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2"
+	app, accounts := testutil.Setup(t, 2, sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 1000), sdk.NewInt64Coin("stake", 1000)))
+	granter, grantee := accounts[0], accounts[1]
+
+	_, err := app.GrantFeeAllowance(t, granter, grantee.Address, &types.BasicAllowance{
+		SpendLimit: sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 25)),
+	}, nil)
+	require.NoError(t, err)
+
+	send := banktypes.NewMsgSend(grantee.Address, granter.Address, sdk.NewCoins(sdk.NewInt64Coin("stake", 1)))
+	_, err = app.DeliverGrantedFeeTx(t, granter.Address, grantee, sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 10)), send)
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewInt64Coin(ibcDenom, 990), app.BankKeeper.GetBalance(app.Context(), granter.Address, ibcDenom))
+
+	grant, err := app.FeeGrantKeeper.GetFeeAllowance(app.Context(), granter.Address, grantee.Address)
+	require.NoError(t, err)
+	require.Equal(t, &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 15))}, grant)
+
+	// the allowance does not pay fees in another denom
+	_, err = app.DeliverGrantedFeeTx(t, granter.Address, grantee, sdk.NewCoins(sdk.NewInt64Coin("stake", 10)), send)
+	require.True(t, errors.Is(err, types.ErrFeeLimitExceeded))
+	require.Equal(t, sdk.NewInt64Coin("stake", 1001), app.BankKeeper.GetBalance(app.Context(), granter.Address, "stake"))
+}
+
+func TestDeferredGrantedFeeTxs(t *testing.T) {
+	limit := sdk.NewCoins(sdk.NewInt64Coin("stake", 25))
+	fee := sdk.NewCoins(sdk.NewInt64Coin("stake", 10))
+
+	testCases := []struct {
+		name       string
+		setup      func(t *testing.T, numAccounts int, coins sdk.Coins) (*testutil.App, []testutil.Account)
+		expGranter sdk.Coins
+		expGrantee sdk.Coins
+		expGrant   types.FeeAllowanceI
+	}{
+		{
+			"allowance used in the ante handler",
+			testutil.Setup,
+			sdk.NewCoins(sdk.NewInt64Coin("stake", 990)),
+			sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)),
+			&types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("stake", 15))},
+		},
+		{
+			"allowance used in the post handler",
+			testutil.SetupDeferred,
+			sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)),
+			sdk.NewCoins(sdk.NewInt64Coin("stake", 990)),
+			&types.BasicAllowance{SpendLimit: limit},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			app, accounts := tc.setup(t, 2, sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)))
+			granter, grantee := accounts[0], accounts[1]
+
+			_, err := app.GrantFeeAllowance(t, granter, grantee.Address, &types.BasicAllowance{SpendLimit: limit}, nil)
+			require.NoError(t, err)
+
+			// the msg fails, but the tx is included and its fee paid
+			overspend := banktypes.NewMsgSend(grantee.Address, granter.Address, sdk.NewCoins(sdk.NewInt64Coin("stake", 5000)))
+			_, err = app.DeliverGrantedFeeTx(t, granter.Address, grantee, fee, overspend)
+			require.True(t, errors.Is(err, sdkerrors.ErrInsufficientFunds))
+			require.Equal(t, tc.expGranter, app.BankKeeper.GetAllBalances(app.Context(), granter.Address))
+			require.Equal(t, tc.expGrantee, app.BankKeeper.GetAllBalances(app.Context(), grantee.Address))
+
+			grant, err := app.FeeGrantKeeper.GetFeeAllowance(app.Context(), granter.Address, grantee.Address)
+			require.NoError(t, err)
+			require.Equal(t, tc.expGrant, grant)
+		})
+	}
+}
+
+func TestDeferredGrantedFeeTxsFailingRepeatedly(t *testing.T) {
+	app, accounts := testutil.SetupDeferred(t, 2, sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)))
+	granter, grantee := accounts[0], accounts[1]
+
+	limit := sdk.NewCoins(sdk.NewInt64Coin("stake", 25))
+	_, err := app.GrantFeeAllowance(t, granter, grantee.Address, &types.BasicAllowance{SpendLimit: limit}, nil)
+	require.NoError(t, err)
+
+	// txs whose msgs fail cost the granter nothing, however many the grantee
+	// sends and whatever the allowance would cover in total
+	fee := sdk.NewCoins(sdk.NewInt64Coin("stake", 10))
+	overspend := banktypes.NewMsgSend(grantee.Address, granter.Address, sdk.NewCoins(sdk.NewInt64Coin("stake", 5000)))
+	for i := 1; i <= 10; i++ {
+		_, err = app.DeliverGrantedFeeTx(t, granter.Address, grantee, fee, overspend)
+		require.True(t, errors.Is(err, sdkerrors.ErrInsufficientFunds))
+		require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)), app.BankKeeper.GetAllBalances(app.Context(), granter.Address))
+		require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 1000-10*int64(i))), app.BankKeeper.GetAllBalances(app.Context(), grantee.Address))
+	}
+
+	grant, err := app.FeeGrantKeeper.GetFeeAllowance(app.Context(), granter.Address, grantee.Address)
+	require.NoError(t, err)
+	require.Equal(t, &types.BasicAllowance{SpendLimit: limit}, grant)
+
+	// a grantee who cannot pay the fee upfront cannot send txs at all
+	_, err = app.DeliverGrantedFeeTx(t, granter.Address, grantee, sdk.NewCoins(sdk.NewInt64Coin("stake", 901)), overspend)
+	require.Error(t, err)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)), app.BankKeeper.GetAllBalances(app.Context(), granter.Address))
+}
+
+func TestDeferredGrantedFeeTxsUseAllowance(t *testing.T) {
+	app, accounts := testutil.SetupDeferred(t, 2, sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)))
+	granter, grantee := accounts[0], accounts[1]
+
+	_, err := app.GrantFeeAllowance(t, granter, grantee.Address, &types.BasicAllowance{
+		SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("stake", 25)),
+	}, nil)
+	require.NoError(t, err)
+
+	// a tx whose msgs succeed uses the allowance
+	send := banktypes.NewMsgSend(grantee.Address, granter.Address, sdk.NewCoins(sdk.NewInt64Coin("stake", 1)))
+	_, err = app.DeliverGrantedFeeTx(t, granter.Address, grantee, sdk.NewCoins(sdk.NewInt64Coin("stake", 10)), send)
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 991)), app.BankKeeper.GetAllBalances(app.Context(), granter.Address))
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 999)), app.BankKeeper.GetAllBalances(app.Context(), grantee.Address))
+
+	grant, err := app.FeeGrantKeeper.GetFeeAllowance(app.Context(), granter.Address, grantee.Address)
+	require.NoError(t, err)
+	require.Equal(t, &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("stake", 15))}, grant)
+
+	// a fee the allowance does not cover is still rejected by the ante handler
+	_, err = app.DeliverGrantedFeeTx(t, granter.Address, grantee, sdk.NewCoins(sdk.NewInt64Coin("stake", 20)), send)
+	require.True(t, errors.Is(err, types.ErrFeeLimitExceeded))
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 991)), app.BankKeeper.GetAllBalances(app.Context(), granter.Address))
+}
@@ -0,0 +1,307 @@
+package cli_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/suite"
+	tmcli "github.com/tendermint/tendermint/libs/cli"
+
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	clitestutil "github.com/cosmos/cosmos-sdk/testutil/cli"
+	"github.com/cosmos/cosmos-sdk/testutil/network"
+	"github.com/cosmos/cosmos-sdk/testutil/testdata"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/client/cli"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+type IntegrationTestSuite struct {
+	suite.Suite
+
+	cfg     network.Config
+	network *network.Network
+	grantee sdk.AccAddress
+}
+
+func (s *IntegrationTestSuite) SetupSuite() {
+	s.T().Log("setting up integration test suite")
+
+	cfg := network.DefaultConfig()
+	cfg.NumValidators = 1
+
+	s.cfg = cfg
+	s.network = network.New(s.T(), cfg)
+
+	_, err := s.network.WaitForHeight(1)
+	s.Require().NoError(err)
+
+	val := s.network.Validators[0]
+	_, _, s.grantee = testdata.KeyTestPubAddr()
+
+	args := []string{
+		val.Address.String(),
+		s.grantee.String(),
+		fmt.Sprintf("--%s=100%s", cli.FlagSpendLimit, s.cfg.BondDenom),
+		fmt.Sprintf("--%s=2100-01-01T00:00:00Z", cli.FlagExpiration),
+		fmt.Sprintf("--%s=true", flags.FlagSkipConfirmation),
+		fmt.Sprintf("--%s=%s", flags.FlagBroadcastMode, flags.BroadcastBlock),
+		fmt.Sprintf("--%s=%s", flags.FlagFees, sdk.NewCoins(sdk.NewCoin(s.cfg.BondDenom, sdk.NewInt(10))).String()),
+	}
+
+	_, err = clitestutil.ExecTestCLICmd(val.ClientCtx, cli.NewCmdFeeGrant(), args)
+	s.Require().NoError(err)
+	_, err = s.network.WaitForHeight(3)
+	s.Require().NoError(err)
+}
+
+func (s *IntegrationTestSuite) TearDownSuite() {
+	s.T().Log("tearing down integration test suite")
+	s.network.Cleanup()
+}
+
+func (s *IntegrationTestSuite) TestCmdGetFeeGrant() {
+	val := s.network.Validators[0]
+	granter := val.Address
+	grantee := s.grantee
+
+	testCases := []struct {
+		name         string
+		args         []string
+		expectErrMsg string
+		expectErr    bool
+	}{
+		{
+			"wrong granter",
+			[]string{"wrong_granter", grantee.String()},
+			"decoding bech32 failed",
+			true,
+		},
+		{
+			"non existed grant",
+			[]string{grantee.String(), granter.String()},
+			fmt.Sprintf("no allowance found for granter %s and grantee %s", grantee, granter),
+			true,
+		},
+		{
+			"valid req",
+			[]string{granter.String(), grantee.String()},
+			"",
+			false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		s.Run(tc.name, func() {
+			args := append(tc.args, fmt.Sprintf("--%s=json", tmcli.OutputFlag))
+			out, err := clitestutil.ExecTestCLICmd(val.ClientCtx, cli.GetCmdQueryFeeGrant(), args)
+
+			if tc.expectErr {
+				s.Require().Error(err)
+				s.Require().Contains(err.Error(), tc.expectErrMsg)
+			} else {
+				s.Require().NoError(err)
+
+				var grant types.FeeAllowanceGrant
+				s.Require().NoError(val.ClientCtx.JSONMarshaler.UnmarshalJSON(out.Bytes(), &grant))
+				s.Require().Equal(granter.String(), grant.Granter)
+				s.Require().Equal(grantee.String(), grant.Grantee)
+
+				allowance, err := grant.GetFeeGrant()
+				s.Require().NoError(err)
+				basic, ok := allowance.(*types.BasicAllowance)
+				s.Require().True(ok)
+				s.Require().Equal(sdk.NewCoins(sdk.NewInt64Coin(s.cfg.BondDenom, 100)), basic.SpendLimit)
+				s.Require().NotNil(basic.Expiration)
+			}
+		})
+	}
+}
+
+func (s *IntegrationTestSuite) TestCmdGetFeeGrants() {
+	val := s.network.Validators[0]
+	grantee := s.grantee
+
+	testCases := []struct {
+		name      string
+		args      []string
+		expectErr bool
+		expLen    int
+	}{
+		{
+			"wrong grantee",
+			[]string{"wrong_grantee"},
+			true,
+			0,
+		},
+		{
+			"non existed grantee",
+			[]string{val.Address.String()},
+			false,
+			0,
+		},
+		{
+			"valid req",
+			[]string{grantee.String()},
+			false,
+			1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		s.Run(tc.name, func() {
+			args := append(tc.args, fmt.Sprintf("--%s=json", tmcli.OutputFlag))
+			out, err := clitestutil.ExecTestCLICmd(val.ClientCtx, cli.GetCmdQueryFeeGrants(), args)
+
+			if tc.expectErr {
+				s.Require().Error(err)
+			} else {
+				s.Require().NoError(err)
+
+				var resp types.QueryAllowancesResponse
+				s.Require().NoError(val.ClientCtx.JSONMarshaler.UnmarshalJSON(out.Bytes(), &resp))
+				s.Require().Len(resp.Allowances, tc.expLen)
+			}
+		})
+	}
+}
+
+func (s *IntegrationTestSuite) TestCmdQueryParams() {
+	val := s.network.Validators[0]
+
+	out, err := clitestutil.ExecTestCLICmd(val.ClientCtx, cli.GetCmdQueryParams(), []string{fmt.Sprintf("--%s=json", tmcli.OutputFlag)})
+	s.Require().NoError(err)
+
+	var params types.Params
+	s.Require().NoError(val.ClientCtx.JSONMarshaler.UnmarshalJSON(out.Bytes(), &params))
+	s.Require().Equal(types.DefaultParams(), params)
+}
+
+func (s *IntegrationTestSuite) TestNewCmdRevokeFeegrant() {
+	val := s.network.Validators[0]
+	granter := val.Address
+	_, _, grantee := testdata.KeyTestPubAddr()
+
+	txFlags := []string{
+		fmt.Sprintf("--%s=true", flags.FlagSkipConfirmation),
+		fmt.Sprintf("--%s=%s", flags.FlagBroadcastMode, flags.BroadcastBlock),
+		fmt.Sprintf("--%s=%s", flags.FlagFees, sdk.NewCoins(sdk.NewCoin(s.cfg.BondDenom, sdk.NewInt(10))).String()),
+	}
+
+	// a malformed grantee is rejected before anything is broadcast
+	_, err := clitestutil.ExecTestCLICmd(val.ClientCtx, cli.NewCmdRevokeFeegrant(), append([]string{granter.String(), "wrong_grantee"}, txFlags...))
+	s.Require().Error(err)
+	s.Require().Contains(err.Error(), "invalid grantee address wrong_grantee")
+
+	exec := func(cmd *cobra.Command, args []string) {
+		out, err := clitestutil.ExecTestCLICmd(val.ClientCtx, cmd, append(args, txFlags...))
+		s.Require().NoError(err)
+
+		var res sdk.TxResponse
+		s.Require().NoError(val.ClientCtx.JSONMarshaler.UnmarshalJSON(out.Bytes(), &res))
+		s.Require().Equal(uint32(0), res.Code, res.RawLog)
+		s.Require().NoError(s.network.WaitForNextBlock())
+	}
+
+	exec(cli.NewCmdFeeGrant(), []string{granter.String(), grantee.String(), fmt.Sprintf("--%s=100%s", cli.FlagSpendLimit, s.cfg.BondDenom)})
+
+	query := []string{granter.String(), grantee.String(), fmt.Sprintf("--%s=json", tmcli.OutputFlag)}
+	_, err = clitestutil.ExecTestCLICmd(val.ClientCtx, cli.GetCmdQueryFeeGrant(), query)
+	s.Require().NoError(err)
+
+	exec(cli.NewCmdRevokeFeegrant(), []string{granter.String(), grantee.String()})
+
+	_, err = clitestutil.ExecTestCLICmd(val.ClientCtx, cli.GetCmdQueryFeeGrant(), query)
+	s.Require().Error(err)
+	s.Require().Contains(err.Error(), fmt.Sprintf("no allowance found for granter %s and grantee %s", granter, grantee))
+}
+
+func (s *IntegrationTestSuite) TestNewCmdFeeGrantAllowanceFile() {
+	val := s.network.Validators[0]
+	granter := val.Address
+	_, _, grantee := testdata.KeyTestPubAddr()
+
+	allowanceFile := filepath.Join("testdata", "nested_allowance.json")
+	txFlags := []string{
+		fmt.Sprintf("--%s=true", flags.FlagSkipConfirmation),
+		fmt.Sprintf("--%s=%s", flags.FlagBroadcastMode, flags.BroadcastBlock),
+		fmt.Sprintf("--%s=%s", flags.FlagFees, sdk.NewCoins(sdk.NewCoin(s.cfg.BondDenom, sdk.NewInt(10))).String()),
+	}
+
+	// the allowance flags cannot be mixed with an allowance file
+	args := []string{
+		granter.String(), grantee.String(),
+		fmt.Sprintf("--%s=%s", cli.FlagAllowanceFile, allowanceFile),
+		fmt.Sprintf("--%s=100%s", cli.FlagSpendLimit, s.cfg.BondDenom),
+	}
+	_, err := clitestutil.ExecTestCLICmd(val.ClientCtx, cli.NewCmdFeeGrant(), append(args, txFlags...))
+	s.Require().Error(err)
+	s.Require().Contains(err.Error(), fmt.Sprintf("--%s cannot be used with --%s", cli.FlagSpendLimit, cli.FlagAllowanceFile))
+
+	args = []string{granter.String(), grantee.String(), fmt.Sprintf("--%s=%s", cli.FlagAllowanceFile, allowanceFile)}
+	out, err := clitestutil.ExecTestCLICmd(val.ClientCtx, cli.NewCmdFeeGrant(), append(args, txFlags...))
+	s.Require().NoError(err)
+
+	var res sdk.TxResponse
+	s.Require().NoError(val.ClientCtx.JSONMarshaler.UnmarshalJSON(out.Bytes(), &res))
+	s.Require().Equal(uint32(0), res.Code, res.RawLog)
+
+	query := []string{granter.String(), grantee.String(), fmt.Sprintf("--%s=json", tmcli.OutputFlag)}
+	out, err = clitestutil.ExecTestCLICmd(val.ClientCtx, cli.GetCmdQueryFeeGrant(), query)
+	s.Require().NoError(err)
+
+	var grant types.FeeAllowanceGrant
+	s.Require().NoError(val.ClientCtx.JSONMarshaler.UnmarshalJSON(out.Bytes(), &grant))
+
+	expected, err := cli.ParseAllowanceFile(val.ClientCtx.JSONMarshaler, allowanceFile)
+	s.Require().NoError(err)
+	allowance, err := grant.GetFeeGrant()
+	s.Require().NoError(err)
+	s.Require().Equal(expected, allowance)
+}
+
+func (s *IntegrationTestSuite) TestNewCmdFeeGrantIBCDenom() {
+	val := s.network.Validators[0]
+	_, _, grantee := testdata.KeyTestPubAddr()
+
+	ibcDenom := "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2"
+	args := []string{
+		val.Address.String(),
+		grantee.String(),
+		fmt.Sprintf("--%s=100%s,5%s", cli.FlagSpendLimit, ibcDenom, s.cfg.BondDenom),
+		fmt.Sprintf("--%s=3600", cli.FlagPeriod),
+		fmt.Sprintf("--%s=10%s", cli.FlagPeriodLimit, ibcDenom),
+		fmt.Sprintf("--%s=true", flags.FlagSkipConfirmation),
+		fmt.Sprintf("--%s=%s", flags.FlagBroadcastMode, flags.BroadcastBlock),
+		fmt.Sprintf("--%s=%s", flags.FlagFees, sdk.NewCoins(sdk.NewCoin(s.cfg.BondDenom, sdk.NewInt(10))).String()),
+	}
+	out, err := clitestutil.ExecTestCLICmd(val.ClientCtx, cli.NewCmdFeeGrant(), args)
+	s.Require().NoError(err)
+
+	var res sdk.TxResponse
+	s.Require().NoError(val.ClientCtx.JSONMarshaler.UnmarshalJSON(out.Bytes(), &res))
+	s.Require().Equal(uint32(0), res.Code, res.RawLog)
+
+	query := []string{val.Address.String(), grantee.String(), fmt.Sprintf("--%s=json", tmcli.OutputFlag)}
+	out, err = clitestutil.ExecTestCLICmd(val.ClientCtx, cli.GetCmdQueryFeeGrant(), query)
+	s.Require().NoError(err)
+
+	var grant types.FeeAllowanceGrant
+	s.Require().NoError(val.ClientCtx.JSONMarshaler.UnmarshalJSON(out.Bytes(), &grant))
+	allowance, err := grant.GetFeeGrant()
+	s.Require().NoError(err)
+	periodic, ok := allowance.(*types.PeriodicFeeAllowance)
+	s.Require().True(ok, "unexpected allowance %T", allowance)
+	s.Require().Equal(sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 100), sdk.NewInt64Coin(s.cfg.BondDenom, 5)), periodic.Basic.SpendLimit)
+	s.Require().Equal(sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 10)), periodic.PeriodSpendLimit)
+}
+
+func TestIntegrationTestSuite(t *testing.T) {
+	suite.Run(t, new(IntegrationTestSuite))
+}
@@ -0,0 +1,330 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/version"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+// flag for feegrant module
+const (
+	FlagExpiration       = "expiration"
+	FlagExpirationHeight = "expiration-height"
+	FlagPeriod           = "period"
+	FlagPeriodLimit      = "period-limit"
+	FlagCarryoverLimit   = "carryover-limit"
+	FlagPeriodAnchor     = "period-anchor"
+	FlagSpendLimit       = "spend-limit"
+	FlagAllowedMsgs      = "allowed-messages"
+	FlagUpdate           = "update"
+	FlagAllowanceFile    = "allowance-file"
+)
+
+// allowanceFlags are the flags building the allowance of a grant, which cannot
+// be used along with FlagAllowanceFile.
+var allowanceFlags = []string{
+	FlagSpendLimit, FlagExpiration, FlagExpirationHeight, FlagPeriod, FlagPeriodLimit,
+	FlagCarryoverLimit, FlagPeriodAnchor, FlagAllowedMsgs,
+}
+
+// GetTxCmd returns the transaction commands for this module
+func GetTxCmd() *cobra.Command {
+	feegrantTxCmd := &cobra.Command{
+		Use:                        types.ModuleName,
+		Short:                      "Feegrant transactions subcommands",
+		Long:                       "Grant and revoke fee allowance for a grantee by a granter",
+		DisableFlagParsing:         true,
+		SuggestionsMinimumDistance: 2,
+		RunE:                       client.ValidateCmd,
+	}
+
+	feegrantTxCmd.AddCommand(
+		NewCmdFeeGrant(),
+		NewCmdRevokeFeegrant(),
+	)
+
+	return feegrantTxCmd
+}
+
+// NewCmdFeeGrant returns a CLI command handler for creating a MsgGrantFeeAllowance transaction.
+func NewCmdFeeGrant() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "grant [granter] [grantee]",
+		Short: "Grant Fee allowance to an address",
+		Long: strings.TrimSpace(
+			fmt.Sprintf(
+				`Grant authorization to pay fees from your address. Note, the'--from' flag is
+				ignored as it is implied from [granter].
+
+Examples:
+%s tx %s grant cosmos1skjw... cosmos1skjw... --spend-limit 100stake --expiration 2022-01-30T15:04:05Z or
+%s tx %s grant cosmos1skjw... cosmos1skjw... --spend-limit 100stake --period 3600 --period-limit 10stake --expiration 2022-01-30T15:04:05Z
+
+An existing grant is only replaced when --update is set:
+%s tx %s grant cosmos1skjw... cosmos1skjw... --spend-limit 200stake --update
+
+The allowance can instead be read from a JSON file, in which nested allowances
+are identified by their "@type":
+%s tx %s grant cosmos1skjw... cosmos1skjw... --allowance-file allowance.json
+
+Where allowance.json contains:
+{
+  "@type": "/cosmos.feegrant.v1beta1.AllowedMsgAllowance",
+  "allowance": {
+    "@type": "/cosmos.feegrant.v1beta1.BasicAllowance",
+    "spend_limit": [{"denom": "stake", "amount": "100"}]
+  },
+  "allowed_messages": ["/cosmos.gov.v1beta1.MsgVote"]
+}
+				`, version.AppName, types.ModuleName, version.AppName, types.ModuleName, version.AppName, types.ModuleName,
+				version.AppName, types.ModuleName,
+			),
+		),
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.Flags().Set(flags.FlagFrom, args[0])
+			clientCtx, err := client.GetClientTxContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			grantee, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			granter := clientCtx.GetFromAddress()
+
+			var grant types.FeeAllowanceI
+			allowanceFile, err := cmd.Flags().GetString(FlagAllowanceFile)
+			if err != nil {
+				return err
+			}
+
+			if allowanceFile != "" {
+				for _, flag := range allowanceFlags {
+					if cmd.Flags().Changed(flag) {
+						return fmt.Errorf("--%s cannot be used with --%s", flag, FlagAllowanceFile)
+					}
+				}
+
+				grant, err = ParseAllowanceFile(clientCtx.JSONMarshaler, allowanceFile)
+			} else {
+				grant, err = allowanceFromFlags(cmd)
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := types.ValidateNewAllowance(grant); err != nil {
+				return err
+			}
+
+			update, err := cmd.Flags().GetBool(FlagUpdate)
+			if err != nil {
+				return err
+			}
+
+			var msg sdk.Msg
+			if update {
+				msg, err = types.NewMsgUpdateAllowance(grant, granter, grantee)
+			} else {
+				msg, err = types.NewMsgGrantFeeAllowance(grant, granter, grantee)
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return tx.GenerateOrBroadcastTxCLI(clientCtx, cmd.Flags(), msg)
+		},
+	}
+
+	flags.AddTxFlagsToCmd(cmd)
+	cmd.Flags().String(FlagSpendLimit, "", "Spend limit specifies the max limit can be used, if not mentioned there is no limit")
+	cmd.Flags().String(FlagExpiration, "", "The RFC 3339 timestamp after which the grant expires for the user")
+	cmd.Flags().Int64(FlagExpirationHeight, 0, "The block height after which the grant expires for the user, cannot be used with --expiration")
+	cmd.Flags().Int64(FlagPeriod, 0, "period specifies the time duration in seconds in which period_spend_limit coins can be spent before that allowance is reset")
+	cmd.Flags().String(FlagPeriodLimit, "", "period limit specifies the maximum number of coins that can be spent in the period")
+	cmd.Flags().String(FlagCarryoverLimit, "", "carryover limit enables carrying over the coins left unspent in a period to the next ones, up to this maximum")
+	cmd.Flags().String(FlagPeriodAnchor, "", "The RFC 3339 timestamp of the first period reset, the next resets staying aligned on it, e.g. 2022-01-01T00:00:00Z for resets at midnight UTC")
+	cmd.Flags().StringSlice(FlagAllowedMsgs, []string{}, "Set of allowed messages for fee allowance, e.g. /cosmos.gov.v1beta1.MsgVote")
+	cmd.Flags().Bool(FlagUpdate, false, "Replace the allowance of an existing grant instead of creating a new grant")
+	cmd.Flags().String(FlagAllowanceFile, "", "Read the allowance from a JSON file instead of building it from the other allowance flags")
+
+	return cmd
+}
+
+// allowanceFromFlags builds the allowance granted by NewCmdFeeGrant from the
+// allowance flags.
+func allowanceFromFlags(cmd *cobra.Command) (types.FeeAllowanceI, error) {
+	sl, err := cmd.Flags().GetString(FlagSpendLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	// if `FlagSpendLimit` isn't set, limit will be nil
+	limit, err := sdk.ParseCoinsNormalized(sl)
+	if err != nil {
+		return nil, err
+	}
+
+	basic := types.BasicAllowance{
+		SpendLimit: limit,
+	}
+
+	exp, err := cmd.Flags().GetString(FlagExpiration)
+	if err != nil {
+		return nil, err
+	}
+	if exp != "" {
+		expTime, err := time.Parse(time.RFC3339, exp)
+		if err != nil {
+			return nil, err
+		}
+		basic.Expiration = &expTime
+	}
+
+	basic.ExpirationHeight, err = cmd.Flags().GetInt64(FlagExpirationHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	var grant types.FeeAllowanceI
+	grant = &basic
+
+	periodClock, err := cmd.Flags().GetInt64(FlagPeriod)
+	if err != nil {
+		return nil, err
+	}
+
+	periodLimitVal, err := cmd.Flags().GetString(FlagPeriodLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	carryoverLimitVal, err := cmd.Flags().GetString(FlagCarryoverLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	periodAnchorVal, err := cmd.Flags().GetString(FlagPeriodAnchor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check any of period or periodLimit flags set, If set consider it as periodic fee allowance.
+	if periodClock > 0 || periodLimitVal != "" {
+		periodLimit, err := sdk.ParseCoinsNormalized(periodLimitVal)
+		if err != nil {
+			return nil, err
+		}
+
+		if periodClock <= 0 || periodLimit.Empty() {
+			return nil, fmt.Errorf("period (%d) and period limit (%s) must both be set and positive", periodClock, periodLimit)
+		}
+
+		period := time.Duration(periodClock) * time.Second
+		grant = &types.PeriodicFeeAllowance{
+			Basic:            basic,
+			Period:           period,
+			PeriodReset:      time.Now().Add(period),
+			PeriodSpendLimit: periodLimit,
+			PeriodCanSpend:   periodLimit,
+		}
+
+		// a carryover limit enables carrying over unspent coins
+		if carryoverLimitVal != "" {
+			carryoverLimit, err := sdk.ParseCoinsNormalized(carryoverLimitVal)
+			if err != nil {
+				return nil, err
+			}
+
+			periodic := grant.(*types.PeriodicFeeAllowance)
+			periodic.Carryover = true
+			periodic.PeriodCarryoverLimit = carryoverLimit
+		}
+
+		// an anchor sets the first reset, the next ones being aligned on it
+		if periodAnchorVal != "" {
+			anchor, err := time.Parse(time.RFC3339, periodAnchorVal)
+			if err != nil {
+				return nil, err
+			}
+
+			periodic := grant.(*types.PeriodicFeeAllowance)
+			periodic.Aligned = true
+			periodic.PeriodReset = anchor
+		}
+	} else if carryoverLimitVal != "" {
+		return nil, fmt.Errorf("carryover limit requires period and period limit to be set")
+	} else if periodAnchorVal != "" {
+		return nil, fmt.Errorf("period anchor requires period and period limit to be set")
+	}
+
+	allowedMsgs, err := cmd.Flags().GetStringSlice(FlagAllowedMsgs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(allowedMsgs) > 0 {
+		grant, err = types.NewAllowedMsgAllowance(grant, allowedMsgs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return grant, nil
+}
+
+// NewCmdRevokeFeegrant returns a CLI command handler for creating a MsgRevokeFeeAllowance transaction.
+func NewCmdRevokeFeegrant() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revoke [granter] [grantee]",
+		Short: "Revoke a fee grant",
+		Long: strings.TrimSpace(
+			fmt.Sprintf(`Revoke the fee grant from a granter to a grantee. Note, the '--from' flag is
+ignored as it is implied from [granter].
+
+Example:
+%s tx %s revoke cosmos1skjw... cosmos1skjw...
+`, version.AppName, types.ModuleName),
+		),
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.Flags().Set(flags.FlagFrom, args[0])
+			clientCtx, err := client.GetClientTxContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			grantee, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid grantee address %s: %w", args[1], err)
+			}
+
+			msg := types.NewMsgRevokeFeeAllowance(clientCtx.GetFromAddress(), grantee)
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return tx.GenerateOrBroadcastTxCLI(clientCtx, cmd.Flags(), &msg)
+		},
+	}
+
+	flags.AddTxFlagsToCmd(cmd)
+
+	return cmd
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+// ParseAllowanceJSON decodes an allowance from its JSON form, where the
+// allowance and the allowances it wraps are identified by their "@type", e.g.
+//
+//	{
+//	  "@type": "/cosmos.feegrant.v1beta1.AllowedMsgAllowance",
+//	  "allowance": {
+//	    "@type": "/cosmos.feegrant.v1beta1.BasicAllowance",
+//	    "spend_limit": [{"denom": "stake", "amount": "100"}]
+//	  },
+//	  "allowed_messages": ["/cosmos.gov.v1beta1.MsgVote"]
+//	}
+//
+// The type URLs are resolved by the interface registry of cdc.
+func ParseAllowanceJSON(cdc codec.JSONMarshaler, bz []byte) (types.FeeAllowanceI, error) {
+	var allowance types.FeeAllowanceI
+	if err := cdc.UnmarshalInterfaceJSON(bz, &allowance); err != nil {
+		return nil, err
+	}
+
+	return allowance, nil
+}
+
+// ParseAllowanceFile reads and decodes an allowance from a JSON file, see
+// ParseAllowanceJSON.
+func ParseAllowanceFile(cdc codec.JSONMarshaler, allowanceFile string) (types.FeeAllowanceI, error) {
+	contents, err := ioutil.ReadFile(allowanceFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseAllowanceJSON(cdc, contents)
+}
+
+// MarshalAllowanceJSON encodes allowance to the JSON form read by
+// ParseAllowanceJSON.
+func MarshalAllowanceJSON(cdc codec.JSONMarshaler, allowance types.FeeAllowanceI) ([]byte, error) {
+	msg, ok := allowance.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("cannot proto marshal %T", allowance)
+	}
+
+	return cdc.MarshalInterfaceJSON(msg)
+}
@@ -0,0 +1,95 @@
+package cli_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/client/cli"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+func TestParseAllowanceFile(t *testing.T) {
+	cdc := simapp.MakeTestEncodingConfig().Marshaler
+
+	allowance, err := cli.ParseAllowanceFile(cdc, filepath.Join("testdata", "nested_allowance.json"))
+	require.NoError(t, err)
+	require.NoError(t, types.ValidateNewAllowance(allowance))
+
+	allowed, ok := allowance.(*types.AllowedMsgAllowance)
+	require.True(t, ok, "unexpected allowance %T", allowance)
+	require.Equal(t, []string{"/cosmos.gov.v1beta1.MsgVote", "/cosmos.bank.v1beta1.MsgSend"}, allowed.AllowedMessages)
+
+	inner, err := allowed.GetAllowance()
+	require.NoError(t, err)
+	periodic, ok := inner.(*types.PeriodicFeeAllowance)
+	require.True(t, ok, "unexpected inner allowance %T", inner)
+
+	expiration := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)), periodic.Basic.SpendLimit)
+	require.Equal(t, &expiration, periodic.Basic.Expiration)
+	require.Equal(t, time.Hour, periodic.Period)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 100)), periodic.PeriodSpendLimit)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 100)), periodic.PeriodCanSpend)
+	require.Equal(t, time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC), periodic.PeriodReset)
+
+	// the JSON form of the decoded allowance decodes to the same allowance
+	bz, err := cli.MarshalAllowanceJSON(cdc, allowance)
+	require.NoError(t, err)
+	decoded, err := cli.ParseAllowanceJSON(cdc, bz)
+	require.NoError(t, err)
+	require.Equal(t, allowance, decoded)
+}
+
+func TestParseAllowanceJSONInvalid(t *testing.T) {
+	cdc := simapp.MakeTestEncodingConfig().Marshaler
+
+	cases := map[string]string{
+		"malformed":    `{"@type": `,
+		"missing type": `{"spend_limit": [{"denom": "stake", "amount": "100"}]}`,
+		"unknown type": `{"@type": "/cosmos.feegrant.v1beta1.UnknownAllowance"}`,
+		"not an allowance": `{
+			"@type": "/cosmos.bank.v1beta1.MsgSend",
+			"from_address": "", "to_address": "", "amount": []
+		}`,
+		"unknown nested type": `{
+			"@type": "/cosmos.feegrant.v1beta1.AllowedMsgAllowance",
+			"allowance": {"@type": "/cosmos.feegrant.v1beta1.UnknownAllowance"},
+			"allowed_messages": ["/cosmos.gov.v1beta1.MsgVote"]
+		}`,
+	}
+
+	for name, bz := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := cli.ParseAllowanceJSON(cdc, []byte(bz))
+			require.Error(t, err)
+		})
+	}
+
+	_, err := cli.ParseAllowanceFile(cdc, filepath.Join("testdata", "missing.json"))
+	require.Error(t, err)
+}
+
+func TestParseAllowanceFileInvalidAllowance(t *testing.T) {
+	cdc := simapp.MakeTestEncodingConfig().Marshaler
+
+	// a periodic limit above the basic spend limit decodes, but cannot be granted
+	file := filepath.Join(t.TempDir(), "allowance.json")
+	require.NoError(t, ioutil.WriteFile(file, []byte(`{
+		"@type": "/cosmos.feegrant.v1beta1.PeriodicFeeAllowance",
+		"basic": {"spend_limit": [{"denom": "stake", "amount": "10"}]},
+		"period": "3600s",
+		"period_spend_limit": [{"denom": "stake", "amount": "100"}],
+		"period_can_spend": [{"denom": "stake", "amount": "100"}],
+		"period_reset": "2099-01-01T00:00:00Z"
+	}`), 0600))
+
+	allowance, err := cli.ParseAllowanceFile(cdc, file)
+	require.NoError(t, err)
+	require.Error(t, types.ValidateNewAllowance(allowance))
+}
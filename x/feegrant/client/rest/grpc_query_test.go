@@ -0,0 +1,155 @@
+// +build norace
+
+package rest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/cosmos/cosmos-sdk/testutil/network"
+	"github.com/cosmos/cosmos-sdk/testutil/testdata"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/rest"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+type IntegrationTestSuite struct {
+	suite.Suite
+	cfg       network.Config
+	network   *network.Network
+	granter   sdk.AccAddress
+	grantee   sdk.AccAddress
+	allowance *types.BasicAllowance
+}
+
+func (s *IntegrationTestSuite) SetupSuite() {
+	s.T().Log("setting up integration test suite")
+
+	cfg := network.DefaultConfig()
+	cfg.NumValidators = 1
+
+	_, _, s.granter = testdata.KeyTestPubAddr()
+	_, _, s.grantee = testdata.KeyTestPubAddr()
+	s.allowance = &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin(cfg.BondDenom, 100))}
+
+	grant, err := types.NewFeeAllowanceGrant(s.granter, s.grantee, s.allowance)
+	s.Require().NoError(err)
+
+	genesisState := cfg.GenesisState
+	feegrantDataBz, err := cfg.Codec.MarshalJSON(types.NewGenesisState(types.DefaultParams(), []types.FeeAllowanceGrant{grant}, nil))
+	s.Require().NoError(err)
+	genesisState[types.ModuleName] = feegrantDataBz
+	cfg.GenesisState = genesisState
+
+	s.cfg = cfg
+	s.network = network.New(s.T(), cfg)
+
+	_, err = s.network.WaitForHeight(1)
+	s.Require().NoError(err)
+}
+
+func (s *IntegrationTestSuite) TearDownSuite() {
+	s.T().Log("tearing down integration test suite")
+	s.network.Cleanup()
+}
+
+func (s *IntegrationTestSuite) TestQueryAllowanceGRPC() {
+	val := s.network.Validators[0]
+	baseURL := val.APIAddress
+
+	testCases := []struct {
+		name   string
+		url    string
+		expErr bool
+	}{
+		{
+			"invalid granter",
+			fmt.Sprintf("%s/cosmos/feegrant/v1beta1/allowance/%s/%s", baseURL, "invalid_granter", s.grantee),
+			true,
+		},
+		{
+			"no grant",
+			fmt.Sprintf("%s/cosmos/feegrant/v1beta1/allowance/%s/%s", baseURL, s.grantee, s.granter),
+			true,
+		},
+		{
+			"valid request",
+			fmt.Sprintf("%s/cosmos/feegrant/v1beta1/allowance/%s/%s", baseURL, s.granter, s.grantee),
+			false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		s.Run(tc.name, func() {
+			resp, err := rest.GetRequest(tc.url)
+			s.Require().NoError(err)
+
+			var res types.QueryAllowanceResponse
+			err = val.ClientCtx.JSONMarshaler.UnmarshalJSON(resp, &res)
+			if tc.expErr {
+				s.Require().Error(err)
+			} else {
+				s.Require().NoError(err)
+				s.Require().Equal(s.granter.String(), res.Allowance.Granter)
+				s.Require().Equal(s.grantee.String(), res.Allowance.Grantee)
+
+				allowance, err := res.Allowance.GetFeeGrant()
+				s.Require().NoError(err)
+				s.Require().Equal(s.allowance, allowance)
+			}
+		})
+	}
+}
+
+func (s *IntegrationTestSuite) TestQueryAllowancesGRPC() {
+	val := s.network.Validators[0]
+
+	resp, err := rest.GetRequest(fmt.Sprintf("%s/cosmos/feegrant/v1beta1/allowances/%s", val.APIAddress, s.grantee))
+	s.Require().NoError(err)
+
+	var res types.QueryAllowancesResponse
+	s.Require().NoError(val.ClientCtx.JSONMarshaler.UnmarshalJSON(resp, &res))
+	s.Require().Len(res.Allowances, 1)
+	s.Require().Equal(s.granter.String(), res.Allowances[0].Granter)
+}
+
+func (s *IntegrationTestSuite) TestQueryAllowancesByGranterGRPC() {
+	val := s.network.Validators[0]
+
+	resp, err := rest.GetRequest(fmt.Sprintf("%s/cosmos/feegrant/v1beta1/issued/%s", val.APIAddress, s.granter))
+	s.Require().NoError(err)
+
+	var res types.QueryAllowancesByGranterResponse
+	s.Require().NoError(val.ClientCtx.JSONMarshaler.UnmarshalJSON(resp, &res))
+	s.Require().Len(res.Allowances, 1)
+	s.Require().Equal(s.grantee.String(), res.Allowances[0].Grantee)
+}
+
+func (s *IntegrationTestSuite) TestQueryAllowanceRemainingGRPC() {
+	val := s.network.Validators[0]
+
+	resp, err := rest.GetRequest(fmt.Sprintf("%s/cosmos/feegrant/v1beta1/allowance/%s/%s/remaining", val.APIAddress, s.granter, s.grantee))
+	s.Require().NoError(err)
+
+	var res types.QueryAllowanceRemainingResponse
+	s.Require().NoError(val.ClientCtx.JSONMarshaler.UnmarshalJSON(resp, &res))
+	s.Require().Equal(s.allowance.SpendLimit, res.Remaining)
+}
+
+func (s *IntegrationTestSuite) TestQueryGrantsCountGRPC() {
+	val := s.network.Validators[0]
+
+	resp, err := rest.GetRequest(fmt.Sprintf("%s/cosmos/feegrant/v1beta1/grants_count", val.APIAddress))
+	s.Require().NoError(err)
+
+	var res types.QueryGrantsCountResponse
+	s.Require().NoError(val.ClientCtx.JSONMarshaler.UnmarshalJSON(resp, &res))
+	s.Require().Equal(uint64(1), res.Count)
+}
+
+func TestIntegrationTestSuite(t *testing.T) {
+	suite.Run(t, new(IntegrationTestSuite))
+}
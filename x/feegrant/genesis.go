@@ -0,0 +1,121 @@
+package feegrant
+
+import (
+	"bytes"
+	"sort"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/keeper"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+// InitGenesis will initialize the keeper from a *previously validated* GenesisState.
+// The spending records are restored once all the grants are made, so that they
+// replace the records started by the grants.
+func InitGenesis(ctx sdk.Context, k keeper.Keeper, data *types.GenesisState) error {
+	k.SetParams(ctx, data.Params)
+
+	for _, f := range data.FeeAllowances {
+		granter, err := sdk.AccAddressFromBech32(f.Granter)
+		if err != nil {
+			return err
+		}
+		grantee, err := sdk.AccAddressFromBech32(f.Grantee)
+		if err != nil {
+			return err
+		}
+
+		grant, err := f.GetFeeGrant()
+		if err != nil {
+			return err
+		}
+
+		if err := k.GrantFeeAllowance(ctx, granter, grantee, grant); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range data.AllowanceSpendings {
+		granter, err := sdk.AccAddressFromBech32(r.Granter)
+		if err != nil {
+			return err
+		}
+		grantee, err := sdk.AccAddressFromBech32(r.Grantee)
+		if err != nil {
+			return err
+		}
+
+		k.SetAllowanceSpending(ctx, granter, grantee, r.Spending)
+	}
+
+	return nil
+}
+
+// ExportGenesis will dump the contents of the keeper into a serializable GenesisState.
+// Grants and spending records are sorted by granter and then grantee address
+// bytes, independently of the store's key layout and iteration order, so that
+// the export is byte-stable.
+func ExportGenesis(ctx sdk.Context, k keeper.Keeper) (*types.GenesisState, error) {
+	type sortableGrant struct {
+		key   []byte
+		grant types.FeeAllowanceGrant
+	}
+
+	var (
+		sortable []sortableGrant
+		err      error
+	)
+	iterErr := k.IterateAllFeeAllowances(ctx, func(grant types.FeeAllowanceGrant) bool {
+		var granter, grantee sdk.AccAddress
+		if granter, err = sdk.AccAddressFromBech32(grant.Granter); err != nil {
+			return true
+		}
+		if grantee, err = sdk.AccAddressFromBech32(grant.Grantee); err != nil {
+			return true
+		}
+
+		// the bech32 encoding does not sort like the address bytes
+		sortable = append(sortable, sortableGrant{key: types.FeeAllowanceByGranterKey(granter, grantee), grant: grant})
+		return false
+	})
+	if iterErr != nil {
+		return nil, iterErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(sortable, func(i, j int) bool {
+		return bytes.Compare(sortable[i].key, sortable[j].key) < 0
+	})
+
+	var grants []types.FeeAllowanceGrant
+	for _, s := range sortable {
+		grants = append(grants, s.grant)
+	}
+
+	type sortableSpending struct {
+		key    []byte
+		record types.AllowanceSpendingRecord
+	}
+
+	var spendings []sortableSpending
+	k.IterateAllAllowanceSpendings(ctx, func(granter, grantee sdk.AccAddress, spending types.AllowanceSpending) bool {
+		spendings = append(spendings, sortableSpending{
+			key:    types.FeeAllowanceByGranterKey(granter, grantee),
+			record: types.NewAllowanceSpendingRecord(granter, grantee, spending),
+		})
+		return false
+	})
+
+	sort.Slice(spendings, func(i, j int) bool {
+		return bytes.Compare(spendings[i].key, spendings[j].key) < 0
+	})
+
+	var records []types.AllowanceSpendingRecord
+	for _, s := range spendings {
+		records = append(records, s.record)
+	}
+
+	return types.NewGenesisState(k.GetParams(ctx), grants, records), nil
+}
@@ -0,0 +1,175 @@
+package feegrant_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/keeper"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+type GenesisTestSuite struct {
+	suite.Suite
+
+	ctx    sdk.Context
+	keeper keeper.Keeper
+}
+
+func (suite *GenesisTestSuite) SetupTest() {
+	checkTx := false
+	app := simapp.Setup(checkTx)
+
+	suite.ctx = app.BaseApp.NewContext(checkTx, tmproto.Header{Height: 1, Time: time.Now()})
+	suite.keeper = app.FeeGrantKeeper
+}
+
+var (
+	granteePub  = secp256k1.GenPrivKey().PubKey()
+	granterPub  = secp256k1.GenPrivKey().PubKey()
+	granteeAddr = sdk.AccAddress(granteePub.Address())
+	granterAddr = sdk.AccAddress(granterPub.Address())
+)
+
+func (suite *GenesisTestSuite) TestImportExportGenesis() {
+	coins := sdk.NewCoins(sdk.NewCoin("foo", sdk.NewInt(1_000)))
+	now := suite.ctx.BlockHeader().Time.UTC()
+	oneYear := now.AddDate(1, 0, 0)
+
+	allowance := &types.BasicAllowance{SpendLimit: coins, Expiration: &oneYear}
+	suite.Require().NoError(suite.keeper.GrantFeeAllowance(suite.ctx, granterAddr, granteeAddr, allowance))
+	suite.Require().NoError(suite.keeper.GrantFeeAllowance(suite.ctx, granteeAddr, granterAddr, &types.BasicAllowance{}))
+
+	genesis, err := feegrant.ExportGenesis(suite.ctx, suite.keeper)
+	suite.Require().NoError(err)
+	suite.Require().Len(genesis.FeeAllowances, 2)
+	suite.Require().NoError(types.ValidateGenesis(*genesis))
+
+	// clear the store and re-import the exported state
+	suite.Require().NoError(suite.keeper.RevokeFeeAllowance(suite.ctx, granterAddr, granteeAddr))
+	suite.Require().NoError(suite.keeper.RevokeFeeAllowance(suite.ctx, granteeAddr, granterAddr))
+
+	suite.Require().NoError(feegrant.InitGenesis(suite.ctx, suite.keeper, genesis))
+
+	newGenesis, err := feegrant.ExportGenesis(suite.ctx, suite.keeper)
+	suite.Require().NoError(err)
+	suite.Require().Equal(genesis, newGenesis)
+
+	grant, err := suite.keeper.GetFeeAllowance(suite.ctx, granterAddr, granteeAddr)
+	suite.Require().NoError(err)
+	suite.Require().Equal(allowance, grant)
+}
+
+func (suite *GenesisTestSuite) TestImportExportAllowanceSpending() {
+	limit := sdk.NewCoins(sdk.NewInt64Coin("foo", 1_000))
+	since := suite.ctx.BlockTime()
+
+	suite.Require().NoError(suite.keeper.GrantFeeAllowance(suite.ctx, granterAddr, granteeAddr, &types.BasicAllowance{SpendLimit: limit}))
+	suite.Require().NoError(suite.keeper.GrantFeeAllowance(suite.ctx, granteeAddr, granterAddr, &types.BasicAllowance{}))
+
+	suite.ctx = suite.ctx.WithBlockTime(since.Add(24 * time.Hour))
+	fee := sdk.NewCoins(sdk.NewInt64Coin("foo", 100))
+	_, _, err := suite.keeper.UseGrantedFees(suite.ctx, granterAddr, granteeAddr, fee, nil)
+	suite.Require().NoError(err)
+
+	spending, found := suite.keeper.GetAllowanceSpending(suite.ctx, granterAddr, granteeAddr)
+	suite.Require().True(found)
+
+	genesis, err := feegrant.ExportGenesis(suite.ctx, suite.keeper)
+	suite.Require().NoError(err)
+	suite.Require().Len(genesis.AllowanceSpendings, 2)
+	suite.Require().NoError(types.ValidateGenesis(*genesis))
+
+	// clear the store and re-import the exported state later on
+	suite.Require().NoError(suite.keeper.RevokeFeeAllowance(suite.ctx, granterAddr, granteeAddr))
+	suite.Require().NoError(suite.keeper.RevokeFeeAllowance(suite.ctx, granteeAddr, granterAddr))
+	suite.ctx = suite.ctx.WithBlockTime(since.Add(48 * time.Hour))
+
+	suite.Require().NoError(feegrant.InitGenesis(suite.ctx, suite.keeper, genesis))
+
+	// the records replace the ones started by the re-granted allowances
+	imported, found := suite.keeper.GetAllowanceSpending(suite.ctx, granterAddr, granteeAddr)
+	suite.Require().True(found)
+	suite.Require().Equal(spending.Spent, imported.Spent)
+	suite.Require().Equal(limit, imported.OriginalLimit)
+	suite.Require().True(since.Equal(imported.Since))
+
+	newGenesis, err := feegrant.ExportGenesis(suite.ctx, suite.keeper)
+	suite.Require().NoError(err)
+	suite.Require().Equal(genesis, newGenesis)
+}
+
+func (suite *GenesisTestSuite) TestExportGenesisSorted() {
+	addrs := []sdk.AccAddress{granteeAddr, granterAddr, sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address())}
+	for _, granter := range addrs {
+		for _, grantee := range addrs {
+			if granter.Equals(grantee) {
+				continue
+			}
+			suite.Require().NoError(suite.keeper.GrantFeeAllowance(suite.ctx, granter, grantee, &types.BasicAllowance{}))
+		}
+	}
+
+	genesis, err := feegrant.ExportGenesis(suite.ctx, suite.keeper)
+	suite.Require().NoError(err)
+	suite.Require().Len(genesis.FeeAllowances, 6)
+
+	addr := func(bech32 string) sdk.AccAddress {
+		addr, err := sdk.AccAddressFromBech32(bech32)
+		suite.Require().NoError(err)
+		return addr
+	}
+	for i := 1; i < len(genesis.FeeAllowances); i++ {
+		prev, cur := genesis.FeeAllowances[i-1], genesis.FeeAllowances[i]
+		order := bytes.Compare(addr(prev.Granter), addr(cur.Granter))
+		if order == 0 {
+			order = bytes.Compare(addr(prev.Grantee), addr(cur.Grantee))
+		}
+		suite.Require().Equal(-1, order)
+	}
+}
+
+func (suite *GenesisTestSuite) TestExportGenesisInsertionOrder() {
+	other := sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address())
+	pairs := [][2]sdk.AccAddress{
+		{granterAddr, granteeAddr},
+		{granterAddr, other},
+		{other, granteeAddr},
+		{granteeAddr, granterAddr},
+	}
+	orders := [][]int{{0, 1, 2, 3}, {3, 2, 1, 0}, {2, 0, 3, 1}}
+
+	// the spending records hold the block time of the grants
+	now := suite.ctx.BlockTime()
+
+	var exported []byte
+	for _, order := range orders {
+		suite.SetupTest()
+		suite.ctx = suite.ctx.WithBlockTime(now)
+		for _, i := range order {
+			suite.Require().NoError(suite.keeper.GrantFeeAllowance(suite.ctx, pairs[i][0], pairs[i][1], &types.BasicAllowance{}))
+		}
+
+		genesis, err := feegrant.ExportGenesis(suite.ctx, suite.keeper)
+		suite.Require().NoError(err)
+		suite.Require().Len(genesis.FeeAllowances, len(pairs))
+
+		bz, err := genesis.Marshal()
+		suite.Require().NoError(err)
+		if exported == nil {
+			exported = bz
+		}
+		suite.Require().Equal(exported, bz, "insertion order %v", order)
+	}
+}
+
+func TestGenesisTestSuite(t *testing.T) {
+	suite.Run(t, new(GenesisTestSuite))
+}
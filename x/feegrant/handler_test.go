@@ -0,0 +1,40 @@
+package feegrant_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+func TestHandlerEmitsEvents(t *testing.T) {
+	app := simapp.Setup(false)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{})
+	handler := feegrant.NewHandler(app.FeeGrantKeeper)
+
+	addrs := simapp.AddTestAddrsIncremental(app, ctx, 2, sdk.NewInt(30000000))
+	granter, grantee := addrs[0], addrs[1]
+	expAttrs := []sdk.Attribute{
+		sdk.NewAttribute(types.AttributeKeyGranter, granter.String()),
+		sdk.NewAttribute(types.AttributeKeyGrantee, grantee.String()),
+	}
+
+	grant, err := types.NewMsgGrantFeeAllowance(&types.BasicAllowance{
+		SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 555)),
+	}, granter, grantee)
+	require.NoError(t, err)
+
+	res, err := handler(ctx, grant)
+	require.NoError(t, err)
+	require.Contains(t, res.GetEvents(), sdk.NewEvent(types.EventTypeSetFeeGrant, expAttrs...))
+
+	revoke := types.NewMsgRevokeFeeAllowance(granter, grantee)
+	res, err = handler(ctx, &revoke)
+	require.NoError(t, err)
+	require.Contains(t, res.GetEvents(), sdk.NewEvent(types.EventTypeRevokeFeeGrant, expAttrs...))
+}
@@ -0,0 +1,90 @@
+package keeper
+
+import (
+	"bytes"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+// allowanceCache keeps the allowances decoded by UseGrantedFees, so that a
+// grant paying the fees of many txs is not decoded again for each of them.
+//
+// An entry is only used while the stored allowance is still the one it was
+// decoded from, which keeps the cache correct when the writes of a tx are
+// discarded. CheckTx and DeliverTx have their own entries, which are dropped
+// whenever the block height changes, and an entry is taken out of the cache
+// while it is used, so that no two callers ever share an allowance.
+type allowanceCache struct {
+	mu        sync.Mutex
+	checkTx   allowanceCacheEntries
+	deliverTx allowanceCacheEntries
+}
+
+type allowanceCacheEntries struct {
+	height  int64
+	entries map[string]cachedAllowance
+}
+
+type cachedAllowance struct {
+	bz        []byte
+	allowance types.FeeAllowanceI
+}
+
+func newAllowanceCache() *allowanceCache {
+	return &allowanceCache{}
+}
+
+// entries returns the entries of the mode of ctx at its height. The caller
+// must hold c.mu.
+func (c *allowanceCache) entries(ctx sdk.Context) map[string]cachedAllowance {
+	e := &c.deliverTx
+	if ctx.IsCheckTx() {
+		e = &c.checkTx
+	}
+
+	if e.entries == nil || e.height != ctx.BlockHeight() {
+		e.height = ctx.BlockHeight()
+		e.entries = make(map[string]cachedAllowance)
+	}
+
+	return e.entries
+}
+
+// take removes the allowance cached for key from the cache and returns it,
+// provided it was decoded from bz.
+func (c *allowanceCache) take(ctx sdk.Context, key, bz []byte) (types.FeeAllowanceI, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := c.entries(ctx)
+	cached, ok := entries[string(key)]
+	if !ok {
+		return nil, false
+	}
+
+	delete(entries, string(key))
+	if !bytes.Equal(cached.bz, bz) {
+		return nil, false
+	}
+
+	return cached.allowance, true
+}
+
+// put caches allowance, encoded as bz, for key. The cache owns allowance
+// from then on.
+func (c *allowanceCache) put(ctx sdk.Context, key, bz []byte, allowance types.FeeAllowanceI) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries(ctx)[string(key)] = cachedAllowance{bz: bz, allowance: allowance}
+}
+
+// remove drops the allowance cached for key, if any.
+func (c *allowanceCache) remove(ctx sdk.Context, key []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries(ctx), string(key))
+}
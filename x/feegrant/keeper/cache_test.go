@@ -0,0 +1,77 @@
+package keeper_test
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+func (suite *KeeperTestSuite) TestAllowanceCache() {
+	ctx := suite.sdkCtx.WithBlockHeight(10)
+	k := suite.app.FeeGrantKeeper
+	granter, grantee := suite.addrs[0], suite.addrs[1]
+	fee := sdk.NewCoins(sdk.NewInt64Coin("atom", 10))
+	limit := func(amount int64) types.FeeAllowanceI {
+		return &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", amount))}
+	}
+	requireStored := func(ctx sdk.Context, expected types.FeeAllowanceI) {
+		allowance, err := k.GetFeeAllowance(ctx, granter, grantee)
+		suite.Require().NoError(err)
+		suite.Require().Equal(expected, allowance)
+	}
+
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, grantee, limit(100)))
+	_, ok := k.CachedFeeAllowance(ctx, granter, grantee)
+	suite.Require().False(ok)
+
+	// the allowance decoded by the first use is reused by the next ones
+	_, _, err := k.UseGrantedFees(ctx, granter, grantee, fee, nil)
+	suite.Require().NoError(err)
+	cached, ok := k.CachedFeeAllowance(ctx, granter, grantee)
+	suite.Require().True(ok)
+	suite.Require().Equal(limit(90), cached)
+
+	_, _, err = k.UseGrantedFees(ctx, granter, grantee, fee, nil)
+	suite.Require().NoError(err)
+	reused, ok := k.CachedFeeAllowance(ctx, granter, grantee)
+	suite.Require().True(ok)
+	suite.Require().Same(cached, reused)
+	requireStored(ctx, limit(80))
+
+	// an allowance cached by writes which are then discarded is not used
+	cacheCtx, _ := ctx.CacheContext()
+	_, _, err = k.UseGrantedFees(cacheCtx, granter, grantee, fee, nil)
+	suite.Require().NoError(err)
+	requireStored(ctx, limit(80))
+
+	_, _, err = k.UseGrantedFees(ctx, granter, grantee, fee, nil)
+	suite.Require().NoError(err)
+	requireStored(ctx, limit(70))
+	cached, ok = k.CachedFeeAllowance(ctx, granter, grantee)
+	suite.Require().True(ok)
+	suite.Require().NotSame(reused, cached)
+	suite.Require().Equal(limit(70), cached)
+
+	// a rejected fee drops the allowance from the cache
+	_, _, err = k.UseGrantedFees(ctx, granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("atom", 1000)), nil)
+	suite.Require().Error(err)
+	_, ok = k.CachedFeeAllowance(ctx, granter, grantee)
+	suite.Require().False(ok)
+	requireStored(ctx, limit(70))
+
+	// writing the allowance invalidates the cache
+	_, _, err = k.UseGrantedFees(ctx, granter, grantee, fee, nil)
+	suite.Require().NoError(err)
+	suite.Require().NoError(k.UpdateFeeAllowance(ctx, granter, grantee, limit(50)))
+	_, ok = k.CachedFeeAllowance(ctx, granter, grantee)
+	suite.Require().False(ok)
+
+	// CheckTx does not see the DeliverTx entries, and no entry outlives its height
+	_, _, err = k.UseGrantedFees(ctx, granter, grantee, fee, nil)
+	suite.Require().NoError(err)
+	_, ok = k.CachedFeeAllowance(ctx.WithIsCheckTx(true), granter, grantee)
+	suite.Require().False(ok)
+	_, ok = k.CachedFeeAllowance(ctx.WithBlockHeight(11), granter, grantee)
+	suite.Require().False(ok)
+	_, ok = k.CachedFeeAllowance(ctx, granter, grantee)
+	suite.Require().False(ok)
+}
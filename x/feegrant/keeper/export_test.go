@@ -0,0 +1,23 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+// CachedFeeAllowance returns the allowance cached for the grant from granter
+// to grantee, leaving it in the cache.
+func (k Keeper) CachedFeeAllowance(ctx sdk.Context, granter, grantee sdk.AccAddress) (types.FeeAllowanceI, bool) {
+	k.cache.mu.Lock()
+	defer k.cache.mu.Unlock()
+
+	cached, ok := k.cache.entries(ctx)[string(types.FeeAllowanceKey(granter, grantee))]
+	return cached.allowance, ok
+}
+
+// MaxRejections is the number of grants whose last rejection is kept.
+const MaxRejections = maxRejections
+
+// LegacyKey returns the key of the pre-release layout whose addresses were
+// not length prefixed, made of prefix followed by addrs.
+var LegacyKey = legacyKey
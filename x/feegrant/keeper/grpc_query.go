@@ -0,0 +1,669 @@
+package keeper
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/cosmos/cosmos-sdk/store/prefix"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+var _ types.QueryServer = Keeper{}
+
+// Allowance returns fee granted to the grantee by the granter.
+func (k Keeper) Allowance(c context.Context, req *types.QueryAllowanceRequest) (*types.QueryAllowanceResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	granterAddr, err := sdk.AccAddressFromBech32(req.Granter)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	granteeAddr, err := sdk.AccAddressFromBech32(req.Grantee)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+
+	feeAllowance, err := k.GetFeeAllowance(ctx, granterAddr, granteeAddr)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if feeAllowance == nil {
+		return nil, status.Errorf(codes.NotFound, "no allowance for granter %s and grantee %s", req.Granter, req.Grantee)
+	}
+
+	grant, err := types.NewFeeAllowanceGrant(granterAddr, granteeAddr, feeAllowance)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryAllowanceResponse{
+		Allowance: &grant,
+	}, nil
+}
+
+// Allowances queries all the allowances granted to the given grantee.
+func (k Keeper) Allowances(c context.Context, req *types.QueryAllowancesRequest) (*types.QueryAllowancesResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	granteeAddr, err := sdk.AccAddressFromBech32(req.Grantee)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+
+	var grants []*types.FeeAllowanceGrant
+
+	store := ctx.KVStore(k.storeKey)
+	grantsStore := prefix.NewStore(store, types.FeeAllowancePrefixByGrantee(granteeAddr))
+
+	pageRes, err := query.Paginate(grantsStore, req.Pagination, func(key []byte, value []byte) error {
+		feeAllowance, err := k.UnmarshalFeeAllowance(value)
+		if err != nil {
+			return err
+		}
+
+		// the remaining key is the granter address
+		grant, err := types.NewFeeAllowanceGrant(types.ParseLengthPrefixedAddress(key), granteeAddr, feeAllowance)
+		if err != nil {
+			return err
+		}
+
+		grants = append(grants, &grant)
+		return nil
+	})
+
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryAllowancesResponse{Allowances: grants, Pagination: pageRes}, nil
+}
+
+// AllowanceRemaining returns the fees the grantee can currently spend from the
+// allowance granted by the granter.
+func (k Keeper) AllowanceRemaining(c context.Context, req *types.QueryAllowanceRemainingRequest) (*types.QueryAllowanceRemainingResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	granterAddr, err := sdk.AccAddressFromBech32(req.Granter)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	granteeAddr, err := sdk.AccAddressFromBech32(req.Grantee)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+
+	feeAllowance, err := k.GetFeeAllowance(ctx, granterAddr, granteeAddr)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if feeAllowance == nil {
+		return nil, status.Errorf(codes.NotFound, "no allowance for granter %s and grantee %s", req.Granter, req.Grantee)
+	}
+
+	remaining, err := feeAllowance.Remaining(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryAllowanceRemainingResponse{Remaining: remaining}, nil
+}
+
+// AllowancesByGranter queries all the allowances granted by the given granter.
+func (k Keeper) AllowancesByGranter(c context.Context, req *types.QueryAllowancesByGranterRequest) (*types.QueryAllowancesByGranterResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	granterAddr, err := sdk.AccAddressFromBech32(req.Granter)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+
+	var grants []*types.FeeAllowanceGrant
+
+	store := ctx.KVStore(k.storeKey)
+	indexStore := prefix.NewStore(store, types.FeeAllowancePrefixByGranter(granterAddr))
+
+	pageRes, err := query.Paginate(indexStore, req.Pagination, func(key []byte, _ []byte) error {
+		// the remaining key is the grantee address
+		granteeAddr := types.ParseLengthPrefixedAddress(key)
+
+		feeAllowance, err := k.GetFeeAllowance(ctx, granterAddr, granteeAddr)
+		if err != nil {
+			return err
+		}
+
+		if feeAllowance == nil {
+			return fmt.Errorf("granter index refers to a missing grant to %s", granteeAddr)
+		}
+
+		grant, err := types.NewFeeAllowanceGrant(granterAddr, granteeAddr, feeAllowance)
+		if err != nil {
+			return err
+		}
+
+		grants = append(grants, &grant)
+		return nil
+	})
+
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryAllowancesByGranterResponse{Allowances: grants, Pagination: pageRes}, nil
+}
+
+// GrantsCount returns the total number of grants.
+func (k Keeper) GrantsCount(c context.Context, req *types.QueryGrantsCountRequest) (*types.QueryGrantsCountResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+
+	return &types.QueryGrantsCountResponse{Count: k.GetGrantsCount(ctx)}, nil
+}
+
+// GrantAllowsMsgs returns whether the allowance granted by the granter to the
+// grantee allows msgs of the given types, without spending from it.
+func (k Keeper) GrantAllowsMsgs(c context.Context, req *types.QueryGrantAllowsMsgsRequest) (*types.QueryGrantAllowsMsgsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	granterAddr, err := sdk.AccAddressFromBech32(req.Granter)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	granteeAddr, err := sdk.AccAddressFromBech32(req.Grantee)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+
+	feeAllowance, err := k.GetFeeAllowance(ctx, granterAddr, granteeAddr)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if feeAllowance == nil {
+		return nil, status.Errorf(codes.NotFound, "no allowance for granter %s and grantee %s", req.Granter, req.Grantee)
+	}
+
+	disallowed, err := types.DisallowedMsgTypes(feeAllowance, req.TypeUrls)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryGrantAllowsMsgsResponse{
+		Allowed:            len(disallowed) == 0,
+		DisallowedTypeUrls: disallowed,
+	}, nil
+}
+
+// AllowanceSpent returns the fees spent so far from the allowance granted by
+// the granter to the grantee, along with the spend limit it was granted with.
+func (k Keeper) AllowanceSpent(c context.Context, req *types.QueryAllowanceSpentRequest) (*types.QueryAllowanceSpentResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	granterAddr, err := sdk.AccAddressFromBech32(req.Granter)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	granteeAddr, err := sdk.AccAddressFromBech32(req.Grantee)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+
+	feeAllowance, err := k.GetFeeAllowance(ctx, granterAddr, granteeAddr)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if feeAllowance == nil {
+		return nil, status.Errorf(codes.NotFound, "no allowance for granter %s and grantee %s", req.Granter, req.Grantee)
+	}
+
+	spending, found := k.GetAllowanceSpending(ctx, granterAddr, granteeAddr)
+	if !found {
+		// nothing was spent yet from a grant made before spending was recorded
+		if spending.OriginalLimit, err = feeAllowance.RemainingSpendLimit(); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	return &types.QueryAllowanceSpentResponse{
+		Spent:         spending.Spent,
+		OriginalLimit: spending.OriginalLimit,
+	}, nil
+}
+
+// AllowanceUsageRate queries the average amount of fees paid per day out of a
+// grant, dividing the fees spent so far by the time elapsed since they started
+// being tracked.
+func (k Keeper) AllowanceUsageRate(c context.Context, req *types.QueryAllowanceUsageRateRequest) (*types.QueryAllowanceUsageRateResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	granterAddr, err := sdk.AccAddressFromBech32(req.Granter)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	granteeAddr, err := sdk.AccAddressFromBech32(req.Grantee)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+
+	feeAllowance, err := k.GetFeeAllowance(ctx, granterAddr, granteeAddr)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if feeAllowance == nil {
+		return nil, status.Errorf(codes.NotFound, "no allowance for granter %s and grantee %s", req.Granter, req.Grantee)
+	}
+
+	spending, _ := k.GetAllowanceSpending(ctx, granterAddr, granteeAddr)
+	resp := &types.QueryAllowanceUsageRateResponse{Spent: spending.Spent, Since: spending.Since}
+
+	// grants made before the start of spending was recorded have no rate
+	// until they are next used
+	if spending.Since.IsZero() {
+		return resp, nil
+	}
+
+	elapsed := int64(ctx.BlockTime().Sub(spending.Since).Seconds())
+	if elapsed > 0 {
+		const secondsPerDay = 24 * 60 * 60
+		resp.PerDay = sdk.NewDecCoinsFromCoins(spending.Spent...).
+			MulDec(sdk.NewDec(secondsPerDay)).
+			QuoDec(sdk.NewDec(elapsed))
+	}
+
+	return resp, nil
+}
+
+// AllowancesExpiringBefore queries the allowances expiring before the given
+// time, walking the expiration queue.
+func (k Keeper) AllowancesExpiringBefore(c context.Context, req *types.QueryAllowancesExpiringBeforeRequest) (*types.QueryAllowancesExpiringBeforeResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+
+	var grants []*types.FeeAllowanceGrant
+
+	store := ctx.KVStore(k.storeKey)
+	queueStore := boundedStore{
+		KVStore: prefix.NewStore(store, types.FeeAllowanceQueueKeyPrefix),
+		end:     sdk.FormatTimeBytes(req.Time),
+	}
+
+	pageRes, err := query.Paginate(queueStore, req.Pagination, func(key []byte, _ []byte) error {
+		granterAddr, granteeAddr := types.ParseAddressesFromFeeAllowanceQueueKey(append(types.FeeAllowanceQueueKeyPrefix, key...))
+
+		feeAllowance, err := k.GetFeeAllowance(ctx, granterAddr, granteeAddr)
+		if err != nil {
+			return err
+		}
+
+		if feeAllowance == nil {
+			return fmt.Errorf("expiration queue refers to a missing grant from %s to %s", granterAddr, granteeAddr)
+		}
+
+		grant, err := types.NewFeeAllowanceGrant(granterAddr, granteeAddr, feeAllowance)
+		if err != nil {
+			return err
+		}
+
+		grants = append(grants, &grant)
+		return nil
+	})
+
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryAllowancesExpiringBeforeResponse{Allowances: grants, Pagination: pageRes}, nil
+}
+
+// boundedStore only iterates over the keys of the underlying store before end,
+// so that pagination stops there.
+type boundedStore struct {
+	sdk.KVStore
+	end []byte
+}
+
+func (s boundedStore) Iterator(start, _ []byte) sdk.Iterator {
+	return s.KVStore.Iterator(start, s.end)
+}
+
+// AllowancesByFilter queries the grants matching a filter expression, see
+// types.ParseGrantFilter.
+func (k Keeper) AllowancesByFilter(c context.Context, req *types.QueryAllowancesByFilterRequest) (*types.QueryAllowancesByFilterResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	filter, err := types.ParseGrantFilter(req.Filter)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+
+	var grants []*types.FeeAllowanceGrant
+
+	filterStore, parseKey := k.grantFilterStore(ctx, filter)
+
+	pageRes, err := query.FilteredPaginate(filterStore, req.Pagination, func(key []byte, _ []byte, accumulate bool) (bool, error) {
+		granterAddr, granteeAddr := parseKey(key)
+
+		feeAllowance, err := k.GetFeeAllowance(ctx, granterAddr, granteeAddr)
+		if err != nil {
+			return false, err
+		}
+
+		if feeAllowance == nil {
+			return false, fmt.Errorf("index refers to a missing grant from %s to %s", granterAddr, granteeAddr)
+		}
+
+		match, err := filter.Matches(ctx, granterAddr, granteeAddr, feeAllowance)
+		if err != nil || !match {
+			return false, err
+		}
+
+		if accumulate {
+			grant, err := types.NewFeeAllowanceGrant(granterAddr, granteeAddr, feeAllowance)
+			if err != nil {
+				return false, err
+			}
+
+			grants = append(grants, &grant)
+		}
+
+		return true, nil
+	})
+
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryAllowancesByFilterResponse{Allowances: grants, Pagination: pageRes}, nil
+}
+
+// grantFilterStore returns the store to walk for the grants matching filter,
+// along with the function parsing the granter and grantee out of its keys. It
+// narrows the walk down to the grants of the filter's grantee, or else to the
+// granter index of its granter, or else to the expiration queue up to its
+// expiring_before time, and falls back to all the grants.
+func (k Keeper) grantFilterStore(ctx sdk.Context, filter types.GrantFilter) (sdk.KVStore, func(key []byte) (granter, grantee sdk.AccAddress)) {
+	store := ctx.KVStore(k.storeKey)
+
+	switch {
+	case filter.Grantee != nil:
+		// the remaining key is the granter address
+		return prefix.NewStore(store, types.FeeAllowancePrefixByGrantee(filter.Grantee)), func(key []byte) (sdk.AccAddress, sdk.AccAddress) {
+			return types.ParseLengthPrefixedAddress(key), filter.Grantee
+		}
+
+	case filter.Granter != nil:
+		// the remaining key is the grantee address
+		return prefix.NewStore(store, types.FeeAllowancePrefixByGranter(filter.Granter)), func(key []byte) (sdk.AccAddress, sdk.AccAddress) {
+			return filter.Granter, types.ParseLengthPrefixedAddress(key)
+		}
+
+	case filter.ExpiringBefore != nil:
+		queueStore := boundedStore{
+			KVStore: prefix.NewStore(store, types.FeeAllowanceQueueKeyPrefix),
+			end:     sdk.FormatTimeBytes(*filter.ExpiringBefore),
+		}
+		return queueStore, func(key []byte) (sdk.AccAddress, sdk.AccAddress) {
+			return types.ParseAddressesFromFeeAllowanceQueueKey(append(types.FeeAllowanceQueueKeyPrefix, key...))
+		}
+
+	default:
+		return prefix.NewStore(store, types.FeeAllowanceKeyPrefix), func(key []byte) (sdk.AccAddress, sdk.AccAddress) {
+			return types.ParseAddressesFromFeeAllowanceKey(append(types.FeeAllowanceKeyPrefix, key...))
+		}
+	}
+}
+
+const (
+	// defaultStreamBatchSize is the number of grants per AllowancesStream
+	// response when the request does not set one.
+	defaultStreamBatchSize = 100
+
+	// maxStreamBatchSize caps the number of grants per AllowancesStream response.
+	maxStreamBatchSize = 1000
+)
+
+// AllowancesStream streams the grants given by the requested granter, or all
+// the grants, in batches. It stops as soon as the stream's context is done.
+func (k Keeper) AllowancesStream(req *types.QueryAllowancesStreamRequest, stream types.Query_AllowancesStreamServer) error {
+	if req == nil {
+		return status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	var granterAddr sdk.AccAddress
+	if req.Granter != "" {
+		var err error
+		if granterAddr, err = sdk.AccAddressFromBech32(req.Granter); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	batchSize := int(req.BatchSize)
+	switch {
+	case batchSize == 0:
+		batchSize = defaultStreamBatchSize
+	case batchSize > maxStreamBatchSize:
+		batchSize = maxStreamBatchSize
+	}
+
+	ctx := sdk.UnwrapSDKContext(stream.Context())
+
+	var batch []*types.FeeAllowanceGrant
+	send := func() error {
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		if err := stream.Send(&types.QueryAllowancesStreamResponse{Allowances: batch}); err != nil {
+			return err
+		}
+
+		batch = nil
+		return nil
+	}
+
+	var sendErr error
+	cb := func(grant types.FeeAllowanceGrant) bool {
+		batch = append(batch, &grant)
+		if len(batch) == batchSize {
+			sendErr = send()
+		}
+		return sendErr != nil
+	}
+
+	var err error
+	if granterAddr != nil {
+		err = k.iterateFeeAllowancesByGranter(ctx, granterAddr, cb)
+	} else {
+		err = k.IterateAllFeeAllowances(ctx, cb)
+	}
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	if sendErr != nil {
+		return sendErr
+	}
+
+	if len(batch) > 0 {
+		return send()
+	}
+
+	return nil
+}
+
+// GrantRaw returns the allowance granted to the grantee by the granter as it is
+// stored.
+func (k Keeper) GrantRaw(c context.Context, req *types.QueryGrantRawRequest) (*types.QueryGrantRawResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	granterAddr, err := sdk.AccAddressFromBech32(req.Granter)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	granteeAddr, err := sdk.AccAddressFromBech32(req.Grantee)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+
+	any, err := k.GetFeeGrantAny(ctx, granterAddr, granteeAddr)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if any == nil {
+		return nil, status.Errorf(codes.NotFound, "no allowance for granter %s and grantee %s", req.Granter, req.Grantee)
+	}
+
+	return &types.QueryGrantRawResponse{Allowance: any}, nil
+}
+
+// GranterExposure returns the total the grants given by the granter can still
+// pay, see GetGranterExposure.
+func (k Keeper) GranterExposure(c context.Context, req *types.QueryGranterExposureRequest) (*types.QueryGranterExposureResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	granterAddr, err := sdk.AccAddressFromBech32(req.Granter)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+
+	exposure, unlimited, err := k.GetGranterExposure(ctx, granterAddr)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryGranterExposureResponse{Exposure: exposure, UnlimitedGrants: unlimited}, nil
+}
+
+// AllowanceTyped returns the allowance granted to the grantee by the granter
+// broken down into typed fields, see types.NewAllowanceBreakdown.
+func (k Keeper) AllowanceTyped(c context.Context, req *types.QueryAllowanceTypedRequest) (*types.QueryAllowanceTypedResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	granterAddr, err := sdk.AccAddressFromBech32(req.Granter)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	granteeAddr, err := sdk.AccAddressFromBech32(req.Grantee)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+
+	feeAllowance, err := k.GetFeeAllowance(ctx, granterAddr, granteeAddr)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if feeAllowance == nil {
+		return nil, status.Errorf(codes.NotFound, "no allowance for granter %s and grantee %s", req.Granter, req.Grantee)
+	}
+
+	breakdown, err := types.NewAllowanceBreakdown(feeAllowance)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &types.QueryAllowanceTypedResponse{Allowance: breakdown}, nil
+}
+
+// SponsorshipRejection returns the last rejection recorded for a grant by
+// this node. It is best effort: the rejections are not part of the state, so
+// they differ from node to node and do not survive a restart.
+func (k Keeper) SponsorshipRejection(c context.Context, req *types.QuerySponsorshipRejectionRequest) (*types.QuerySponsorshipRejectionResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	granterAddr, err := sdk.AccAddressFromBech32(req.Granter)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	granteeAddr, err := sdk.AccAddressFromBech32(req.Grantee)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	rejection, found := k.GetRejection(granterAddr, granteeAddr)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "no rejection for granter %s and grantee %s", req.Granter, req.Grantee)
+	}
+
+	return &types.QuerySponsorshipRejectionResponse{Rejection: &rejection}, nil
+}
+
+// Params returns the parameters of the feegrant module.
+func (k Keeper) Params(c context.Context, req *types.QueryParamsRequest) (*types.QueryParamsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+
+	ctx := sdk.UnwrapSDKContext(c)
+
+	return &types.QueryParamsResponse{Params: k.GetParams(ctx)}, nil
+}
@@ -0,0 +1,1089 @@
+package keeper_test
+
+import (
+	gocontext "context"
+	"fmt"
+	"io"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/keeper"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+func (suite *KeeperTestSuite) TestEmptyRequests() {
+	ctx := sdk.WrapSDKContext(suite.sdkCtx)
+	k := suite.app.FeeGrantKeeper
+
+	_, err := k.Allowance(ctx, nil)
+	suite.Require().Error(err)
+
+	_, err = k.Allowances(ctx, nil)
+	suite.Require().Error(err)
+
+	_, err = k.AllowanceRemaining(ctx, nil)
+	suite.Require().Error(err)
+
+	_, err = k.AllowancesByGranter(ctx, nil)
+	suite.Require().Error(err)
+
+	_, err = k.GrantsCount(ctx, nil)
+	suite.Require().Error(err)
+
+	_, err = k.GrantAllowsMsgs(ctx, nil)
+	suite.Require().Error(err)
+
+	_, err = k.AllowanceSpent(ctx, nil)
+	suite.Require().Error(err)
+
+	_, err = k.AllowanceUsageRate(ctx, nil)
+	suite.Require().Error(err)
+
+	_, err = k.AllowancesByFilter(ctx, nil)
+	suite.Require().Error(err)
+
+	_, err = k.SponsorshipRejection(ctx, nil)
+	suite.Require().Error(err)
+
+	_, err = k.AllowancesExpiringBefore(ctx, nil)
+	suite.Require().Error(err)
+
+	err = k.AllowancesStream(nil, nil)
+	suite.Require().Error(err)
+
+	_, err = k.GrantRaw(ctx, nil)
+	suite.Require().Error(err)
+
+	_, err = k.GranterExposure(ctx, nil)
+	suite.Require().Error(err)
+
+	_, err = k.AllowanceTyped(ctx, nil)
+	suite.Require().Error(err)
+
+	_, err = k.Params(ctx, nil)
+	suite.Require().Error(err)
+}
+
+func (suite *KeeperTestSuite) TestFeeAllowance() {
+	ctx := suite.sdkCtx
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+
+	testCases := []struct {
+		name    string
+		req     *types.QueryAllowanceRequest
+		preRun  func()
+		expErr  bool
+		postRun func(*types.QueryAllowanceResponse)
+	}{
+		{
+			"fail: invalid granter",
+			&types.QueryAllowanceRequest{
+				Granter: "invalid_granter",
+				Grantee: suite.addrs[0].String(),
+			},
+			func() {},
+			true,
+			func(*types.QueryAllowanceResponse) {},
+		},
+		{
+			"fail: no grants",
+			&types.QueryAllowanceRequest{
+				Granter: suite.addrs[0].String(),
+				Grantee: suite.addrs[1].String(),
+			},
+			func() {},
+			true,
+			func(*types.QueryAllowanceResponse) {},
+		},
+		{
+			"valid query: expect single grant",
+			&types.QueryAllowanceRequest{
+				Granter: suite.addrs[0].String(),
+				Grantee: suite.addrs[1].String(),
+			},
+			func() {
+				err := suite.app.FeeGrantKeeper.GrantFeeAllowance(ctx, suite.addrs[0], suite.addrs[1], &types.BasicAllowance{SpendLimit: atom})
+				suite.Require().NoError(err)
+			},
+			false,
+			func(resp *types.QueryAllowanceResponse) {
+				suite.Require().Equal(suite.addrs[0].String(), resp.Allowance.Granter)
+				suite.Require().Equal(suite.addrs[1].String(), resp.Allowance.Grantee)
+
+				allowance, err := resp.Allowance.GetFeeGrant()
+				suite.Require().NoError(err)
+				suite.Require().Equal(&types.BasicAllowance{SpendLimit: atom}, allowance)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.preRun()
+			resp, err := suite.queryClient.Allowance(gocontext.Background(), tc.req)
+			if tc.expErr {
+				suite.Require().Error(err)
+			} else {
+				suite.Require().NoError(err)
+				tc.postRun(resp)
+			}
+		})
+	}
+}
+
+func (suite *KeeperTestSuite) TestFeeAllowances() {
+	ctx := suite.sdkCtx
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+
+	testCases := []struct {
+		name    string
+		req     *types.QueryAllowancesRequest
+		preRun  func()
+		expErr  bool
+		postRun func(*types.QueryAllowancesResponse)
+	}{
+		{
+			"fail: invalid grantee",
+			&types.QueryAllowancesRequest{
+				Grantee: "invalid_grantee",
+			},
+			func() {},
+			true,
+			func(*types.QueryAllowancesResponse) {},
+		},
+		{
+			"no grants",
+			&types.QueryAllowancesRequest{
+				Grantee: suite.addrs[1].String(),
+			},
+			func() {},
+			false,
+			func(resp *types.QueryAllowancesResponse) {
+				suite.Require().Equal(len(resp.Allowances), 0)
+			},
+		},
+		{
+			"valid query: expect grants from two granters",
+			&types.QueryAllowancesRequest{
+				Grantee: suite.addrs[1].String(),
+			},
+			func() {
+				k := suite.app.FeeGrantKeeper
+				suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[0], suite.addrs[1], &types.BasicAllowance{SpendLimit: atom}))
+				suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[2], suite.addrs[1], &types.BasicAllowance{SpendLimit: atom}))
+				// a grant to another grantee must not show up
+				suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[0], suite.addrs[3], &types.BasicAllowance{SpendLimit: atom}))
+			},
+			false,
+			func(resp *types.QueryAllowancesResponse) {
+				suite.Require().Equal(len(resp.Allowances), 2)
+				for _, grant := range resp.Allowances {
+					suite.Require().Equal(suite.addrs[1].String(), grant.Grantee)
+				}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.preRun()
+			resp, err := suite.queryClient.Allowances(gocontext.Background(), tc.req)
+			if tc.expErr {
+				suite.Require().Error(err)
+			} else {
+				suite.Require().NoError(err)
+				tc.postRun(resp)
+			}
+		})
+	}
+}
+
+func (suite *KeeperTestSuite) TestFeeAllowancesPagination() {
+	grantee := suite.addrs[0]
+	granters := make([]sdk.AccAddress, 50)
+	for i := range granters {
+		granters[i] = sdk.AccAddress(fmt.Sprintf("granter%013d", i))
+		err := suite.app.FeeGrantKeeper.GrantFeeAllowance(suite.sdkCtx, granters[i], grantee, &types.BasicAllowance{
+			SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 555)),
+		})
+		suite.Require().NoError(err)
+	}
+
+	// page through using the next key
+	var (
+		seen    []string
+		nextKey []byte
+	)
+	for page := 0; page < 5; page++ {
+		resp, err := suite.queryClient.Allowances(gocontext.Background(), &types.QueryAllowancesRequest{
+			Grantee:    grantee.String(),
+			Pagination: &query.PageRequest{Key: nextKey, Limit: 10, CountTotal: page == 0},
+		})
+		suite.Require().NoError(err)
+		suite.Require().Len(resp.Allowances, 10)
+		if page == 0 {
+			suite.Require().Equal(uint64(50), resp.Pagination.Total)
+		}
+
+		for _, grant := range resp.Allowances {
+			suite.Require().Equal(grantee.String(), grant.Grantee)
+			seen = append(seen, grant.Granter)
+		}
+		nextKey = resp.Pagination.NextKey
+	}
+	suite.Require().Nil(nextKey)
+
+	expected := make([]string, len(granters))
+	for i, granter := range granters {
+		expected[i] = granter.String()
+	}
+	suite.Require().ElementsMatch(expected, seen)
+
+	// offset based paging returns the same batches
+	resp, err := suite.queryClient.Allowances(gocontext.Background(), &types.QueryAllowancesRequest{
+		Grantee:    grantee.String(),
+		Pagination: &query.PageRequest{Offset: 20, Limit: 10},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(resp.Allowances, 10)
+	for i, grant := range resp.Allowances {
+		suite.Require().Equal(seen[20+i], grant.Granter)
+	}
+}
+
+func (suite *KeeperTestSuite) TestAllowanceRemaining() {
+	k := suite.app.FeeGrantKeeper
+	now := suite.sdkCtx.BlockTime()
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	oneHour := now.Add(time.Hour)
+
+	periodic := &types.PeriodicFeeAllowance{
+		Basic:            types.BasicAllowance{SpendLimit: atom},
+		Period:           time.Hour,
+		PeriodSpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 100)),
+		PeriodCanSpend:   sdk.NewCoins(sdk.NewInt64Coin("atom", 40)),
+		PeriodReset:      oneHour,
+	}
+
+	testCases := []struct {
+		name      string
+		allowance types.FeeAllowanceI
+		blockTime time.Time
+		remaining sdk.Coins
+	}{
+		{
+			"basic",
+			&types.BasicAllowance{SpendLimit: atom, Expiration: &oneHour},
+			now,
+			atom,
+		},
+		{
+			"basic without spend limit",
+			&types.BasicAllowance{},
+			now,
+			nil,
+		},
+		{
+			"periodic mid-period",
+			periodic,
+			now.Add(30 * time.Minute),
+			sdk.NewCoins(sdk.NewInt64Coin("atom", 40)),
+		},
+		{
+			"periodic after a reset",
+			periodic,
+			now.Add(90 * time.Minute),
+			sdk.NewCoins(sdk.NewInt64Coin("atom", 100)),
+		},
+		{
+			"expired",
+			&types.BasicAllowance{SpendLimit: atom, Expiration: &oneHour},
+			now.Add(2 * time.Hour),
+			sdk.Coins{},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.Require().NoError(k.GrantFeeAllowance(suite.sdkCtx, suite.addrs[0], suite.addrs[1], tc.allowance))
+
+			ctx := sdk.WrapSDKContext(suite.sdkCtx.WithBlockTime(tc.blockTime))
+			resp, err := k.AllowanceRemaining(ctx, &types.QueryAllowanceRemainingRequest{
+				Granter: suite.addrs[0].String(),
+				Grantee: suite.addrs[1].String(),
+			})
+			suite.Require().NoError(err)
+			suite.Require().Equal(tc.remaining, resp.Remaining)
+		})
+	}
+
+	// computing the remaining fees does not reset the period
+	_, err := periodic.Remaining(suite.sdkCtx.WithBlockTime(now.Add(90 * time.Minute)))
+	suite.Require().NoError(err)
+	suite.Require().Equal(oneHour, periodic.PeriodReset)
+	suite.Require().Equal(sdk.NewCoins(sdk.NewInt64Coin("atom", 40)), periodic.PeriodCanSpend)
+
+	_, err = k.AllowanceRemaining(sdk.WrapSDKContext(suite.sdkCtx), &types.QueryAllowanceRemainingRequest{
+		Granter: suite.addrs[1].String(),
+		Grantee: suite.addrs[0].String(),
+	})
+	suite.Require().Error(err)
+}
+
+func (suite *KeeperTestSuite) TestAllowancesByGranter() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+	granter := suite.addrs[0]
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	eth := sdk.NewCoins(sdk.NewInt64Coin("eth", 123))
+
+	_, err := suite.queryClient.AllowancesByGranter(gocontext.Background(), &types.QueryAllowancesByGranterRequest{Granter: "invalid_granter"})
+	suite.Require().Error(err)
+
+	for _, grantee := range suite.addrs[1:] {
+		suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, grantee, &types.BasicAllowance{SpendLimit: atom}))
+	}
+	// a grant from another granter must not show up
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[1], suite.addrs[2], &types.BasicAllowance{SpendLimit: atom}))
+
+	// page through the granter view
+	var (
+		grantees []string
+		nextKey  []byte
+	)
+	for page := 0; page < 2; page++ {
+		resp, err := suite.queryClient.AllowancesByGranter(gocontext.Background(), &types.QueryAllowancesByGranterRequest{
+			Granter:    granter.String(),
+			Pagination: &query.PageRequest{Key: nextKey, Limit: 2, CountTotal: page == 0},
+		})
+		suite.Require().NoError(err)
+		if page == 0 {
+			suite.Require().Len(resp.Allowances, 2)
+			suite.Require().Equal(uint64(3), resp.Pagination.Total)
+		}
+
+		for _, grant := range resp.Allowances {
+			suite.Require().Equal(granter.String(), grant.Granter)
+			grantees = append(grantees, grant.Grantee)
+		}
+		nextKey = resp.Pagination.NextKey
+	}
+	suite.Require().Nil(nextKey)
+	suite.Require().Equal([]string{suite.addrs[1].String(), suite.addrs[2].String(), suite.addrs[3].String()}, grantees)
+
+	// the index follows updates and revocations
+	suite.Require().NoError(k.UpdateFeeAllowance(ctx, granter, suite.addrs[2], &types.BasicAllowance{SpendLimit: eth}))
+	suite.Require().NoError(k.RevokeFeeAllowance(ctx, granter, suite.addrs[1]))
+
+	resp, err := suite.queryClient.AllowancesByGranter(gocontext.Background(), &types.QueryAllowancesByGranterRequest{Granter: granter.String()})
+	suite.Require().NoError(err)
+	suite.Require().Len(resp.Allowances, 2)
+	suite.Require().Equal(suite.addrs[2].String(), resp.Allowances[0].Grantee)
+	suite.Require().Equal(suite.addrs[3].String(), resp.Allowances[1].Grantee)
+
+	allowance, err := resp.Allowances[0].GetFeeGrant()
+	suite.Require().NoError(err)
+	suite.Require().Equal(&types.BasicAllowance{SpendLimit: eth}, allowance)
+}
+
+func (suite *KeeperTestSuite) TestGrantsCount() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	exp := ctx.BlockTime().Add(time.Hour)
+
+	requireCount := func(expected uint64) {
+		suite.Require().Equal(expected, k.GetGrantsCount(ctx))
+
+		resp, err := suite.queryClient.GrantsCount(gocontext.Background(), &types.QueryGrantsCountRequest{})
+		suite.Require().NoError(err)
+		suite.Require().Equal(expected, resp.Count)
+	}
+	requireCount(0)
+
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[0], suite.addrs[1], &types.BasicAllowance{SpendLimit: atom}))
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[0], suite.addrs[2], &types.BasicAllowance{Expiration: &exp}))
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[0], suite.addrs[3], &types.BasicAllowance{}))
+	requireCount(3)
+
+	// replacing, updating or spending from a grant does not change the count
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[0], suite.addrs[3], &types.BasicAllowance{SpendLimit: atom}))
+	suite.Require().NoError(k.UpdateFeeAllowance(ctx, suite.addrs[0], suite.addrs[3], &types.BasicAllowance{}))
+	_, _, err := k.UseGrantedFees(ctx, suite.addrs[0], suite.addrs[1], sdk.NewCoins(sdk.NewInt64Coin("atom", 55)), nil)
+	suite.Require().NoError(err)
+	requireCount(3)
+
+	suite.Require().NoError(k.RevokeFeeAllowance(ctx, suite.addrs[0], suite.addrs[3]))
+	requireCount(2)
+
+	k.RemoveExpiredAllowances(ctx.WithBlockTime(exp.Add(time.Second)))
+	requireCount(1)
+
+	// using up a grant removes it
+	_, _, err = k.UseGrantedFees(ctx, suite.addrs[0], suite.addrs[1], sdk.NewCoins(sdk.NewInt64Coin("atom", 500)), nil)
+	suite.Require().NoError(err)
+	requireCount(0)
+}
+
+func (suite *KeeperTestSuite) TestGrantAllowsMsgs() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+	basic := &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 555))}
+	vote, send, delegate := "/cosmos.gov.v1beta1.MsgVote", "/cosmos.bank.v1beta1.MsgSend", "/cosmos.staking.v1beta1.MsgDelegate"
+
+	filtered, err := types.NewAllowedMsgAllowance(basic, []string{vote, send})
+	suite.Require().NoError(err)
+	denomFiltered, err := types.NewAllowedDenomAllowance(filtered, []string{"atom"})
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[0], suite.addrs[1], basic))
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[0], suite.addrs[2], filtered))
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[0], suite.addrs[3], denomFiltered))
+
+	testCases := []struct {
+		name          string
+		grantee       sdk.AccAddress
+		typeURLs      []string
+		expErr        bool
+		expDisallowed []string
+	}{
+		{"no grant", suite.addrs[0], []string{vote}, true, nil},
+		{"no filter", suite.addrs[1], []string{vote, delegate}, false, nil},
+		{"allowed", suite.addrs[2], []string{vote, send}, false, nil},
+		{"partially allowed", suite.addrs[2], []string{vote, delegate}, false, []string{delegate}},
+		{"wrapped filter", suite.addrs[3], []string{delegate, send}, false, []string{delegate}},
+		{"no msgs", suite.addrs[2], nil, false, nil},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		suite.Run(tc.name, func() {
+			resp, err := suite.queryClient.GrantAllowsMsgs(gocontext.Background(), &types.QueryGrantAllowsMsgsRequest{
+				Granter:  suite.addrs[0].String(),
+				Grantee:  tc.grantee.String(),
+				TypeUrls: tc.typeURLs,
+			})
+			if tc.expErr {
+				suite.Require().Error(err)
+				return
+			}
+
+			suite.Require().NoError(err)
+			suite.Require().Equal(len(tc.expDisallowed) == 0, resp.Allowed)
+			suite.Require().Equal(tc.expDisallowed, resp.DisallowedTypeUrls)
+		})
+	}
+
+	// nothing was spent
+	grant, err := k.GetFeeAllowance(ctx, suite.addrs[0], suite.addrs[2])
+	suite.Require().NoError(err)
+	suite.Require().Equal(filtered, grant)
+}
+
+func (suite *KeeperTestSuite) TestAllowanceSpent() {
+	k := suite.app.FeeGrantKeeper
+	ctx := sdk.WrapSDKContext(suite.sdkCtx)
+	granter, grantee := suite.addrs[0], suite.addrs[1]
+	limit := sdk.NewCoins(sdk.NewInt64Coin("atom", 100))
+	req := &types.QueryAllowanceSpentRequest{Granter: granter.String(), Grantee: grantee.String()}
+
+	_, err := k.AllowanceSpent(ctx, req)
+	suite.Require().Error(err)
+
+	suite.Require().NoError(k.GrantFeeAllowance(suite.sdkCtx, granter, grantee, &types.BasicAllowance{SpendLimit: limit}))
+
+	resp, err := k.AllowanceSpent(ctx, req)
+	suite.Require().NoError(err)
+	suite.Require().True(resp.Spent.IsZero())
+	suite.Require().Equal(limit, resp.OriginalLimit)
+
+	// each tx adds its fee to the total spent
+	for _, amount := range []int64{10, 15, 5} {
+		fee := sdk.NewCoins(sdk.NewInt64Coin("atom", amount))
+		_, _, err = k.UseGrantedFees(suite.sdkCtx, granter, grantee, fee, nil)
+		suite.Require().NoError(err)
+	}
+
+	resp, err = k.AllowanceSpent(ctx, req)
+	suite.Require().NoError(err)
+	suite.Require().Equal(sdk.NewCoins(sdk.NewInt64Coin("atom", 30)), resp.Spent)
+	suite.Require().Equal(limit, resp.OriginalLimit)
+
+	// a rejected fee is not counted
+	_, _, err = k.UseGrantedFees(suite.sdkCtx, granter, grantee, limit, nil)
+	suite.Require().Error(err)
+
+	// the total spent survives an update of the allowance
+	newLimit := sdk.NewCoins(sdk.NewInt64Coin("atom", 50), sdk.NewInt64Coin("eth", 20))
+	suite.Require().NoError(k.UpdateFeeAllowance(suite.sdkCtx, granter, grantee, &types.BasicAllowance{SpendLimit: newLimit}))
+	_, _, err = k.UseGrantedFees(suite.sdkCtx, granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("eth", 20)), nil)
+	suite.Require().NoError(err)
+
+	resp, err = k.AllowanceSpent(ctx, req)
+	suite.Require().NoError(err)
+	suite.Require().Equal(sdk.NewCoins(sdk.NewInt64Coin("atom", 30), sdk.NewInt64Coin("eth", 20)), resp.Spent)
+	suite.Require().Equal(newLimit, resp.OriginalLimit)
+
+	// a new grant starts from scratch
+	suite.Require().NoError(k.RevokeFeeAllowance(suite.sdkCtx, granter, grantee))
+	suite.Require().NoError(k.GrantFeeAllowance(suite.sdkCtx, granter, grantee, &types.BasicAllowance{}))
+
+	resp, err = k.AllowanceSpent(ctx, req)
+	suite.Require().NoError(err)
+	suite.Require().True(resp.Spent.IsZero())
+	suite.Require().True(resp.OriginalLimit.IsZero())
+}
+
+func (suite *KeeperTestSuite) TestAllowanceUsageRate() {
+	k := suite.app.FeeGrantKeeper
+	granter, grantee := suite.addrs[0], suite.addrs[1]
+	limit := sdk.NewCoins(sdk.NewInt64Coin("atom", 1000))
+	req := &types.QueryAllowanceUsageRateRequest{Granter: granter.String(), Grantee: grantee.String()}
+	start := suite.sdkCtx.BlockTime()
+	day := 24 * time.Hour
+
+	_, err := k.AllowanceUsageRate(sdk.WrapSDKContext(suite.sdkCtx), req)
+	suite.Require().Equal(codes.NotFound, status.Code(err))
+
+	suite.Require().NoError(k.GrantFeeAllowance(suite.sdkCtx, granter, grantee, &types.BasicAllowance{SpendLimit: limit}))
+
+	// no time has elapsed yet
+	resp, err := k.AllowanceUsageRate(sdk.WrapSDKContext(suite.sdkCtx), req)
+	suite.Require().NoError(err)
+	suite.Require().True(resp.Spent.IsZero())
+	suite.Require().True(resp.Since.Equal(start))
+	suite.Require().True(resp.PerDay.IsZero())
+
+	// 90 atom spent over three days
+	uses := []struct {
+		after  time.Duration
+		amount int64
+	}{
+		{6 * time.Hour, 10},
+		{day, 20},
+		{2 * day, 30},
+		{3 * day, 30},
+	}
+	for _, use := range uses {
+		ctx := suite.sdkCtx.WithBlockTime(start.Add(use.after))
+		_, _, err = k.UseGrantedFees(ctx, granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("atom", use.amount)), nil)
+		suite.Require().NoError(err)
+	}
+
+	cases := map[string]struct {
+		elapsed time.Duration
+		perDay  sdk.DecCoins
+	}{
+		"after three days": {
+			elapsed: 3 * day,
+			perDay:  sdk.NewDecCoins(sdk.NewInt64DecCoin("atom", 30)),
+		},
+		"after four days": {
+			elapsed: 4 * day,
+			perDay:  sdk.NewDecCoins(sdk.NewDecCoinFromDec("atom", sdk.NewDecWithPrec(225, 1))),
+		},
+		"after ten days": {
+			elapsed: 10 * day,
+			perDay:  sdk.NewDecCoins(sdk.NewInt64DecCoin("atom", 9)),
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		suite.Run(name, func() {
+			ctx := suite.sdkCtx.WithBlockTime(start.Add(tc.elapsed))
+			resp, err := k.AllowanceUsageRate(sdk.WrapSDKContext(ctx), req)
+			suite.Require().NoError(err)
+			suite.Require().Equal(sdk.NewCoins(sdk.NewInt64Coin("atom", 90)), resp.Spent)
+			suite.Require().True(resp.Since.Equal(start))
+			suite.Require().Equal(tc.perDay, resp.PerDay)
+		})
+	}
+}
+
+func (suite *KeeperTestSuite) TestAllowanceUsageRateGenesis() {
+	k := suite.app.FeeGrantKeeper
+	granter, grantee := suite.addrs[0], suite.addrs[1]
+	req := &types.QueryAllowanceUsageRateRequest{Granter: granter.String(), Grantee: grantee.String()}
+	start := suite.sdkCtx.BlockTime()
+	day := 24 * time.Hour
+
+	suite.Require().NoError(k.GrantFeeAllowance(suite.sdkCtx, granter, grantee, &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 1000))}))
+
+	// 90 atom spent over three days
+	ctx := suite.sdkCtx.WithBlockTime(start.Add(3 * day))
+	_, _, err := k.UseGrantedFees(ctx, granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("atom", 90)), nil)
+	suite.Require().NoError(err)
+
+	genesis, err := feegrant.ExportGenesis(ctx, k)
+	suite.Require().NoError(err)
+
+	// clear the store and re-import the exported state a day later
+	suite.Require().NoError(k.RevokeFeeAllowance(ctx, granter, grantee))
+	ctx = suite.sdkCtx.WithBlockTime(start.Add(4 * day))
+	suite.Require().NoError(feegrant.InitGenesis(ctx, k, genesis))
+
+	resp, err := k.AllowanceUsageRate(sdk.WrapSDKContext(ctx), req)
+	suite.Require().NoError(err)
+	suite.Require().Equal(sdk.NewCoins(sdk.NewInt64Coin("atom", 90)), resp.Spent)
+	suite.Require().True(resp.Since.Equal(start))
+	suite.Require().Equal(sdk.NewDecCoins(sdk.NewDecCoinFromDec("atom", sdk.NewDecWithPrec(225, 1))), resp.PerDay)
+}
+
+func (suite *KeeperTestSuite) TestAllowancesByFilter() {
+	k := suite.app.FeeGrantKeeper
+	ctx := suite.sdkCtx
+	addrs := suite.addrs
+	now := ctx.BlockTime()
+	inOneDay, inTwoDays := now.Add(24*time.Hour), now.Add(48*time.Hour)
+	atom := func(amount int64) sdk.Coins { return sdk.NewCoins(sdk.NewInt64Coin("atom", amount)) }
+
+	filtered, err := types.NewAllowedMsgAllowance(&types.BasicAllowance{Expiration: &inTwoDays}, []string{"/cosmos.bank.v1beta1.MsgSend"})
+	suite.Require().NoError(err)
+
+	grants := []struct {
+		granter, grantee sdk.AccAddress
+		allowance        types.FeeAllowanceI
+	}{
+		{addrs[0], addrs[1], &types.BasicAllowance{SpendLimit: atom(100), Expiration: &inOneDay}},
+		{addrs[0], addrs[2], &types.PeriodicFeeAllowance{Period: time.Hour, PeriodSpendLimit: atom(10), PeriodCanSpend: atom(10), PeriodReset: now}},
+		{addrs[1], addrs[2], &types.BasicAllowance{SpendLimit: atom(5)}},
+		{addrs[2], addrs[3], filtered},
+		{addrs[3], addrs[1], &types.BasicAllowance{}},
+	}
+	for _, g := range grants {
+		suite.Require().NoError(k.GrantFeeAllowance(ctx, g.granter, g.grantee, g.allowance))
+	}
+
+	cases := map[string]struct {
+		filter    string
+		expGrants []int
+	}{
+		"no term": {
+			"",
+			[]int{0, 1, 2, 3, 4},
+		},
+		"granter": {
+			fmt.Sprintf("granter=%s", addrs[0]),
+			[]int{0, 1},
+		},
+		"grantee": {
+			fmt.Sprintf("grantee=%s", addrs[2]),
+			[]int{1, 2},
+		},
+		"granter and grantee": {
+			fmt.Sprintf("granter=%s grantee=%s", addrs[0], addrs[2]),
+			[]int{1},
+		},
+		"type": {
+			"type=/cosmos.feegrant.v1beta1.BasicAllowance",
+			[]int{0, 2, 4},
+		},
+		"type and min remaining, including unlimited allowances": {
+			"type=/cosmos.feegrant.v1beta1.BasicAllowance min_remaining=50atom",
+			[]int{0, 4},
+		},
+		"min remaining of a periodic allowance": {
+			fmt.Sprintf("granter=%s min_remaining=10atom", addrs[0]),
+			[]int{0, 1},
+		},
+		"expiring before": {
+			"expiring_before=" + now.Add(36*time.Hour).Format(time.RFC3339),
+			[]int{0},
+		},
+		"expiring before, wrapped allowance": {
+			"type=/cosmos.feegrant.v1beta1.AllowedMsgAllowance expiring_before=" + now.Add(72*time.Hour).Format(time.RFC3339),
+			[]int{3},
+		},
+		"grantee and expiring before": {
+			fmt.Sprintf("grantee=%s expiring_before=%s", addrs[1], now.Add(72*time.Hour).Format(time.RFC3339)),
+			[]int{0},
+		},
+		"no match": {
+			fmt.Sprintf("granter=%s min_remaining=10atom", addrs[1]),
+			nil,
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		suite.Run(name, func() {
+			resp, err := suite.queryClient.AllowancesByFilter(gocontext.Background(), &types.QueryAllowancesByFilterRequest{Filter: tc.filter})
+			suite.Require().NoError(err)
+
+			var expPairs, pairs []string
+			for _, i := range tc.expGrants {
+				expPairs = append(expPairs, grants[i].granter.String()+"/"+grants[i].grantee.String())
+			}
+			for _, grant := range resp.Allowances {
+				pairs = append(pairs, grant.Granter+"/"+grant.Grantee)
+			}
+			suite.Require().ElementsMatch(expPairs, pairs)
+		})
+	}
+
+	// matching grants are paginated, and counted
+	req := &types.QueryAllowancesByFilterRequest{Filter: "type=/cosmos.feegrant.v1beta1.BasicAllowance"}
+	resp, err := suite.queryClient.AllowancesByFilter(gocontext.Background(), req)
+	suite.Require().NoError(err)
+	suite.Require().Equal(uint64(3), resp.Pagination.Total)
+
+	req.Pagination = &query.PageRequest{Limit: 2}
+	resp, err = suite.queryClient.AllowancesByFilter(gocontext.Background(), req)
+	suite.Require().NoError(err)
+	suite.Require().Len(resp.Allowances, 2)
+
+	req.Pagination = &query.PageRequest{Key: resp.Pagination.NextKey}
+	resp, err = suite.queryClient.AllowancesByFilter(gocontext.Background(), req)
+	suite.Require().NoError(err)
+	suite.Require().Len(resp.Allowances, 1)
+
+	for _, filter := range []string{
+		"granter",
+		"fraction=1",
+		fmt.Sprintf("granter=%s granter=%s", addrs[0], addrs[1]),
+		"grantee=cosmos1invalid",
+		"min_remaining=-1atom",
+		"expiring_before=tomorrow",
+	} {
+		_, err := suite.queryClient.AllowancesByFilter(gocontext.Background(), &types.QueryAllowancesByFilterRequest{Filter: filter})
+		suite.Require().Equal(codes.InvalidArgument, status.Code(err), filter)
+	}
+}
+
+func (suite *KeeperTestSuite) TestSponsorshipRejection() {
+	k := suite.app.FeeGrantKeeper
+	granter, grantee := suite.addrs[0], suite.addrs[1]
+	req := &types.QuerySponsorshipRejectionRequest{Granter: granter.String(), Grantee: grantee.String()}
+
+	_, err := suite.queryClient.SponsorshipRejection(gocontext.Background(), req)
+	suite.Require().Equal(codes.NotFound, status.Code(err))
+
+	suite.Require().NoError(k.GrantFeeAllowance(suite.sdkCtx, granter, grantee, &types.BasicAllowance{
+		SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 10)),
+	}))
+	ctx := suite.sdkCtx.WithBlockHeight(7)
+	_, _, err = k.UseGrantedFees(ctx, granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("atom", 20)), nil)
+	suite.Require().Error(err)
+	k.RecordRejection(ctx, granter, grantee, err, true)
+
+	resp, err := suite.queryClient.SponsorshipRejection(gocontext.Background(), req)
+	suite.Require().NoError(err)
+	suite.Require().Equal(&types.SponsorshipRejection{
+		Reason:    types.RejectionExhausted,
+		Log:       "granter " + granter.String() + ", grantee " + grantee.String() + ": basic allowance: fee limit exceeded",
+		Height:    7,
+		Simulated: true,
+	}, resp.Rejection)
+
+	// the latest rejection replaces the previous one
+	k.RecordRejection(ctx.WithBlockHeight(8), granter, grantee, types.ErrFeeLimitExpired, false)
+	resp, err = suite.queryClient.SponsorshipRejection(gocontext.Background(), req)
+	suite.Require().NoError(err)
+	suite.Require().Equal(types.RejectionExpired, resp.Rejection.Reason)
+	suite.Require().Equal(int64(8), resp.Rejection.Height)
+
+	// only the rejections of the most recently rejected grants are kept
+	for i := 0; i < keeper.MaxRejections; i++ {
+		other := sdk.AccAddress(fmt.Sprintf("grantee-%015d", i))
+		k.RecordRejection(ctx, granter, other, types.ErrNoAllowance, false)
+	}
+	_, err = suite.queryClient.SponsorshipRejection(gocontext.Background(), req)
+	suite.Require().Equal(codes.NotFound, status.Code(err))
+
+	_, found := k.GetRejection(granter, sdk.AccAddress(fmt.Sprintf("grantee-%015d", 0)))
+	suite.Require().True(found)
+
+	_, err = suite.queryClient.SponsorshipRejection(gocontext.Background(), &types.QuerySponsorshipRejectionRequest{Granter: "invalid", Grantee: grantee.String()})
+	suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+}
+
+func (suite *KeeperTestSuite) TestGrantRaw() {
+	k := suite.app.FeeGrantKeeper
+	granter, grantee := suite.addrs[0], suite.addrs[1]
+	req := &types.QueryGrantRawRequest{Granter: granter.String(), Grantee: grantee.String()}
+
+	_, err := suite.queryClient.GrantRaw(gocontext.Background(), req)
+	suite.Require().Equal(codes.NotFound, status.Code(err))
+
+	_, err = suite.queryClient.GrantRaw(gocontext.Background(), &types.QueryGrantRawRequest{Granter: "invalid", Grantee: grantee.String()})
+	suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+
+	basic := &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 100))}
+	allowance, err := types.NewAllowedMsgAllowance(basic, []string{"/cosmos.gov.v1beta1.MsgVote"})
+	suite.Require().NoError(err)
+	suite.Require().NoError(k.GrantFeeAllowance(suite.sdkCtx, granter, grantee, allowance))
+
+	stored := suite.sdkCtx.KVStore(suite.app.GetKey(types.StoreKey)).Get(types.FeeAllowanceKey(granter, grantee))
+	var expected codectypes.Any
+	suite.Require().NoError(suite.app.AppCodec().UnmarshalBinaryBare(stored, &expected))
+	suite.Require().Equal("/cosmos.feegrant.v1beta1.AllowedMsgAllowance", expected.TypeUrl)
+
+	resp, err := suite.queryClient.GrantRaw(gocontext.Background(), req)
+	suite.Require().NoError(err)
+	suite.Require().Equal(expected.TypeUrl, resp.Allowance.TypeUrl)
+	suite.Require().Equal(expected.Value, resp.Allowance.Value)
+
+	// the keeper returns the same Any, which encodes to the stored bytes
+	any, err := k.GetFeeGrantAny(suite.sdkCtx, granter, grantee)
+	suite.Require().NoError(err)
+	bz, err := suite.app.AppCodec().MarshalBinaryBare(any)
+	suite.Require().NoError(err)
+	suite.Require().Equal(stored, bz)
+
+	any, err = k.GetFeeGrantAny(suite.sdkCtx, grantee, granter)
+	suite.Require().NoError(err)
+	suite.Require().Nil(any)
+}
+
+func (suite *KeeperTestSuite) TestGranterExposure() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+	granter := suite.addrs[0]
+	req := &types.QueryGranterExposureRequest{Granter: granter.String()}
+
+	resp, err := suite.queryClient.GranterExposure(gocontext.Background(), req)
+	suite.Require().NoError(err)
+	suite.Require().Empty(resp.Exposure)
+	suite.Require().Zero(resp.UnlimitedGrants)
+
+	_, err = suite.queryClient.GranterExposure(gocontext.Background(), &types.QueryGranterExposureRequest{Granter: "invalid"})
+	suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+
+	basic := &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 100))}
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, suite.addrs[1], basic))
+
+	// the whole spend limit of a periodic allowance counts, not its period limit
+	periodic := &types.PeriodicFeeAllowance{
+		Basic:            types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 500), sdk.NewInt64Coin("eth", 50))},
+		Period:           time.Hour,
+		PeriodSpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 10)),
+		PeriodCanSpend:   sdk.NewCoins(sdk.NewInt64Coin("atom", 10)),
+		PeriodReset:      ctx.BlockTime().Add(time.Hour),
+	}
+	filtered, err := types.NewAllowedMsgAllowance(periodic, []string{"/cosmos.gov.v1beta1.MsgVote"})
+	suite.Require().NoError(err)
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, suite.addrs[2], filtered))
+
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, suite.addrs[3], &types.BasicAllowance{}))
+
+	// grants given by other granters do not count
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[1], suite.addrs[2], basic))
+
+	resp, err = suite.queryClient.GranterExposure(gocontext.Background(), req)
+	suite.Require().NoError(err)
+	suite.Require().Equal(sdk.NewCoins(sdk.NewInt64Coin("atom", 600), sdk.NewInt64Coin("eth", 50)), resp.Exposure)
+	suite.Require().Equal(uint64(1), resp.UnlimitedGrants)
+
+	// spending lowers the exposure, and expired grants no longer count
+	_, _, err = k.UseGrantedFees(ctx, granter, suite.addrs[1], sdk.NewCoins(sdk.NewInt64Coin("atom", 30)), nil)
+	suite.Require().NoError(err)
+
+	expired := ctx.BlockTime().Add(-time.Minute)
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, suite.addrs[3], &types.BasicAllowance{
+		SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 1000)),
+		Expiration: &expired,
+	}))
+
+	exposure, unlimited, err := k.GetGranterExposure(ctx, granter)
+	suite.Require().NoError(err)
+	suite.Require().Equal(sdk.NewCoins(sdk.NewInt64Coin("atom", 570), sdk.NewInt64Coin("eth", 50)), exposure)
+	suite.Require().Zero(unlimited)
+
+	// grants expiring at a height count up to that height, wrapped or not
+	byHeight, err := types.NewAllowedDenomAllowance(&types.BasicAllowance{
+		SpendLimit:       sdk.NewCoins(sdk.NewInt64Coin("atom", 2000)),
+		ExpirationHeight: 10,
+	}, []string{"atom"})
+	suite.Require().NoError(err)
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, suite.addrs[3], byHeight))
+
+	exposure, _, err = k.GetGranterExposure(ctx.WithBlockHeight(10), granter)
+	suite.Require().NoError(err)
+	suite.Require().Equal(sdk.NewCoins(sdk.NewInt64Coin("atom", 2570), sdk.NewInt64Coin("eth", 50)), exposure)
+
+	exposure, _, err = k.GetGranterExposure(ctx.WithBlockHeight(11), granter)
+	suite.Require().NoError(err)
+	suite.Require().Equal(sdk.NewCoins(sdk.NewInt64Coin("atom", 570), sdk.NewInt64Coin("eth", 50)), exposure)
+}
+
+func (suite *KeeperTestSuite) TestAllowanceTyped() {
+	k := suite.app.FeeGrantKeeper
+	granter, grantee := suite.addrs[0], suite.addrs[1]
+	req := &types.QueryAllowanceTypedRequest{Granter: granter.String(), Grantee: grantee.String()}
+
+	_, err := suite.queryClient.AllowanceTyped(gocontext.Background(), req)
+	suite.Require().Equal(codes.NotFound, status.Code(err))
+
+	_, err = suite.queryClient.AllowanceTyped(gocontext.Background(), &types.QueryAllowanceTypedRequest{Granter: granter.String(), Grantee: "invalid"})
+	suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+
+	limit := sdk.NewCoins(sdk.NewInt64Coin("atom", 100))
+	allowance, err := types.NewAllowedDenomAllowance(&types.BasicAllowance{SpendLimit: limit}, []string{"atom"})
+	suite.Require().NoError(err)
+	suite.Require().NoError(k.GrantFeeAllowance(suite.sdkCtx, granter, grantee, allowance))
+
+	resp, err := suite.queryClient.AllowanceTyped(gocontext.Background(), req)
+	suite.Require().NoError(err)
+	suite.Require().Equal(&types.AllowanceBreakdown{
+		Type:          "/cosmos.feegrant.v1beta1.AllowedDenomAllowance",
+		AllowedDenoms: []string{"atom"},
+		Allowance: &types.AllowanceBreakdown{
+			Type:       "/cosmos.feegrant.v1beta1.BasicAllowance",
+			SpendLimit: limit,
+		},
+	}, resp.Allowance)
+}
+
+func (suite *KeeperTestSuite) TestQueryParams() {
+	res, err := suite.queryClient.Params(gocontext.Background(), &types.QueryParamsRequest{})
+	suite.Require().NoError(err)
+	suite.Require().Equal(types.DefaultParams(), res.Params)
+
+	params := types.NewParams(types.DefaultParams().MaxGrantsPerGranter+7, false, time.Hour)
+	suite.Require().NotEqual(types.DefaultParams(), params)
+	suite.Require().NoError(feegrant.InitGenesis(suite.sdkCtx, suite.app.FeeGrantKeeper, types.NewGenesisState(params, nil, nil)))
+
+	res, err = suite.queryClient.Params(gocontext.Background(), &types.QueryParamsRequest{})
+	suite.Require().NoError(err)
+	suite.Require().Equal(params, res.Params)
+}
+
+func (suite *KeeperTestSuite) TestAllowancesExpiringBefore() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+	now := ctx.BlockTime()
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	expiringIn := func(d time.Duration) *types.BasicAllowance {
+		exp := now.Add(d)
+		return &types.BasicAllowance{SpendLimit: atom, Expiration: &exp}
+	}
+
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[0], suite.addrs[1], expiringIn(time.Hour)))
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[0], suite.addrs[2], expiringIn(3*time.Hour)))
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[1], suite.addrs[2], expiringIn(2*time.Hour)))
+	// grants without an expiration time are never returned
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[0], suite.addrs[3], &types.BasicAllowance{SpendLimit: atom}))
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[1], suite.addrs[3], &types.BasicAllowance{SpendLimit: atom, ExpirationHeight: 10}))
+
+	// page through the grants expiring soon, the soonest first
+	var (
+		grants  [][2]string
+		nextKey []byte
+	)
+	for page := 0; page < 2; page++ {
+		resp, err := suite.queryClient.AllowancesExpiringBefore(gocontext.Background(), &types.QueryAllowancesExpiringBeforeRequest{
+			Time:       now.Add(150 * time.Minute),
+			Pagination: &query.PageRequest{Key: nextKey, Limit: 1, CountTotal: page == 0},
+		})
+		suite.Require().NoError(err)
+		suite.Require().Len(resp.Allowances, 1)
+		if page == 0 {
+			suite.Require().Equal(uint64(2), resp.Pagination.Total)
+		}
+
+		for _, grant := range resp.Allowances {
+			grants = append(grants, [2]string{grant.Granter, grant.Grantee})
+		}
+		nextKey = resp.Pagination.NextKey
+	}
+	suite.Require().Nil(nextKey)
+	suite.Require().Equal([][2]string{
+		{suite.addrs[0].String(), suite.addrs[1].String()},
+		{suite.addrs[1].String(), suite.addrs[2].String()},
+	}, grants)
+
+	// a grant expiring at the requested time is not returned
+	resp, err := suite.queryClient.AllowancesExpiringBefore(gocontext.Background(), &types.QueryAllowancesExpiringBeforeRequest{Time: now.Add(2 * time.Hour)})
+	suite.Require().NoError(err)
+	suite.Require().Len(resp.Allowances, 1)
+	suite.Require().Equal(suite.addrs[1].String(), resp.Allowances[0].Grantee)
+	allowance, err := resp.Allowances[0].GetFeeGrant()
+	suite.Require().NoError(err)
+	suite.Require().Equal(expiringIn(time.Hour), allowance)
+
+	resp, err = suite.queryClient.AllowancesExpiringBefore(gocontext.Background(), &types.QueryAllowancesExpiringBeforeRequest{Time: now})
+	suite.Require().NoError(err)
+	suite.Require().Empty(resp.Allowances)
+}
+
+func (suite *KeeperTestSuite) TestAllowancesStream() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+
+	for _, granter := range suite.addrs {
+		for _, grantee := range suite.addrs {
+			if !granter.Equals(grantee) {
+				suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, grantee, &types.BasicAllowance{SpendLimit: atom}))
+			}
+		}
+	}
+
+	var expected []types.FeeAllowanceGrant
+	suite.Require().NoError(k.IterateAllFeeAllowances(ctx, func(grant types.FeeAllowanceGrant) bool {
+		expected = append(expected, grant)
+		return false
+	}))
+	suite.Require().Len(expected, 12)
+
+	readStream := func(goCtx gocontext.Context, req *types.QueryAllowancesStreamRequest) ([]types.FeeAllowanceGrant, []int, error) {
+		stream, err := suite.queryClient.AllowancesStream(goCtx, req)
+		suite.Require().NoError(err)
+
+		var (
+			grants  []types.FeeAllowanceGrant
+			batches []int
+		)
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				return grants, batches, nil
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+
+			batches = append(batches, len(resp.Allowances))
+			for _, grant := range resp.Allowances {
+				grants = append(grants, *grant)
+			}
+		}
+	}
+
+	grants, batches, err := readStream(gocontext.Background(), &types.QueryAllowancesStreamRequest{BatchSize: 5})
+	suite.Require().NoError(err)
+	suite.Require().Equal([]int{5, 5, 2}, batches)
+	suite.Require().Equal(expected, grants)
+
+	// a single batch by default
+	grants, batches, err = readStream(gocontext.Background(), &types.QueryAllowancesStreamRequest{})
+	suite.Require().NoError(err)
+	suite.Require().Equal([]int{12}, batches)
+	suite.Require().Equal(expected, grants)
+
+	// the grants given by a granter
+	granter := suite.addrs[2]
+	grants, _, err = readStream(gocontext.Background(), &types.QueryAllowancesStreamRequest{Granter: granter.String(), BatchSize: 2})
+	suite.Require().NoError(err)
+	suite.Require().Len(grants, 3)
+	for _, grant := range grants {
+		suite.Require().Equal(granter.String(), grant.Granter)
+	}
+
+	_, _, err = readStream(gocontext.Background(), &types.QueryAllowancesStreamRequest{Granter: "invalid_granter"})
+	suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+
+	// nothing is sent once the stream is canceled
+	canceled, cancel := gocontext.WithCancel(gocontext.Background())
+	cancel()
+	grants, _, err = readStream(canceled, &types.QueryAllowancesStreamRequest{BatchSize: 5})
+	suite.Require().Equal(codes.Canceled, status.Code(err))
+	suite.Require().Empty(grants)
+}
@@ -0,0 +1,39 @@
+package keeper
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+// Implements FeegrantHooks interface
+var _ types.FeegrantHooks = Keeper{}
+
+// AfterGrant - call hook if registered
+func (k Keeper) AfterGrant(ctx sdk.Context, granter, grantee sdk.AccAddress, allowance types.FeeAllowanceI) {
+	if k.hooks != nil {
+		k.hooks.AfterGrant(ctx, granter, grantee, allowance)
+	}
+}
+
+// AfterRevoke - call hook if registered
+func (k Keeper) AfterRevoke(ctx sdk.Context, granter, grantee sdk.AccAddress) {
+	if k.hooks != nil {
+		k.hooks.AfterRevoke(ctx, granter, grantee)
+	}
+}
+
+// AfterUseAllowance - call hook if registered
+func (k Keeper) AfterUseAllowance(ctx sdk.Context, granter, grantee sdk.AccAddress, fee sdk.Coins) {
+	if k.hooks != nil {
+		k.hooks.AfterUseAllowance(ctx, granter, grantee, fee)
+	}
+}
+
+// BeforeAllowanceExpires - call hook if registered
+func (k Keeper) BeforeAllowanceExpires(ctx sdk.Context, granter, grantee sdk.AccAddress, expiresAt time.Time) {
+	if k.hooks != nil {
+		k.hooks.BeforeAllowanceExpires(ctx, granter, grantee, expiresAt)
+	}
+}
@@ -0,0 +1,49 @@
+package keeper
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+// RegisterInvariants registers the feegrant module invariants
+func RegisterInvariants(ir sdk.InvariantRegistry, k Keeper) {
+	ir.RegisterRoute(types.ModuleName, "valid-allowances", ValidAllowancesInvariant(k))
+}
+
+// ValidAllowancesInvariant checks that every stored grant holds an allowance
+// which can be decoded and passes its ValidateBasic.
+func ValidAllowancesInvariant(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		var (
+			msg   string
+			count int
+		)
+
+		store := ctx.KVStore(k.storeKey)
+		iter := sdk.KVStorePrefixIterator(store, types.FeeAllowanceKeyPrefix)
+		defer iter.Close()
+
+		for ; iter.Valid(); iter.Next() {
+			granter, grantee := types.ParseAddressesFromFeeAllowanceKey(iter.Key())
+
+			feeAllowance, err := k.UnmarshalFeeAllowance(iter.Value())
+			if err == nil {
+				err = feeAllowance.ValidateBasic()
+			}
+
+			if err != nil {
+				count++
+				msg += fmt.Sprintf("\tinvalid allowance from granter %s to grantee %s: %s\n", granter, grantee, err)
+			}
+		}
+
+		broken := count != 0
+
+		return sdk.FormatInvariant(
+			types.ModuleName, "valid-allowances",
+			fmt.Sprintf("amount of invalid allowances found %d\n%s", count, msg),
+		), broken
+	}
+}
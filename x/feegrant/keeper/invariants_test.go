@@ -0,0 +1,34 @@
+package keeper_test
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/keeper"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+func (suite *KeeperTestSuite) TestValidAllowancesInvariant() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+	invariant := keeper.ValidAllowancesInvariant(k)
+
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[0], suite.addrs[1], &types.BasicAllowance{SpendLimit: atom}))
+
+	_, broken := invariant(ctx)
+	suite.Require().False(broken)
+
+	// an allowance which decodes but fails ValidateBasic
+	negative := sdk.Coins{sdk.Coin{Denom: "atom", Amount: sdk.NewInt(-1)}}
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[0], suite.addrs[2], &types.BasicAllowance{SpendLimit: negative}))
+
+	// an allowance which cannot be decoded at all
+	store := ctx.KVStore(suite.app.GetKey(types.StoreKey))
+	store.Set(types.FeeAllowanceKey(suite.addrs[0], suite.addrs[3]), []byte("garbage"))
+
+	msg, broken := invariant(ctx)
+	suite.Require().True(broken)
+	suite.Require().Contains(msg, "amount of invalid allowances found 2")
+	suite.Require().Contains(msg, suite.addrs[2].String())
+	suite.Require().Contains(msg, suite.addrs[3].String())
+	suite.Require().NotContains(msg, suite.addrs[1].String())
+}
@@ -0,0 +1,817 @@
+package keeper
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/tendermint/tendermint/libs/log"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+	paramtypes "github.com/cosmos/cosmos-sdk/x/params/types"
+)
+
+// Keeper manages state of all fee grants, as well as calculating approval.
+// It must have a codec with all available allowances registered.
+type Keeper struct {
+	cdc        codec.BinaryMarshaler
+	storeKey   sdk.StoreKey
+	paramSpace paramtypes.Subspace
+	authKeeper types.AccountKeeper
+	hooks      types.FeegrantHooks
+
+	// cache is shared by all the copies of the keeper
+	cache *allowanceCache
+	// rejections is shared by all the copies of the keeper
+	rejections *rejectionLog
+}
+
+// NewKeeper creates a fee grant Keeper
+func NewKeeper(cdc codec.BinaryMarshaler, storeKey sdk.StoreKey, paramSpace paramtypes.Subspace, ak types.AccountKeeper) Keeper {
+	// set KeyTable if it has not already been set
+	if !paramSpace.HasKeyTable() {
+		paramSpace = paramSpace.WithKeyTable(types.ParamKeyTable())
+	}
+
+	return Keeper{
+		cdc:        cdc,
+		storeKey:   storeKey,
+		paramSpace: paramSpace,
+		authKeeper: ak,
+		cache:      newAllowanceCache(),
+		rejections: newRejectionLog(),
+	}
+}
+
+// SetHooks sets the feegrant hooks
+func (k *Keeper) SetHooks(fh types.FeegrantHooks) *Keeper {
+	if k.hooks != nil {
+		panic("cannot set feegrant hooks twice")
+	}
+
+	k.hooks = fh
+
+	return k
+}
+
+// Logger returns a module-specific logger.
+func (k Keeper) Logger(ctx sdk.Context) log.Logger {
+	return ctx.Logger().With("module", fmt.Sprintf("x/%s", types.ModuleName))
+}
+
+// GrantFeeAllowance creates a new grant, or replaces an existing one. A new
+// grant fails with ErrTooManyGrants if the granter already has the maximum
+// number of grants allowed by the MaxGrantsPerGranter param. The fees already
+// spent out of a replaced grant are kept.
+func (k Keeper) GrantFeeAllowance(ctx sdk.Context, granter, grantee sdk.AccAddress, feeAllowance types.FeeAllowanceI) error {
+	store := ctx.KVStore(k.storeKey)
+	if !store.Has(types.FeeAllowanceKey(granter, grantee)) {
+		max := k.GetParams(ctx).MaxGrantsPerGranter
+		if k.countGrantsByGranter(ctx, granter, max) >= max {
+			return sdkerrors.Wrapf(types.ErrTooManyGrants, "granter %s has %d grants", granter, max)
+		}
+	}
+
+	if _, err := k.setFeeAllowance(ctx, granter, grantee, feeAllowance); err != nil {
+		return err
+	}
+
+	limit, err := feeAllowance.RemainingSpendLimit()
+	if err != nil {
+		return err
+	}
+
+	spending, _ := k.GetAllowanceSpending(ctx, granter, grantee)
+	if spending.Since.IsZero() {
+		spending.Since = ctx.BlockTime()
+	}
+	spending.OriginalLimit = limit
+	k.SetAllowanceSpending(ctx, granter, grantee, spending)
+
+	k.AfterGrant(ctx, granter, grantee, feeAllowance)
+
+	return nil
+}
+
+// setFeeAllowance stores the allowance of the grant from granter to grantee,
+// and returns its encoding.
+func (k Keeper) setFeeAllowance(ctx sdk.Context, granter, grantee sdk.AccAddress, feeAllowance types.FeeAllowanceI) ([]byte, error) {
+	bz, err := k.MarshalFeeAllowance(feeAllowance)
+	if err != nil {
+		return nil, err
+	}
+
+	exp, err := feeAllowance.ExpiresAt()
+	if err != nil {
+		return nil, err
+	}
+
+	expHeight, err := feeAllowance.ExpiresAtHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	// drop any expiration queue entry of the allowance being replaced
+	if err := k.removeFromFeeAllowanceQueue(ctx, granter, grantee, exp); err != nil {
+		return nil, err
+	}
+
+	store := ctx.KVStore(k.storeKey)
+	key := types.FeeAllowanceKey(granter, grantee)
+	if !store.Has(key) {
+		k.setGrantsCount(ctx, k.GetGrantsCount(ctx)+1)
+	}
+
+	store.Set(key, bz)
+	k.cache.remove(ctx, key)
+	store.Set(types.FeeAllowanceByGranterKey(granter, grantee), []byte{})
+
+	if exp != nil {
+		store.Set(types.FeeAllowanceQueueKey(*exp, granter, grantee), []byte{})
+	}
+	if expHeight > 0 {
+		store.Set(types.FeeAllowanceHeightQueueKey(expHeight, granter, grantee), []byte{})
+	}
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeSetFeeGrant,
+			sdk.NewAttribute(types.AttributeKeyGranter, granter.String()),
+			sdk.NewAttribute(types.AttributeKeyGrantee, grantee.String()),
+		),
+	)
+
+	return bz, nil
+}
+
+// UpdateFeeAllowance replaces the allowance of an existing grant. It fails if
+// there is no grant from granter to grantee.
+func (k Keeper) UpdateFeeAllowance(ctx sdk.Context, granter, grantee sdk.AccAddress, feeAllowance types.FeeAllowanceI) error {
+	store := ctx.KVStore(k.storeKey)
+	if !store.Has(types.FeeAllowanceKey(granter, grantee)) {
+		return sdkerrors.Wrapf(types.ErrNoAllowance, "granter %s, grantee %s", granter, grantee)
+	}
+
+	return k.GrantFeeAllowance(ctx, granter, grantee, feeAllowance)
+}
+
+// RevokeFeeAllowance removes an existing grant
+func (k Keeper) RevokeFeeAllowance(ctx sdk.Context, granter, grantee sdk.AccAddress) error {
+	if err := k.removeFeeAllowance(ctx, granter, grantee); err != nil {
+		return err
+	}
+
+	k.AfterRevoke(ctx, granter, grantee)
+
+	return nil
+}
+
+// TransferAllowance moves the grant from granter to oldGrantee to newGrantee,
+// keeping the allowance and its state as they are. It fails with
+// ErrNoAllowance if there is no grant to oldGrantee, and with
+// ErrFeeAllowanceExists if newGrantee already has a grant from granter.
+func (k Keeper) TransferAllowance(ctx sdk.Context, granter, oldGrantee, newGrantee sdk.AccAddress) error {
+	if newGrantee.Equals(granter) {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, "cannot self-grant fee authorization")
+	}
+
+	feeAllowance, err := k.GetFeeAllowance(ctx, granter, oldGrantee)
+	if err != nil {
+		return err
+	}
+
+	if feeAllowance == nil {
+		return sdkerrors.Wrapf(types.ErrNoAllowance, "granter %s, grantee %s", granter, oldGrantee)
+	}
+
+	store := ctx.KVStore(k.storeKey)
+	if store.Has(types.FeeAllowanceKey(granter, newGrantee)) {
+		return sdkerrors.Wrapf(types.ErrFeeAllowanceExists, "granter %s, grantee %s", granter, newGrantee)
+	}
+
+	spending, found := k.GetAllowanceSpending(ctx, granter, oldGrantee)
+
+	if err := k.removeFeeAllowance(ctx, granter, oldGrantee); err != nil {
+		return err
+	}
+
+	if _, err := k.setFeeAllowance(ctx, granter, newGrantee, feeAllowance); err != nil {
+		return err
+	}
+
+	if found {
+		k.SetAllowanceSpending(ctx, granter, newGrantee, spending)
+	}
+
+	k.AfterRevoke(ctx, granter, oldGrantee)
+	k.AfterGrant(ctx, granter, newGrantee, feeAllowance)
+
+	return nil
+}
+
+// ExtendExpiration pushes the expiration time of the grant from granter to
+// grantee out to newExpiration, keeping the rest of its allowance as it is. It
+// fails with ErrNoAllowance if there is no such grant, and rejects grants
+// without an expiration time as well as a newExpiration before the current one.
+func (k Keeper) ExtendExpiration(ctx sdk.Context, granter, grantee sdk.AccAddress, newExpiration time.Time) error {
+	feeAllowance, err := k.GetFeeAllowance(ctx, granter, grantee)
+	if err != nil {
+		return err
+	}
+
+	if feeAllowance == nil {
+		return sdkerrors.Wrapf(types.ErrNoAllowance, "granter %s, grantee %s", granter, grantee)
+	}
+
+	exp, err := feeAllowance.ExpiresAt()
+	if err != nil {
+		return err
+	}
+
+	if exp == nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "allowance has no expiration time to extend")
+	}
+
+	if newExpiration.Before(*exp) {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "new expiration %s is before the current expiration %s", newExpiration, exp)
+	}
+
+	if err := types.SetExpiration(feeAllowance, newExpiration); err != nil {
+		return err
+	}
+
+	_, err = k.setFeeAllowance(ctx, granter, grantee, feeAllowance)
+	return err
+}
+
+// RevokeAllowancesByGranter removes all grants from granter and returns how
+// many were removed.
+func (k Keeper) RevokeAllowancesByGranter(ctx sdk.Context, granter sdk.AccAddress) (uint64, error) {
+	var grantees []sdk.AccAddress
+
+	store := ctx.KVStore(k.storeKey)
+	prefixBz := types.FeeAllowancePrefixByGranter(granter)
+	iter := sdk.KVStorePrefixIterator(store, prefixBz)
+	for ; iter.Valid(); iter.Next() {
+		// the remaining key is the grantee address
+		grantees = append(grantees, types.ParseLengthPrefixedAddress(iter.Key()[len(prefixBz):]))
+	}
+	iter.Close()
+
+	for _, grantee := range grantees {
+		if err := k.RevokeFeeAllowance(ctx, granter, grantee); err != nil {
+			return 0, err
+		}
+	}
+
+	return uint64(len(grantees)), nil
+}
+
+// GetGranterExposure returns the sum of the spend limits left on the grants given by granter, which is
+// the most their grantees can still spend out of them. A periodic allowance counts its whole spend
+// limit, not only what is left in its current period. Grants without a spend limit cannot be summed
+// and are only counted in unlimited. Expired grants, which are not pruned yet, are skipped.
+func (k Keeper) GetGranterExposure(ctx sdk.Context, granter sdk.AccAddress) (sdk.Coins, uint64, error) {
+	var (
+		exposure  = sdk.Coins{}
+		unlimited uint64
+		err       error
+	)
+	iterErr := k.iterateFeeAllowancesByGranter(ctx, granter, func(grant types.FeeAllowanceGrant) bool {
+		var allowance types.FeeAllowanceI
+		if allowance, err = grant.GetFeeGrant(); err != nil {
+			return true
+		}
+
+		var expired bool
+		if expired, err = types.IsExpired(ctx, allowance); err != nil {
+			return true
+		}
+		if expired {
+			return false
+		}
+
+		var limit sdk.Coins
+		if limit, err = allowance.RemainingSpendLimit(); err != nil {
+			return true
+		}
+
+		if limit.Empty() {
+			unlimited++
+		} else {
+			exposure = exposure.Add(limit...)
+		}
+		return false
+	})
+	if iterErr != nil {
+		return nil, 0, iterErr
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return exposure, unlimited, nil
+}
+
+// removeFeeAllowance deletes the grant from granter to grantee.
+func (k Keeper) removeFeeAllowance(ctx sdk.Context, granter, grantee sdk.AccAddress) error {
+	store := ctx.KVStore(k.storeKey)
+	key := types.FeeAllowanceKey(granter, grantee)
+
+	if !store.Has(key) {
+		return sdkerrors.Wrapf(types.ErrNoAllowance, "granter %s, grantee %s", granter, grantee)
+	}
+
+	if err := k.removeFromFeeAllowanceQueue(ctx, granter, grantee, nil); err != nil {
+		return err
+	}
+
+	store.Delete(key)
+	k.cache.remove(ctx, key)
+	store.Delete(types.FeeAllowanceByGranterKey(granter, grantee))
+	store.Delete(types.AllowanceSpendingKey(granter, grantee))
+	k.setGrantsCount(ctx, k.GetGrantsCount(ctx)-1)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeRevokeFeeGrant,
+			sdk.NewAttribute(types.AttributeKeyGranter, granter.String()),
+			sdk.NewAttribute(types.AttributeKeyGrantee, grantee.String()),
+		),
+	)
+
+	return nil
+}
+
+// RemoveExpiredAllowances deletes all grants which expired before the current
+// block time or height, along with their expiration queue entries.
+func (k Keeper) RemoveExpiredAllowances(ctx sdk.Context) {
+	store := ctx.KVStore(k.storeKey)
+	iter := store.Iterator(types.FeeAllowanceQueueKeyPrefix, types.FeeAllowanceByQueueKey(ctx.BlockTime()))
+	defer iter.Close()
+
+	for ; iter.Valid(); iter.Next() {
+		granter, grantee := types.ParseAddressesFromFeeAllowanceQueueKey(iter.Key())
+
+		store.Delete(iter.Key())
+		store.Delete(types.ExpiryWarningKey(iter.Key()))
+		k.pruneFeeAllowance(ctx, granter, grantee)
+	}
+
+	// an allowance can still be used at its expiration height
+	heightIter := store.Iterator(types.FeeAllowanceHeightQueueKeyPrefix, types.FeeAllowanceByHeightQueueKey(ctx.BlockHeight()))
+	defer heightIter.Close()
+
+	for ; heightIter.Valid(); heightIter.Next() {
+		granter, grantee := types.ParseAddressesFromFeeAllowanceHeightQueueKey(heightIter.Key())
+
+		store.Delete(heightIter.Key())
+		k.pruneFeeAllowance(ctx, granter, grantee)
+	}
+}
+
+// pruneFeeAllowance deletes the expired grant from granter to grantee, whose
+// expiration queue entry is deleted by the caller.
+func (k Keeper) pruneFeeAllowance(ctx sdk.Context, granter, grantee sdk.AccAddress) {
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.FeeAllowanceKey(granter, grantee))
+	k.cache.remove(ctx, types.FeeAllowanceKey(granter, grantee))
+	store.Delete(types.FeeAllowanceByGranterKey(granter, grantee))
+	store.Delete(types.AllowanceSpendingKey(granter, grantee))
+	k.setGrantsCount(ctx, k.GetGrantsCount(ctx)-1)
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypePruneFeeGrant,
+			sdk.NewAttribute(types.AttributeKeyGranter, granter.String()),
+			sdk.NewAttribute(types.AttributeKeyGrantee, grantee.String()),
+		),
+	)
+}
+
+// WarnExpiringAllowances calls the BeforeAllowanceExpires hook for the grants
+// expiring within the ExpiryWarningWindow param after the current block time,
+// once per grant and expiration time. The grants already warned about are not
+// part of the genesis state, so they may be warned about again after an export.
+func (k Keeper) WarnExpiringAllowances(ctx sdk.Context) {
+	window := k.GetParams(ctx).ExpiryWarningWindow
+	if window <= 0 {
+		return
+	}
+
+	// the hooks may update grants, so the queue is not iterated while they run
+	var queueKeys [][]byte
+
+	store := ctx.KVStore(k.storeKey)
+	iter := store.Iterator(types.FeeAllowanceByQueueKey(ctx.BlockTime()), types.FeeAllowanceByQueueKey(ctx.BlockTime().Add(window)))
+	for ; iter.Valid(); iter.Next() {
+		if !store.Has(types.ExpiryWarningKey(iter.Key())) {
+			queueKeys = append(queueKeys, iter.Key())
+		}
+	}
+	iter.Close()
+
+	for _, queueKey := range queueKeys {
+		granter, grantee := types.ParseAddressesFromFeeAllowanceQueueKey(queueKey)
+		store.Set(types.ExpiryWarningKey(queueKey), []byte{})
+		k.BeforeAllowanceExpires(ctx, granter, grantee, types.ParseExpirationFromFeeAllowanceQueueKey(queueKey))
+	}
+}
+
+// GetGrantsCount returns the number of grants in the store.
+func (k Keeper) GetGrantsCount(ctx sdk.Context) uint64 {
+	bz := ctx.KVStore(k.storeKey).Get(types.GrantsCountKey)
+	if bz == nil {
+		return 0
+	}
+
+	return sdk.BigEndianToUint64(bz)
+}
+
+// setGrantsCount stores the number of grants in the store.
+func (k Keeper) setGrantsCount(ctx sdk.Context, count uint64) {
+	ctx.KVStore(k.storeKey).Set(types.GrantsCountKey, sdk.Uint64ToBigEndian(count))
+}
+
+// GetAllowanceSpending returns the spending record of the grant from granter to
+// grantee, and false if there is none.
+func (k Keeper) GetAllowanceSpending(ctx sdk.Context, granter, grantee sdk.AccAddress) (types.AllowanceSpending, bool) {
+	var spending types.AllowanceSpending
+
+	bz := ctx.KVStore(k.storeKey).Get(types.AllowanceSpendingKey(granter, grantee))
+	if bz == nil {
+		return spending, false
+	}
+
+	k.cdc.MustUnmarshalBinaryBare(bz, &spending)
+
+	return spending, true
+}
+
+// SetAllowanceSpending stores the spending record of the grant from granter to
+// grantee, e.g. to restore it from genesis after the grant was made.
+func (k Keeper) SetAllowanceSpending(ctx sdk.Context, granter, grantee sdk.AccAddress, spending types.AllowanceSpending) {
+	bz := k.cdc.MustMarshalBinaryBare(&spending)
+	ctx.KVStore(k.storeKey).Set(types.AllowanceSpendingKey(granter, grantee), bz)
+}
+
+// countGrantsByGranter returns the number of grants from granter, counting no
+// further than max.
+func (k Keeper) countGrantsByGranter(ctx sdk.Context, granter sdk.AccAddress, max uint64) uint64 {
+	store := ctx.KVStore(k.storeKey)
+	iter := sdk.KVStorePrefixIterator(store, types.FeeAllowancePrefixByGranter(granter))
+	defer iter.Close()
+
+	var count uint64
+	for ; iter.Valid() && count < max; iter.Next() {
+		count++
+	}
+
+	return count
+}
+
+// removeFromFeeAllowanceQueue removes the expiration queue entries of the grant
+// from granter to grantee, if there are any, along with the record of its
+// expiry warning. The time queue entry is kept when the grant already expires
+// at newExp, so that updating an allowance without changing its expiration
+// does not warn again.
+func (k Keeper) removeFromFeeAllowanceQueue(ctx sdk.Context, granter, grantee sdk.AccAddress, newExp *time.Time) error {
+	feeAllowance, err := k.GetFeeAllowance(ctx, granter, grantee)
+	if err != nil || feeAllowance == nil {
+		return err
+	}
+
+	expHeight, err := feeAllowance.ExpiresAtHeight()
+	if err != nil {
+		return err
+	}
+
+	store := ctx.KVStore(k.storeKey)
+	if expHeight > 0 {
+		store.Delete(types.FeeAllowanceHeightQueueKey(expHeight, granter, grantee))
+	}
+
+	exp, err := feeAllowance.ExpiresAt()
+	if err != nil || exp == nil {
+		return err
+	}
+
+	if newExp != nil && exp.Equal(*newExp) {
+		return nil
+	}
+
+	queueKey := types.FeeAllowanceQueueKey(*exp, granter, grantee)
+	store.Delete(queueKey)
+	store.Delete(types.ExpiryWarningKey(queueKey))
+
+	return nil
+}
+
+// GetFeeAllowance returns the allowance between the granter and grantee.
+// If there is none, it returns nil, nil.
+// Returns an error on parsing issues
+func (k Keeper) GetFeeAllowance(ctx sdk.Context, granter, grantee sdk.AccAddress) (types.FeeAllowanceI, error) {
+	store := ctx.KVStore(k.storeKey)
+
+	bz := store.Get(types.FeeAllowanceKey(granter, grantee))
+	if len(bz) == 0 {
+		return nil, nil
+	}
+
+	return k.UnmarshalFeeAllowance(bz)
+}
+
+// GetFeeGrantAny returns the allowance between the granter and grantee as it
+// is stored, without unpacking it. If there is none, it returns nil, nil.
+func (k Keeper) GetFeeGrantAny(ctx sdk.Context, granter, grantee sdk.AccAddress) (*codectypes.Any, error) {
+	bz := ctx.KVStore(k.storeKey).Get(types.FeeAllowanceKey(granter, grantee))
+	if len(bz) == 0 {
+		return nil, nil
+	}
+
+	var any codectypes.Any
+	if err := k.cdc.UnmarshalBinaryBare(bz, &any); err != nil {
+		return nil, err
+	}
+
+	return &any, nil
+}
+
+// GetFeeGrant returns the grant from granter to grantee, and false if there is
+// none. It returns an error if the stored allowance cannot be unpacked.
+func (k Keeper) GetFeeGrant(ctx sdk.Context, granter, grantee sdk.AccAddress) (types.Grant, bool, error) {
+	feeAllowance, err := k.GetFeeAllowance(ctx, granter, grantee)
+	if err != nil || feeAllowance == nil {
+		return types.Grant{}, false, err
+	}
+
+	return types.Grant{
+		Granter:   granter,
+		Grantee:   grantee,
+		Allowance: feeAllowance,
+	}, true, nil
+}
+
+// ResolveFeeGranter returns the account paying the given fee on behalf of the
+// fee payer: its fee granter, if one is set and has granted an allowance to the
+// fee payer, or the fee payer otherwise. It returns an error wrapping
+// ErrNoAllowance if the fee granter has not granted the fee payer an allowance,
+// or ErrInvalidAddress if the fee granter is not a valid address. The allowance
+// is neither checked against the fee nor used, see UseGrantedFees.
+func (k Keeper) ResolveFeeGranter(ctx sdk.Context, feePayer sdk.AccAddress, fee *tx.Fee) (sdk.AccAddress, error) {
+	feeGranter, err := fee.GetFeeGranter()
+	if err != nil {
+		return nil, err
+	}
+
+	if feeGranter == nil || feeGranter.Equals(feePayer) {
+		return feePayer, nil
+	}
+
+	grant, err := k.GetFeeAllowance(ctx, feeGranter, feePayer)
+	if err != nil {
+		return nil, err
+	}
+
+	if grant == nil {
+		return nil, sdkerrors.Wrapf(types.ErrNoAllowance, "granter %s, grantee %s", feeGranter, feePayer)
+	}
+
+	return feeGranter, nil
+}
+
+// UseGrantedFees will try to pay the given fee from the granter's account as requested by the grantee.
+// The fee is normalized first, see normalizeFee, so that allowances always see canonical coins.
+// The stored allowance is updated, or deleted once it is used up, only if the allowance accepts the fee.
+// It returns the part of the fee the allowance covers, see types.CoveredFee, which is the whole fee
+// unless the allowance pays only a fraction of it; the grantee is left to pay the rest. The covered
+// part is added to the fees spent out of the grant, see GetAllowanceSpending, and reported by a
+// use_feegrant event. It also returns whether the grant was removed, e.g. because it was used up.
+// It returns an error wrapping ErrNoAllowance if there is no grant, and the allowance's own error
+// (e.g. ErrFeeLimitExceeded) if the fee or msgs are rejected.
+func (k Keeper) UseGrantedFees(ctx sdk.Context, granter, grantee sdk.AccAddress, fee sdk.Coins, msgs []sdk.Msg) (sdk.Coins, bool, error) {
+	key := types.FeeAllowanceKey(granter, grantee)
+	grant, err := k.takeFeeAllowance(ctx, key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if grant == nil {
+		return nil, false, sdkerrors.Wrapf(types.ErrNoAllowance, "granter %s, grantee %s", granter, grantee)
+	}
+
+	// grants made before spending was recorded start their record with the
+	// limit left before this fee, and from this block on
+	spending, found := k.GetAllowanceSpending(ctx, granter, grantee)
+	if !found {
+		if spending.OriginalLimit, err = grant.RemainingSpendLimit(); err != nil {
+			return nil, false, err
+		}
+	}
+	if spending.Since.IsZero() {
+		spending.Since = ctx.BlockTime()
+	}
+
+	fee = normalizeFee(fee)
+	covered, err := types.CoveredFee(grant, fee)
+	if err != nil {
+		return nil, false, err
+	}
+
+	remove, err := grant.Accept(ctx, fee, msgs)
+	if err != nil {
+		return nil, false, sdkerrors.Wrapf(err, "granter %s, grantee %s", granter, grantee)
+	}
+
+	spending.Spent = spending.Spent.Add(covered...)
+	k.SetAllowanceSpending(ctx, granter, grantee, spending)
+
+	if remove {
+		if err := k.removeFeeAllowance(ctx, granter, grantee); err != nil {
+			return nil, false, err
+		}
+	} else {
+		bz, err := k.setFeeAllowance(ctx, granter, grantee, grant)
+		if err != nil {
+			return nil, false, err
+		}
+
+		k.cache.put(ctx, key, bz, grant)
+	}
+
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeUseFeeGrant,
+			sdk.NewAttribute(types.AttributeKeyGranter, granter.String()),
+			sdk.NewAttribute(types.AttributeKeyGrantee, grantee.String()),
+			sdk.NewAttribute(types.AttributeKeyAmount, covered.String()),
+		),
+	)
+
+	k.AfterUseAllowance(ctx, granter, grantee, covered)
+
+	return covered, remove, nil
+}
+
+// CanUseGrantedFees is a dry run of UseGrantedFees: it returns the part of the fee the allowance
+// would cover, or the error UseGrantedFees would return, but it neither updates the grant nor its
+// spending record, and emits no event.
+func (k Keeper) CanUseGrantedFees(ctx sdk.Context, granter, grantee sdk.AccAddress, fee sdk.Coins, msgs []sdk.Msg) (sdk.Coins, error) {
+	grant, err := k.GetFeeAllowance(ctx, granter, grantee)
+	if err != nil {
+		return nil, err
+	}
+
+	if grant == nil {
+		return nil, sdkerrors.Wrapf(types.ErrNoAllowance, "granter %s, grantee %s", granter, grantee)
+	}
+
+	fee = normalizeFee(fee)
+	covered, err := types.CoveredFee(grant, fee)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := grant.CanAccept(ctx, fee, msgs); err != nil {
+		return nil, sdkerrors.Wrapf(err, "granter %s, grantee %s", granter, grantee)
+	}
+
+	return covered, nil
+}
+
+// takeFeeAllowance returns the allowance stored at key, or nil if there is
+// none, reusing the allowance cached for it if it is still up to date. The
+// returned allowance is owned by the caller.
+func (k Keeper) takeFeeAllowance(ctx sdk.Context, key []byte) (types.FeeAllowanceI, error) {
+	bz := ctx.KVStore(k.storeKey).Get(key)
+	if len(bz) == 0 {
+		return nil, nil
+	}
+
+	if allowance, ok := k.cache.take(ctx, key, bz); ok {
+		return allowance, nil
+	}
+
+	return k.UnmarshalFeeAllowance(bz)
+}
+
+// normalizeFee returns fee sorted by denom, without its zero coins and with the
+// coins of a same denom summed up. A canonical fee is returned unchanged.
+func normalizeFee(fee sdk.Coins) sdk.Coins {
+	normalized := sdk.Coins{}
+	for _, coin := range fee {
+		if !coin.IsZero() {
+			normalized = normalized.Add(coin)
+		}
+	}
+
+	return normalized
+}
+
+// IterateAllFeeAllowances iterates over all the grants in the store, ordered by
+// grantee and then granter address bytes.
+// Callback to get all data, returns true to stop, false to keep reading
+// Calling this without pagination is very expensive and only designed for export genesis
+func (k Keeper) IterateAllFeeAllowances(ctx sdk.Context, cb func(types.FeeAllowanceGrant) bool) error {
+	store := ctx.KVStore(k.storeKey)
+	iter := sdk.KVStorePrefixIterator(store, types.FeeAllowanceKeyPrefix)
+	defer iter.Close()
+
+	for ; iter.Valid(); iter.Next() {
+		feeAllowance, err := k.UnmarshalFeeAllowance(iter.Value())
+		if err != nil {
+			return err
+		}
+
+		granter, grantee := types.ParseAddressesFromFeeAllowanceKey(iter.Key())
+		grant, err := types.NewFeeAllowanceGrant(granter, grantee, feeAllowance)
+		if err != nil {
+			return err
+		}
+
+		if cb(grant) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// IterateAllAllowanceSpendings iterates over the spending records of all the
+// grants, in the order of their grantee, until cb returns true.
+func (k Keeper) IterateAllAllowanceSpendings(ctx sdk.Context, cb func(granter, grantee sdk.AccAddress, spending types.AllowanceSpending) bool) {
+	store := ctx.KVStore(k.storeKey)
+	iter := sdk.KVStorePrefixIterator(store, types.AllowanceSpendingKeyPrefix)
+	defer iter.Close()
+
+	for ; iter.Valid(); iter.Next() {
+		var spending types.AllowanceSpending
+		k.cdc.MustUnmarshalBinaryBare(iter.Value(), &spending)
+
+		granter, grantee := types.ParseAddressesFromAllowanceSpendingKey(iter.Key())
+		if cb(granter, grantee, spending) {
+			break
+		}
+	}
+}
+
+// iterateFeeAllowancesByGranter iterates over the grants given by granter, in
+// the order of their grantee, until cb returns true.
+func (k Keeper) iterateFeeAllowancesByGranter(ctx sdk.Context, granter sdk.AccAddress, cb func(types.FeeAllowanceGrant) bool) error {
+	store := ctx.KVStore(k.storeKey)
+	granterPrefix := types.FeeAllowancePrefixByGranter(granter)
+	iter := sdk.KVStorePrefixIterator(store, granterPrefix)
+	defer iter.Close()
+
+	for ; iter.Valid(); iter.Next() {
+		// the remaining key is the grantee address
+		grantee := types.ParseLengthPrefixedAddress(iter.Key()[len(granterPrefix):])
+
+		feeAllowance, err := k.GetFeeAllowance(ctx, granter, grantee)
+		if err != nil {
+			return err
+		}
+
+		if feeAllowance == nil {
+			return fmt.Errorf("granter index refers to a missing grant to %s", grantee)
+		}
+
+		grant, err := types.NewFeeAllowanceGrant(granter, grantee, feeAllowance)
+		if err != nil {
+			return err
+		}
+
+		if cb(grant) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// MarshalFeeAllowance protobuf serializes a FeeAllowanceI interface
+func (k Keeper) MarshalFeeAllowance(feeAllowance types.FeeAllowanceI) ([]byte, error) {
+	msg, ok := feeAllowance.(proto.Message)
+	if !ok {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrPackAny, "cannot proto marshal %T", feeAllowance)
+	}
+
+	return k.cdc.MarshalInterface(msg)
+}
+
+// DecodeAllowance unpacks an allowance from an Any, see types.UnpackAllowance.
+func (k Keeper) DecodeAllowance(any *codectypes.Any) (types.FeeAllowanceI, error) {
+	return types.UnpackAllowance(k.cdc, any)
+}
+
+// UnmarshalFeeAllowance returns a FeeAllowanceI interface from raw encoded fee
+// allowance bytes of a Proto-based FeeAllowanceI type
+func (k Keeper) UnmarshalFeeAllowance(bz []byte) (types.FeeAllowanceI, error) {
+	var feeAllowance types.FeeAllowanceI
+	return feeAllowance, k.cdc.UnmarshalInterface(bz, &feeAllowance)
+}
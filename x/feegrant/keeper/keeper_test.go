@@ -0,0 +1,713 @@
+package keeper_test
+
+import (
+	gocontext "context"
+	"errors"
+	"testing"
+	"time"
+
+	gogogrpc "github.com/gogo/protobuf/grpc"
+	"github.com/stretchr/testify/suite"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/keeper"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+type KeeperTestSuite struct {
+	suite.Suite
+
+	app         *simapp.SimApp
+	sdkCtx      sdk.Context
+	addrs       []sdk.AccAddress
+	queryClient types.QueryClient
+	msgSrvr     types.MsgServer
+}
+
+func TestKeeperTestSuite(t *testing.T) {
+	suite.Run(t, new(KeeperTestSuite))
+}
+
+func (suite *KeeperTestSuite) SetupTest() {
+	app := simapp.Setup(false)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: time.Now()})
+
+	queryHelper := baseapp.NewQueryServerTestHelper(ctx, app.InterfaceRegistry())
+	queryClient := types.NewQueryClient(queryHelper.Connect(func(s gogogrpc.Server) {
+		types.RegisterQueryServer(s, app.FeeGrantKeeper)
+	}))
+
+	suite.app = app
+	suite.sdkCtx = ctx
+	suite.queryClient = queryClient
+	suite.msgSrvr = keeper.NewMsgServerImpl(app.FeeGrantKeeper)
+	suite.addrs = simapp.AddTestAddrsIncremental(app, ctx, 4, sdk.NewInt(30000000))
+}
+
+func (suite *KeeperTestSuite) TestKeeperCrud() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+
+	// some helpers
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	eth := sdk.NewCoins(sdk.NewInt64Coin("eth", 123))
+	basic := &types.BasicAllowance{SpendLimit: atom}
+	basic2 := &types.BasicAllowance{SpendLimit: eth}
+
+	// let's set up some initial state here
+	err := k.GrantFeeAllowance(ctx, suite.addrs[0], suite.addrs[1], basic)
+	suite.Require().NoError(err)
+	err = k.GrantFeeAllowance(ctx, suite.addrs[0], suite.addrs[2], basic2)
+	suite.Require().NoError(err)
+	err = k.GrantFeeAllowance(ctx, suite.addrs[1], suite.addrs[2], basic)
+	suite.Require().NoError(err)
+	err = k.GrantFeeAllowance(ctx, suite.addrs[1], suite.addrs[3], basic)
+	suite.Require().NoError(err)
+	err = k.GrantFeeAllowance(ctx, suite.addrs[3], suite.addrs[0], basic2)
+	suite.Require().NoError(err)
+
+	// remove some, overwrite other
+	suite.Require().NoError(k.RevokeFeeAllowance(ctx, suite.addrs[0], suite.addrs[1]))
+	suite.Require().NoError(k.RevokeFeeAllowance(ctx, suite.addrs[0], suite.addrs[2]))
+	suite.Require().Error(k.RevokeFeeAllowance(ctx, suite.addrs[0], suite.addrs[1]))
+
+	err = k.GrantFeeAllowance(ctx, suite.addrs[0], suite.addrs[2], basic)
+	suite.Require().NoError(err)
+	err = k.GrantFeeAllowance(ctx, suite.addrs[1], suite.addrs[2], basic2)
+	suite.Require().NoError(err)
+
+	// end state:
+	// addr -> addr3 (basic2), addr2 -> addr3 (basic2), addr2 -> addr4 (basic), addr4 -> addr (basic2)
+	cases := map[string]struct {
+		grantee   sdk.AccAddress
+		granter   sdk.AccAddress
+		allowance types.FeeAllowanceI
+	}{
+		"addr revoked": {
+			granter: suite.addrs[0],
+			grantee: suite.addrs[1],
+		},
+		"addr revoked and added": {
+			granter:   suite.addrs[0],
+			grantee:   suite.addrs[2],
+			allowance: basic,
+		},
+		"addr never there": {
+			granter: suite.addrs[0],
+			grantee: suite.addrs[3],
+		},
+		"addr modified": {
+			granter:   suite.addrs[1],
+			grantee:   suite.addrs[2],
+			allowance: basic2,
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		suite.Run(name, func() {
+			allow, err := k.GetFeeAllowance(ctx, tc.granter, tc.grantee)
+			suite.Require().NoError(err)
+			if tc.allowance == nil {
+				suite.Nil(allow)
+				return
+			}
+			suite.NotNil(allow)
+			suite.Equal(tc.allowance, allow)
+		})
+	}
+}
+
+func (suite *KeeperTestSuite) TestGrantRevokeEvents() {
+	ctx := suite.sdkCtx.WithEventManager(sdk.NewEventManager())
+	granter, grantee := suite.addrs[0], suite.addrs[1]
+	expAttrs := []sdk.Attribute{
+		sdk.NewAttribute(types.AttributeKeyGranter, granter.String()),
+		sdk.NewAttribute(types.AttributeKeyGrantee, grantee.String()),
+	}
+
+	err := suite.app.FeeGrantKeeper.GrantFeeAllowance(ctx, granter, grantee, &types.BasicAllowance{})
+	suite.Require().NoError(err)
+	suite.Require().Equal(sdk.Events{sdk.NewEvent(types.EventTypeSetFeeGrant, expAttrs...)}, ctx.EventManager().Events())
+
+	ctx = ctx.WithEventManager(sdk.NewEventManager())
+	err = suite.app.FeeGrantKeeper.RevokeFeeAllowance(ctx, granter, grantee)
+	suite.Require().NoError(err)
+	suite.Require().Equal(sdk.Events{sdk.NewEvent(types.EventTypeRevokeFeeGrant, expAttrs...)}, ctx.EventManager().Events())
+}
+
+func (suite *KeeperTestSuite) TestUseGrantedFeeEvent() {
+	ctx := suite.sdkCtx
+	granter, grantee := suite.addrs[0], suite.addrs[1]
+
+	allowance, err := types.NewCappedFractionAllowance(&types.BasicAllowance{
+		SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 100)),
+	}, sdk.NewDecWithPrec(5, 1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.app.FeeGrantKeeper.GrantFeeAllowance(ctx, granter, grantee, allowance))
+
+	// only the half of the fee paid out of the grant is reported
+	ctx = ctx.WithEventManager(sdk.NewEventManager())
+	_, removed, err := suite.app.FeeGrantKeeper.UseGrantedFees(ctx, granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("atom", 30)), nil)
+	suite.Require().NoError(err)
+	suite.Require().False(removed)
+
+	var used sdk.Events
+	for _, event := range ctx.EventManager().Events() {
+		if event.Type == types.EventTypeUseFeeGrant {
+			used = append(used, event)
+		}
+	}
+	suite.Require().Equal(sdk.Events{sdk.NewEvent(types.EventTypeUseFeeGrant,
+		sdk.NewAttribute(types.AttributeKeyGranter, granter.String()),
+		sdk.NewAttribute(types.AttributeKeyGrantee, grantee.String()),
+		sdk.NewAttribute(types.AttributeKeyAmount, "15atom"),
+	)}, used)
+
+	// a rejected fee emits nothing
+	ctx = ctx.WithEventManager(sdk.NewEventManager())
+	_, _, err = suite.app.FeeGrantKeeper.UseGrantedFees(ctx, granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("atom", 1000)), nil)
+	suite.Require().Error(err)
+	suite.Require().Empty(ctx.EventManager().Events())
+
+	// using up the grant removes it
+	_, removed, err = suite.app.FeeGrantKeeper.UseGrantedFees(ctx, granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("atom", 170)), nil)
+	suite.Require().NoError(err)
+	suite.Require().True(removed)
+}
+
+func (suite *KeeperTestSuite) TestUseGrantedFeeNormalizesFee() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+	granter, grantee := suite.addrs[0], suite.addrs[1]
+
+	limit := sdk.NewCoins(sdk.NewInt64Coin("atom", 555), sdk.NewInt64Coin("eth", 100))
+	atom, eth := sdk.NewInt64Coin("atom", 120), sdk.NewInt64Coin("eth", 30)
+	canonical := sdk.NewCoins(atom, eth)
+
+	use := func(fee sdk.Coins) (types.FeeAllowanceI, error) {
+		suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, grantee, &types.BasicAllowance{SpendLimit: limit}))
+
+		_, _, err := k.UseGrantedFees(ctx, granter, grantee, fee, nil)
+		grant, getErr := k.GetFeeAllowance(ctx, granter, grantee)
+		suite.Require().NoError(getErr)
+
+		return grant, err
+	}
+
+	expGrant, err := use(canonical)
+	suite.Require().NoError(err)
+	suite.Require().Equal(&types.BasicAllowance{SpendLimit: limit.Sub(canonical)}, expGrant)
+
+	for name, fee := range map[string]sdk.Coins{
+		"zero coin":       {sdk.NewInt64Coin("abc", 0), atom, eth},
+		"unsorted":        {eth, atom},
+		"unsorted zeroes": {eth, sdk.NewInt64Coin("zzz", 0), atom, sdk.NewInt64Coin("abc", 0)},
+		"split denom":     {sdk.NewInt64Coin("atom", 100), eth, sdk.NewInt64Coin("atom", 20)},
+	} {
+		grant, err := use(fee)
+		suite.Require().NoError(err, name)
+		suite.Require().Equal(expGrant, grant, name)
+	}
+
+	// zero coins in a denom which is not granted do not make the fee exceed
+	// the allowance
+	grant, err := use(sdk.Coins{sdk.NewInt64Coin("stake", 0)})
+	suite.Require().NoError(err)
+	suite.Require().Equal(&types.BasicAllowance{SpendLimit: limit}, grant)
+}
+
+func (suite *KeeperTestSuite) TestUseGrantedFee() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+	granter, grantee := suite.addrs[0], suite.addrs[1]
+
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	part := sdk.NewCoins(sdk.NewInt64Coin("atom", 120))
+
+	cases := map[string]struct {
+		granter  sdk.AccAddress
+		fee      sdk.Coins
+		expErr   error
+		expGrant types.FeeAllowanceI
+	}{
+		"use part of the allowance": {
+			granter:  granter,
+			fee:      part,
+			expGrant: &types.BasicAllowance{SpendLimit: atom.Sub(part)},
+		},
+		"use all of the allowance": {
+			granter:  granter,
+			fee:      atom,
+			expGrant: nil,
+		},
+		"exceed the allowance": {
+			granter:  granter,
+			fee:      atom.Add(part...),
+			expErr:   types.ErrFeeLimitExceeded,
+			expGrant: &types.BasicAllowance{SpendLimit: atom},
+		},
+		"no allowance": {
+			granter:  suite.addrs[2],
+			fee:      part,
+			expErr:   types.ErrNoAllowance,
+			expGrant: &types.BasicAllowance{SpendLimit: atom},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		suite.Run(name, func() {
+			err := k.GrantFeeAllowance(ctx, granter, grantee, &types.BasicAllowance{SpendLimit: atom})
+			suite.Require().NoError(err)
+
+			_, _, err = k.UseGrantedFees(ctx, tc.granter, grantee, tc.fee, nil)
+			if tc.expErr != nil {
+				suite.Require().True(errors.Is(err, tc.expErr))
+			} else {
+				suite.Require().NoError(err)
+			}
+
+			grant, err := k.GetFeeAllowance(ctx, granter, grantee)
+			suite.Require().NoError(err)
+			suite.Require().Equal(tc.expGrant, grant)
+
+			if grant != nil {
+				suite.Require().NoError(k.RevokeFeeAllowance(ctx, granter, grantee))
+			}
+		})
+	}
+}
+
+func (suite *KeeperTestSuite) TestCanUseGrantedFees() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+	granter, grantee := suite.addrs[0], suite.addrs[1]
+	store := ctx.KVStore(suite.app.GetKey(types.StoreKey))
+
+	send := &banktypes.MsgSend{}
+	filtered, err := types.NewAllowedMsgAllowance(&types.BasicAllowance{
+		SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 100)),
+	}, []string{"/cosmos.bank.v1beta1.MsgSend"})
+	suite.Require().NoError(err)
+	allowance, err := types.NewCappedFractionAllowance(filtered, sdk.NewDecWithPrec(5, 1))
+	suite.Require().NoError(err)
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, grantee, allowance))
+
+	// record some spending first, so that it can be checked unchanged
+	_, _, err = k.UseGrantedFees(ctx, granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("atom", 20)), []sdk.Msg{send})
+	suite.Require().NoError(err)
+
+	stored := store.Get(types.FeeAllowanceKey(granter, grantee))
+	spending, found := k.GetAllowanceSpending(ctx, granter, grantee)
+	suite.Require().True(found)
+
+	ctx = ctx.WithEventManager(sdk.NewEventManager())
+	covered, err := k.CanUseGrantedFees(ctx, granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("atom", 30)), []sdk.Msg{send})
+	suite.Require().NoError(err)
+	suite.Require().Equal(sdk.NewCoins(sdk.NewInt64Coin("atom", 15)), covered)
+
+	cases := map[string]struct {
+		granter sdk.AccAddress
+		fee     sdk.Coins
+		msgs    []sdk.Msg
+		expErr  error
+	}{
+		"over the limit": {
+			granter: granter,
+			fee:     sdk.NewCoins(sdk.NewInt64Coin("atom", 1000)),
+			msgs:    []sdk.Msg{send},
+			expErr:  types.ErrFeeLimitExceeded,
+		},
+		"msg not allowed": {
+			granter: granter,
+			fee:     sdk.NewCoins(sdk.NewInt64Coin("atom", 30)),
+			msgs:    []sdk.Msg{send, &banktypes.MsgMultiSend{}},
+			expErr:  types.ErrMessageNotAllowed,
+		},
+		"no grant": {
+			granter: suite.addrs[2],
+			fee:     sdk.NewCoins(sdk.NewInt64Coin("atom", 30)),
+			msgs:    []sdk.Msg{send},
+			expErr:  types.ErrNoAllowance,
+		},
+	}
+
+	for name, tc := range cases {
+		_, err := k.CanUseGrantedFees(ctx, tc.granter, grantee, tc.fee, tc.msgs)
+		suite.Require().True(errors.Is(err, tc.expErr), "%s: %v", name, err)
+	}
+
+	// neither the grant nor its spending changed, and nothing was reported
+	suite.Require().Equal(stored, store.Get(types.FeeAllowanceKey(granter, grantee)))
+	newSpending, found := k.GetAllowanceSpending(ctx, granter, grantee)
+	suite.Require().True(found)
+	suite.Require().Equal(spending, newSpending)
+	suite.Require().Empty(ctx.EventManager().Events())
+
+	// the fee checked is then paid the same
+	used, _, err := k.UseGrantedFees(ctx, granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("atom", 30)), []sdk.Msg{send})
+	suite.Require().NoError(err)
+	suite.Require().Equal(covered, used)
+}
+
+func (suite *KeeperTestSuite) TestResolveFeeGranter() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+	granter, grantee, stranger := suite.addrs[0], suite.addrs[1], suite.addrs[2]
+
+	err := k.GrantFeeAllowance(ctx, granter, grantee, &types.BasicAllowance{})
+	suite.Require().NoError(err)
+
+	cases := map[string]struct {
+		feeGranter string
+		expPayer   sdk.AccAddress
+		expErr     error
+	}{
+		"granter set": {
+			feeGranter: granter.String(),
+			expPayer:   granter,
+		},
+		"granter unset": {
+			feeGranter: "",
+			expPayer:   grantee,
+		},
+		"granter is the fee payer": {
+			feeGranter: grantee.String(),
+			expPayer:   grantee,
+		},
+		"unauthorized granter": {
+			feeGranter: stranger.String(),
+			expErr:     types.ErrNoAllowance,
+		},
+		"malformed granter": {
+			feeGranter: "cosmos1invalid",
+			expErr:     sdkerrors.ErrInvalidAddress,
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		suite.Run(name, func() {
+			payer, err := k.ResolveFeeGranter(ctx, grantee, &tx.Fee{Granter: tc.feeGranter})
+			if tc.expErr != nil {
+				suite.Require().True(errors.Is(err, tc.expErr), err)
+				return
+			}
+			suite.Require().NoError(err)
+			suite.Require().Equal(tc.expPayer, payer)
+		})
+	}
+}
+
+func (suite *KeeperTestSuite) TestIterateAllFeeAllowances() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+
+	// addrs are sorted, so grants are stored by grantee then granter
+	pairs := [][2]sdk.AccAddress{
+		{suite.addrs[1], suite.addrs[0]},
+		{suite.addrs[2], suite.addrs[0]},
+		{suite.addrs[0], suite.addrs[1]},
+		{suite.addrs[3], suite.addrs[2]},
+	}
+	for i := len(pairs) - 1; i >= 0; i-- {
+		allowance := &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", int64(i+1)))}
+		suite.Require().NoError(k.GrantFeeAllowance(ctx, pairs[i][0], pairs[i][1], allowance))
+	}
+
+	var seen []types.FeeAllowanceGrant
+	err := k.IterateAllFeeAllowances(ctx, func(grant types.FeeAllowanceGrant) bool {
+		seen = append(seen, grant)
+		return false
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(seen, len(pairs))
+
+	for i, grant := range seen {
+		suite.Require().Equal(pairs[i][0].String(), grant.Granter)
+		suite.Require().Equal(pairs[i][1].String(), grant.Grantee)
+
+		allowance, err := grant.GetFeeGrant()
+		suite.Require().NoError(err)
+		suite.Require().Equal(sdk.NewCoins(sdk.NewInt64Coin("atom", int64(i+1))), allowance.(*types.BasicAllowance).SpendLimit)
+	}
+
+	// returning true stops the iteration
+	var count int
+	err = k.IterateAllFeeAllowances(ctx, func(types.FeeAllowanceGrant) bool {
+		count++
+		return count == 2
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(2, count)
+}
+
+// mockHooks records the feegrant hooks it is called with.
+type mockHooks struct {
+	calls []string
+}
+
+func (h *mockHooks) AfterGrant(_ sdk.Context, granter, grantee sdk.AccAddress, _ types.FeeAllowanceI) {
+	h.calls = append(h.calls, "grant "+granter.String()+" "+grantee.String())
+}
+
+func (h *mockHooks) AfterRevoke(_ sdk.Context, granter, grantee sdk.AccAddress) {
+	h.calls = append(h.calls, "revoke "+granter.String()+" "+grantee.String())
+}
+
+func (h *mockHooks) AfterUseAllowance(_ sdk.Context, granter, grantee sdk.AccAddress, fee sdk.Coins) {
+	h.calls = append(h.calls, "use "+granter.String()+" "+grantee.String()+" "+fee.String())
+}
+
+func (h *mockHooks) BeforeAllowanceExpires(_ sdk.Context, granter, grantee sdk.AccAddress, expiresAt time.Time) {
+	h.calls = append(h.calls, "expires "+granter.String()+" "+grantee.String()+" "+expiresAt.String())
+}
+
+func (suite *KeeperTestSuite) TestHooks() {
+	ctx := suite.sdkCtx
+	granter, grantee := suite.addrs[0], suite.addrs[1]
+	fee := sdk.NewCoins(sdk.NewInt64Coin("atom", 10))
+
+	hooks1, hooks2 := &mockHooks{}, &mockHooks{}
+	k := suite.app.FeeGrantKeeper
+	k.SetHooks(types.NewMultiFeegrantHooks(hooks1, hooks2))
+	suite.Require().Panics(func() { k.SetHooks(hooks1) })
+
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, grantee, &types.BasicAllowance{}))
+	_, _, err := k.UseGrantedFees(ctx, granter, grantee, fee, nil)
+	suite.Require().NoError(err)
+	suite.Require().NoError(k.RevokeFeeAllowance(ctx, granter, grantee))
+
+	// failures do not call the hooks
+	_, _, err = k.UseGrantedFees(ctx, granter, grantee, fee, nil)
+	suite.Require().Error(err)
+	suite.Require().Error(k.RevokeFeeAllowance(ctx, granter, grantee))
+
+	expCalls := []string{
+		"grant " + granter.String() + " " + grantee.String(),
+		"use " + granter.String() + " " + grantee.String() + " 10atom",
+		"revoke " + granter.String() + " " + grantee.String(),
+	}
+	suite.Require().Equal(expCalls, hooks1.calls)
+	suite.Require().Equal(expCalls, hooks2.calls)
+}
+
+func (suite *KeeperTestSuite) TestMaxGrantsPerGranter() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+	granter := suite.addrs[0]
+	allowance := &types.BasicAllowance{}
+
+	k.SetParams(ctx, types.NewParams(2, true, 0))
+
+	// the granter is at limit-1 after the first grant, so the second one fits
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, suite.addrs[1], allowance))
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, suite.addrs[2], allowance))
+
+	// at the limit, new grants are rejected but existing ones can be replaced
+	err := k.GrantFeeAllowance(ctx, granter, suite.addrs[3], allowance)
+	suite.Require().True(errors.Is(err, types.ErrTooManyGrants))
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, suite.addrs[2], allowance))
+
+	// other granters are not affected
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[1], suite.addrs[3], allowance))
+
+	// revoking frees a slot
+	suite.Require().NoError(k.RevokeFeeAllowance(ctx, granter, suite.addrs[1]))
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, suite.addrs[3], allowance))
+
+	// so does an expired grant being pruned
+	exp := ctx.BlockTime().Add(time.Hour)
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, suite.addrs[2], &types.BasicAllowance{Expiration: &exp}))
+	k.RemoveExpiredAllowances(ctx.WithBlockTime(exp.Add(time.Second)))
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, suite.addrs[1], allowance))
+}
+
+func (suite *KeeperTestSuite) TestGetFeeGrant() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+	allowance := &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 555))}
+
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, suite.addrs[0], suite.addrs[1], allowance))
+
+	grant, found, err := k.GetFeeGrant(ctx, suite.addrs[0], suite.addrs[1])
+	suite.Require().NoError(err)
+	suite.Require().True(found)
+	suite.Require().Equal(types.Grant{Granter: suite.addrs[0], Grantee: suite.addrs[1], Allowance: allowance}, grant)
+
+	_, found, err = k.GetFeeGrant(ctx, suite.addrs[1], suite.addrs[0])
+	suite.Require().NoError(err)
+	suite.Require().False(found)
+
+	// an allowance of a type which is not registered cannot be unpacked
+	bz, err := suite.app.AppCodec().MarshalBinaryBare(&codectypes.Any{TypeUrl: "/cosmos.feegrant.v1beta1.Unknown"})
+	suite.Require().NoError(err)
+	ctx.KVStore(suite.app.GetKey(types.StoreKey)).Set(types.FeeAllowanceKey(suite.addrs[2], suite.addrs[3]), bz)
+
+	_, _, err = k.GetFeeGrant(ctx, suite.addrs[2], suite.addrs[3])
+	suite.Require().Error(err)
+}
+
+func (suite *KeeperTestSuite) TestTransferAllowance() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+	granter, oldGrantee, newGrantee := suite.addrs[0], suite.addrs[1], suite.addrs[2]
+
+	exp := ctx.BlockTime().Add(time.Hour)
+	allowance := &types.PeriodicFeeAllowance{
+		Basic:            types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 555)), Expiration: &exp},
+		Period:           time.Minute,
+		PeriodSpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 10)),
+		PeriodCanSpend:   sdk.NewCoins(sdk.NewInt64Coin("atom", 10)),
+		PeriodReset:      ctx.BlockTime().Add(time.Minute),
+	}
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, oldGrantee, allowance))
+	_, _, err := k.UseGrantedFees(ctx, granter, oldGrantee, sdk.NewCoins(sdk.NewInt64Coin("atom", 3)), nil)
+	suite.Require().NoError(err)
+	used, err := k.GetFeeAllowance(ctx, granter, oldGrantee)
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(k.TransferAllowance(ctx, granter, oldGrantee, newGrantee))
+
+	_, found, err := k.GetFeeGrant(ctx, granter, oldGrantee)
+	suite.Require().NoError(err)
+	suite.Require().False(found)
+
+	// the allowance keeps what was spent from it
+	transferred, err := k.GetFeeAllowance(ctx, granter, newGrantee)
+	suite.Require().NoError(err)
+	suite.Require().Equal(used, transferred)
+	suite.Require().Equal(uint64(1), k.GetGrantsCount(ctx))
+
+	spending, found := k.GetAllowanceSpending(ctx, granter, newGrantee)
+	suite.Require().True(found)
+	suite.Require().Equal(sdk.NewCoins(sdk.NewInt64Coin("atom", 3)), spending.Spent)
+	_, found = k.GetAllowanceSpending(ctx, granter, oldGrantee)
+	suite.Require().False(found)
+
+	// the granter index refers to the new grantee
+	res, err := suite.queryClient.AllowancesByGranter(gocontext.Background(), &types.QueryAllowancesByGranterRequest{Granter: granter.String()})
+	suite.Require().NoError(err)
+	suite.Require().Len(res.Allowances, 1)
+	suite.Require().Equal(newGrantee.String(), res.Allowances[0].Grantee)
+
+	// the grant is still pruned once expired
+	k.RemoveExpiredAllowances(ctx.WithBlockTime(exp.Add(time.Second)))
+	_, found, err = k.GetFeeGrant(ctx, granter, newGrantee)
+	suite.Require().NoError(err)
+	suite.Require().False(found)
+}
+
+func (suite *KeeperTestSuite) TestTransferAllowanceInvalid() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+	granter := suite.addrs[0]
+	allowance := &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 555))}
+
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, suite.addrs[1], allowance))
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, suite.addrs[2], allowance))
+
+	err := k.TransferAllowance(ctx, granter, suite.addrs[3], suite.addrs[1])
+	suite.Require().True(errors.Is(err, types.ErrNoAllowance))
+
+	err = k.TransferAllowance(ctx, granter, suite.addrs[1], suite.addrs[2])
+	suite.Require().True(errors.Is(err, types.ErrFeeAllowanceExists))
+
+	err = k.TransferAllowance(ctx, granter, suite.addrs[1], granter)
+	suite.Require().Error(err)
+
+	// failed transfers leave both grants in place
+	for _, grantee := range suite.addrs[1:3] {
+		_, found, err := k.GetFeeGrant(ctx, granter, grantee)
+		suite.Require().NoError(err)
+		suite.Require().True(found)
+	}
+	suite.Require().Equal(uint64(2), k.GetGrantsCount(ctx))
+}
+
+func (suite *KeeperTestSuite) TestExtendExpiration() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+	granter := suite.addrs[0]
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	exp := ctx.BlockTime().Add(time.Hour)
+	later := exp.Add(time.Hour)
+
+	periodic := &types.PeriodicFeeAllowance{
+		Basic:            types.BasicAllowance{SpendLimit: atom, Expiration: &exp},
+		Period:           time.Minute,
+		PeriodSpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 10)),
+	}
+	wrapped, err := types.NewAllowedMsgAllowance(periodic, []string{"/cosmos.bank.v1beta1.MsgSend"})
+	suite.Require().NoError(err)
+	allowances := []types.FeeAllowanceI{&types.BasicAllowance{SpendLimit: atom, Expiration: &exp}, wrapped}
+
+	for i, allowance := range allowances {
+		grantee := suite.addrs[i+1]
+		suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, grantee, allowance))
+		_, _, err := k.UseGrantedFees(ctx, granter, grantee, sdk.NewCoins(sdk.NewInt64Coin("atom", 3)), nil)
+		suite.Require().NoError(err)
+		used, err := k.GetFeeAllowance(ctx, granter, grantee)
+		suite.Require().NoError(err)
+		usedRemaining, err := used.Remaining(ctx)
+		suite.Require().NoError(err)
+
+		// moving the expiration back is rejected
+		err = k.ExtendExpiration(ctx, granter, grantee, exp.Add(-time.Second))
+		suite.Require().True(errors.Is(err, sdkerrors.ErrInvalidRequest))
+
+		suite.Require().NoError(k.ExtendExpiration(ctx, granter, grantee, later))
+
+		extended, err := k.GetFeeAllowance(ctx, granter, grantee)
+		suite.Require().NoError(err)
+		extendedExp, err := extended.ExpiresAt()
+		suite.Require().NoError(err)
+		suite.Require().True(later.Equal(*extendedExp))
+
+		// only the expiration changed
+		remaining, err := extended.Remaining(ctx)
+		suite.Require().NoError(err)
+		suite.Require().Equal(usedRemaining, remaining)
+	}
+
+	// the expiration queue follows the new expiration
+	k.RemoveExpiredAllowances(ctx.WithBlockTime(exp.Add(time.Second)))
+	suite.Require().Equal(uint64(2), k.GetGrantsCount(ctx))
+	k.RemoveExpiredAllowances(ctx.WithBlockTime(later.Add(time.Second)))
+	suite.Require().Equal(uint64(0), k.GetGrantsCount(ctx))
+}
+
+func (suite *KeeperTestSuite) TestExtendExpirationInvalid() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+	granter := suite.addrs[0]
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	later := ctx.BlockTime().Add(time.Hour)
+
+	err := k.ExtendExpiration(ctx, granter, suite.addrs[1], later)
+	suite.Require().True(errors.Is(err, types.ErrNoAllowance))
+
+	// allowances without an expiration time cannot be extended
+	for i, allowance := range []types.FeeAllowanceI{
+		&types.BasicAllowance{SpendLimit: atom},
+		&types.BasicAllowance{SpendLimit: atom, ExpirationHeight: 100},
+	} {
+		grantee := suite.addrs[i+1]
+		suite.Require().NoError(k.GrantFeeAllowance(ctx, granter, grantee, allowance))
+
+		err := k.ExtendExpiration(ctx, granter, grantee, later)
+		suite.Require().True(errors.Is(err, sdkerrors.ErrInvalidRequest))
+
+		stored, err := k.GetFeeAllowance(ctx, granter, grantee)
+		suite.Require().NoError(err)
+		suite.Require().Equal(allowance, stored)
+	}
+}
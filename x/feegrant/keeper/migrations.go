@@ -0,0 +1,339 @@
+package keeper
+
+import (
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+// legacyFlatKeyLen is the length of the keys of the first pre-release layout,
+// in which grants were stored under the flat key granter|grantee, without a
+// prefix nor any index.
+const legacyFlatKeyLen = 2 * sdk.AddrLen
+
+// legacyKey returns the key of the second pre-release layout made of prefix
+// followed by addrs, which were not length prefixed yet.
+func legacyKey(prefix []byte, addrs ...[]byte) []byte {
+	key := append([]byte{}, prefix...)
+	for _, addr := range addrs {
+		key = append(key, addr...)
+	}
+
+	return key
+}
+
+// Migrator is a struct for handling in-place store migrations.
+type Migrator struct {
+	keeper Keeper
+}
+
+// NewMigrator returns a new Migrator.
+func NewMigrator(keeper Keeper) Migrator {
+	return Migrator{keeper: keeper}
+}
+
+// MigrateLegacyStore migrates the store of a chain which ran a pre-release
+// build of the module to the current layout. It is meant to be called from the
+// upgrade handler of the upgrade switching to the released module, e.g.
+//
+//	app.UpgradeKeeper.SetUpgradeHandler("upgrade-name", func(ctx sdk.Context, plan upgradetypes.Plan) {
+//		if err := feegrantkeeper.NewMigrator(app.FeeGrantKeeper).MigrateLegacyStore(ctx); err != nil {
+//			panic(err)
+//		}
+//	})
+//
+// The pre-release builds stored grants either under a flat granter|grantee
+// key, or under prefixed keys simply concatenating the addresses of a grant,
+// which cannot be split if the addresses are not all of the same length. The
+// split of a grant key grantee|granter is thus the one for which the granter
+// index holds the key granter|grantee, and the other keys of the grant are
+// moved along with it. A key which cannot be split unambiguously is logged and
+// moved under types.UnmigratedKeyPrefix instead of failing the upgrade.
+//
+// The store is migrated in a single deterministic pass, the old keys being all
+// read and deleted before the new ones are written, so that they cannot
+// collide. Keys already in the current layout are left untouched, so running
+// it again, e.g. after a partial migration, is safe. The number of grants and
+// the expiration height queue, which the pre-release builds did not all keep,
+// are then backfilled.
+func (m Migrator) MigrateLegacyStore(ctx sdk.Context) error {
+	store := ctx.KVStore(m.keeper.storeKey)
+	var migration legacyMigration
+
+	if err := m.migrateFlatGrants(ctx, &migration); err != nil {
+		return err
+	}
+	m.migratePrefixedKeys(ctx, &migration)
+
+	for _, key := range migration.oldKeys {
+		store.Delete(key)
+	}
+
+	for i, key := range migration.newKeys {
+		store.Set(key, migration.values[i])
+	}
+
+	return m.backfillIndexes(ctx)
+}
+
+// legacyMigration holds the keys to write, in the order they were read, and
+// the old keys to delete first.
+type legacyMigration struct {
+	oldKeys, newKeys, values [][]byte
+	// moved is set for the old keys
+	moved map[string]bool
+}
+
+// move replaces oldKey with newKey.
+func (l *legacyMigration) move(oldKey, newKey, value []byte) {
+	if l.moved == nil {
+		l.moved = make(map[string]bool)
+	}
+
+	l.moved[string(oldKey)] = true
+	l.oldKeys = append(l.oldKeys, oldKey)
+	l.set(newKey, value)
+}
+
+// set adds newKey, without an old key to delete.
+func (l *legacyMigration) set(newKey, value []byte) {
+	l.newKeys = append(l.newKeys, newKey)
+	l.values = append(l.values, value)
+}
+
+// moveAside logs a key which cannot be migrated, and moves it under
+// types.UnmigratedKeyPrefix.
+func (l *legacyMigration) moveAside(ctx sdk.Context, k Keeper, key, value []byte, reason string) {
+	k.Logger(ctx).Error("cannot migrate legacy feegrant key", "key", fmt.Sprintf("%X", key), "reason", reason)
+	l.move(key, append(append([]byte{}, types.UnmigratedKeyPrefix...), key...), value)
+}
+
+// migrateFlatGrants moves the grants of the flat layout under their grantee
+// prefixed key, and adds them to the granter index and expiration queue.
+func (m Migrator) migrateFlatGrants(ctx sdk.Context, migration *legacyMigration) error {
+	store := ctx.KVStore(m.keeper.storeKey)
+
+	// the keys of the other layouts are all prefixed, and thus longer
+	iter := store.Iterator(nil, nil)
+	defer iter.Close()
+
+	for ; iter.Valid(); iter.Next() {
+		key := iter.Key()
+		if len(key) != legacyFlatKeyLen {
+			continue
+		}
+
+		granter, grantee := sdk.AccAddress(key[:sdk.AddrLen]), sdk.AccAddress(key[sdk.AddrLen:])
+
+		feeAllowance, err := m.keeper.UnmarshalFeeAllowance(iter.Value())
+		if err != nil {
+			return err
+		}
+
+		exp, err := feeAllowance.ExpiresAt()
+		if err != nil {
+			return err
+		}
+
+		migration.move(key, types.FeeAllowanceKey(granter, grantee), iter.Value())
+		migration.set(types.FeeAllowanceByGranterKey(granter, grantee), []byte{})
+		if exp != nil {
+			migration.set(types.FeeAllowanceQueueKey(*exp, granter, grantee), []byte{})
+		}
+	}
+
+	return nil
+}
+
+// legacyAddrs are the addresses of a grant, as split from its legacy keys.
+type legacyAddrs struct {
+	granter, grantee sdk.AccAddress
+}
+
+// migratePrefixedKeys moves the keys of the prefixed layout whose addresses
+// were not length prefixed to their current key.
+func (m Migrator) migratePrefixedKeys(ctx sdk.Context, migration *legacyMigration) {
+	store := ctx.KVStore(m.keeper.storeKey)
+
+	// the addresses of the grants, by the grantee|granter and granter|grantee
+	// parts of their legacy keys
+	byGrantee := make(map[string]legacyAddrs)
+	byGranter := make(map[string]legacyAddrs)
+
+	iter := sdk.KVStorePrefixIterator(store, types.FeeAllowanceKeyPrefix)
+	for ; iter.Valid(); iter.Next() {
+		key := iter.Key()
+		if len(key) == legacyFlatKeyLen {
+			continue
+		}
+
+		rest := key[len(types.FeeAllowanceKeyPrefix):]
+		if granter, grantee, ok := parseAddressPair(rest, true); ok && store.Has(types.FeeAllowanceByGranterKey(granter, grantee)) {
+			continue
+		}
+
+		addrs, found := splitLegacyFeeAllowanceKey(store, rest)
+		switch found {
+		case 0:
+			migration.moveAside(ctx, m.keeper, key, iter.Value(), "no granter index entry matches the grant")
+		case 1:
+			byGrantee[string(rest)] = addrs
+			byGranter[string(legacyKey(nil, addrs.granter, addrs.grantee))] = addrs
+		default:
+			migration.moveAside(ctx, m.keeper, key, iter.Value(), "several granter index entries match the grant")
+		}
+	}
+	iter.Close()
+
+	timeLen := len(sdk.FormatTimeBytes(time.Time{}))
+	sets := []struct {
+		prefix []byte
+		// length of the part of the keys between the prefix and the addresses
+		infixLen int
+		// whether the granter comes first in the keys
+		granterFirst bool
+		newKey       func(key []byte, granter, grantee sdk.AccAddress) []byte
+	}{
+		{types.FeeAllowanceKeyPrefix, 0, false, func(_ []byte, granter, grantee sdk.AccAddress) []byte {
+			return types.FeeAllowanceKey(granter, grantee)
+		}},
+		{types.FeeAllowanceQueueKeyPrefix, timeLen, false, func(key []byte, granter, grantee sdk.AccAddress) []byte {
+			return types.FeeAllowanceQueueKey(types.ParseExpirationFromFeeAllowanceQueueKey(key), granter, grantee)
+		}},
+		{types.FeeAllowanceByGranterKeyPrefix, 0, true, func(_ []byte, granter, grantee sdk.AccAddress) []byte {
+			return types.FeeAllowanceByGranterKey(granter, grantee)
+		}},
+		{types.AllowanceSpendingKeyPrefix, 0, false, func(_ []byte, granter, grantee sdk.AccAddress) []byte {
+			return types.AllowanceSpendingKey(granter, grantee)
+		}},
+		// the warning keys are laid out as the expiration queue keys
+		{types.ExpiryWarningKeyPrefix, timeLen, false, func(key []byte, granter, grantee sdk.AccAddress) []byte {
+			exp := types.ParseExpirationFromFeeAllowanceQueueKey(key)
+			return types.ExpiryWarningKey(types.FeeAllowanceQueueKey(exp, granter, grantee))
+		}},
+		{types.FeeAllowanceHeightQueueKeyPrefix, 8, false, func(key []byte, granter, grantee sdk.AccAddress) []byte {
+			height := types.ParseExpirationHeightFromFeeAllowanceHeightQueueKey(key)
+			return types.FeeAllowanceHeightQueueKey(height, granter, grantee)
+		}},
+	}
+
+	for _, set := range sets {
+		byAddrs := byGrantee
+		if set.granterFirst {
+			byAddrs = byGranter
+		}
+
+		iter := sdk.KVStorePrefixIterator(store, set.prefix)
+		for ; iter.Valid(); iter.Next() {
+			key := iter.Key()
+			start := len(set.prefix) + set.infixLen
+			if len(key) == legacyFlatKeyLen || migration.moved[string(key)] {
+				continue
+			}
+
+			if len(key) <= start {
+				migration.moveAside(ctx, m.keeper, key, iter.Value(), "key too short")
+				continue
+			}
+
+			if addrs, ok := byAddrs[string(key[start:])]; ok {
+				migration.move(key, set.newKey(key, addrs.granter, addrs.grantee), iter.Value())
+				continue
+			}
+
+			// the keys of the grants which could not be migrated are moved
+			// aside along with them
+			if _, _, ok := parseAddressPair(key[start:], !set.granterFirst); !ok {
+				migration.moveAside(ctx, m.keeper, key, iter.Value(), "no migrated grant matches the key")
+			}
+		}
+		iter.Close()
+	}
+}
+
+// splitLegacyFeeAllowanceKey splits the grantee|granter part of a legacy grant
+// key into its addresses, at the place for which the granter index holds the
+// key granter|grantee. It returns the number of such places, the addresses
+// being only valid if there is exactly one.
+func splitLegacyFeeAllowanceKey(store sdk.KVStore, rest []byte) (legacyAddrs, int) {
+	var (
+		addrs legacyAddrs
+		found int
+	)
+
+	for i := 1; i < len(rest); i++ {
+		grantee, granter := sdk.AccAddress(rest[:i]), sdk.AccAddress(rest[i:])
+		if store.Has(legacyKey(types.FeeAllowanceByGranterKeyPrefix, granter, grantee)) {
+			addrs = legacyAddrs{granter: granter, grantee: grantee}
+			found++
+		}
+	}
+
+	return addrs, found
+}
+
+// parseAddressPair extracts the granter and grantee from bz, made of their
+// length prefixed addresses, the grantee first if granteeFirst is set. It
+// returns false if bz is not made of two length prefixed addresses.
+func parseAddressPair(bz []byte, granteeFirst bool) (granter, grantee sdk.AccAddress, ok bool) {
+	if len(bz) == 0 || len(bz) < 1+int(bz[0]) {
+		return nil, nil, false
+	}
+
+	rest := bz[1+int(bz[0]):]
+	if len(rest) == 0 || len(rest) != 1+int(rest[0]) {
+		return nil, nil, false
+	}
+
+	first, second := sdk.AccAddress(bz[1:1+int(bz[0])]), sdk.AccAddress(rest[1:])
+	if granteeFirst {
+		return second, first, true
+	}
+
+	return first, second, true
+}
+
+// backfillIndexes adds the grants expiring at a block height to the expiration
+// height queue, and counts the grants, which the pre-release builds did not
+// all do.
+func (m Migrator) backfillIndexes(ctx sdk.Context) error {
+	store := ctx.KVStore(m.keeper.storeKey)
+
+	var (
+		queueKeys [][]byte
+		count     uint64
+	)
+
+	iter := sdk.KVStorePrefixIterator(store, types.FeeAllowanceKeyPrefix)
+	for ; iter.Valid(); iter.Next() {
+		count++
+
+		feeAllowance, err := m.keeper.UnmarshalFeeAllowance(iter.Value())
+		if err != nil {
+			iter.Close()
+			return err
+		}
+
+		expHeight, err := feeAllowance.ExpiresAtHeight()
+		if err != nil {
+			iter.Close()
+			return err
+		}
+
+		if expHeight > 0 {
+			granter, grantee := types.ParseAddressesFromFeeAllowanceKey(iter.Key())
+			queueKeys = append(queueKeys, types.FeeAllowanceHeightQueueKey(expHeight, granter, grantee))
+		}
+	}
+	iter.Close()
+
+	for _, key := range queueKeys {
+		store.Set(key, []byte{})
+	}
+
+	m.keeper.setGrantsCount(ctx, count)
+
+	return nil
+}
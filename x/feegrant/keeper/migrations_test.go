@@ -0,0 +1,247 @@
+package keeper_test
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/keeper"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+// storeKeys returns the keys of the feegrant store under prefix.
+func (suite *KeeperTestSuite) storeKeys(ctx sdk.Context, prefix []byte) [][]byte {
+	var keys [][]byte
+	iter := sdk.KVStorePrefixIterator(ctx.KVStore(suite.app.GetKey(types.StoreKey)), prefix)
+	for ; iter.Valid(); iter.Next() {
+		keys = append(keys, iter.Key())
+	}
+	iter.Close()
+
+	return keys
+}
+
+func (suite *KeeperTestSuite) TestMigrateLegacyStoreFlat() {
+	ctx := suite.sdkCtx.WithBlockHeight(10)
+	k := suite.app.FeeGrantKeeper
+	store := ctx.KVStore(suite.app.GetKey(types.StoreKey))
+
+	exp := ctx.BlockTime().Add(time.Hour)
+	grants := []types.Grant{
+		{Granter: suite.addrs[0], Grantee: suite.addrs[1], Allowance: &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 555))}},
+		{Granter: suite.addrs[0], Grantee: suite.addrs[2], Allowance: &types.BasicAllowance{Expiration: &exp}},
+		{Granter: suite.addrs[3], Grantee: suite.addrs[1], Allowance: &types.BasicAllowance{ExpirationHeight: 20}},
+	}
+
+	// seed the flat layout, without a prefix nor any index
+	setFlat := func(grant types.Grant) {
+		bz, err := k.MarshalFeeAllowance(grant.Allowance)
+		suite.Require().NoError(err)
+		store.Set(append(grant.Granter.Bytes(), grant.Grantee.Bytes()...), bz)
+	}
+	for _, grant := range grants {
+		setFlat(grant)
+	}
+
+	migrator := keeper.NewMigrator(k)
+	suite.Require().NoError(migrator.MigrateLegacyStore(ctx))
+
+	assertMigrated := func() {
+		for _, grant := range grants {
+			suite.Require().False(store.Has(append(grant.Granter.Bytes(), grant.Grantee.Bytes()...)))
+
+			stored, found, err := k.GetFeeGrant(ctx, grant.Granter, grant.Grantee)
+			suite.Require().NoError(err)
+			suite.Require().True(found)
+			suite.Require().Equal(grant, stored)
+		}
+		suite.Require().Equal(uint64(3), k.GetGrantsCount(ctx))
+
+		res, err := k.AllowancesByGranter(sdk.WrapSDKContext(ctx), &types.QueryAllowancesByGranterRequest{
+			Granter:    suite.addrs[0].String(),
+			Pagination: &query.PageRequest{CountTotal: true},
+		})
+		suite.Require().NoError(err)
+		suite.Require().Equal(uint64(2), res.Pagination.Total)
+
+		res2, err := k.Allowances(sdk.WrapSDKContext(ctx), &types.QueryAllowancesRequest{Grantee: suite.addrs[1].String()})
+		suite.Require().NoError(err)
+		suite.Require().Len(res2.Allowances, 2)
+
+		// the expiring grants are in the expiration queues
+		suite.Require().Equal([][]byte{types.FeeAllowanceQueueKey(exp, suite.addrs[0], suite.addrs[2])}, suite.storeKeys(ctx, types.FeeAllowanceQueueKeyPrefix))
+		suite.Require().Equal([][]byte{types.FeeAllowanceHeightQueueKey(20, suite.addrs[3], suite.addrs[1])}, suite.storeKeys(ctx, types.FeeAllowanceHeightQueueKeyPrefix))
+	}
+	assertMigrated()
+
+	// running it again changes nothing
+	suite.Require().NoError(migrator.MigrateLegacyStore(ctx))
+	assertMigrated()
+
+	// a partially applied migration, with a grant both migrated and left in the
+	// flat layout, is completed
+	setFlat(grants[0])
+	suite.Require().NoError(migrator.MigrateLegacyStore(ctx))
+	assertMigrated()
+
+	// the grants are pruned once expired
+	k.RemoveExpiredAllowances(ctx.WithBlockHeight(21))
+	suite.Require().Equal(uint64(2), k.GetGrantsCount(ctx))
+}
+
+func (suite *KeeperTestSuite) TestMigrateLegacyStorePrefixed() {
+	ctx := suite.sdkCtx.WithBlockHeight(10)
+	k := suite.app.FeeGrantKeeper
+	store := ctx.KVStore(suite.app.GetKey(types.StoreKey))
+	migrator := keeper.NewMigrator(k)
+
+	// a grant already in the current layout, as after a partial migration
+	current := types.Grant{Granter: suite.addrs[3], Grantee: suite.addrs[1], Allowance: &types.BasicAllowance{}}
+	suite.Require().NoError(k.GrantFeeAllowance(ctx, current.Granter, current.Grantee, current.Allowance))
+	expected := suite.storeKeys(ctx, nil)
+
+	// a 32 bytes address starting with a 20 bytes one, so that the legacy keys
+	// of its grants could be split at either length
+	short, other := suite.addrs[0], suite.addrs[1]
+	long := sdk.AccAddress(append(suite.addrs[2].Bytes(), suite.addrs[3][:12]...))
+
+	exp := ctx.BlockTime().Add(time.Hour)
+	spending := types.AllowanceSpending{
+		Spent:         sdk.NewCoins(sdk.NewInt64Coin("atom", 5)),
+		OriginalLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 555)),
+		Since:         ctx.BlockTime(),
+	}
+	grants := []types.Grant{
+		{Granter: short, Grantee: long, Allowance: &types.BasicAllowance{SpendLimit: spending.OriginalLimit}},
+		{Granter: long, Grantee: short, Allowance: &types.BasicAllowance{Expiration: &exp}},
+		{Granter: long, Grantee: suite.addrs[2], Allowance: &types.BasicAllowance{}},
+		{Granter: short, Grantee: other, Allowance: &types.BasicAllowance{ExpirationHeight: 20}},
+	}
+
+	// seed the prefixed layout whose addresses were not length prefixed, which
+	// did not index the grants by expiration height
+	for _, grant := range grants {
+		bz, err := k.MarshalFeeAllowance(grant.Allowance)
+		suite.Require().NoError(err)
+		store.Set(keeper.LegacyKey(types.FeeAllowanceKeyPrefix, grant.Grantee, grant.Granter), bz)
+		store.Set(keeper.LegacyKey(types.FeeAllowanceByGranterKeyPrefix, grant.Granter, grant.Grantee), []byte{})
+	}
+	legacyQueueKey := keeper.LegacyKey(types.FeeAllowanceByQueueKey(exp), short, long)
+	store.Set(legacyQueueKey, []byte{})
+	store.Set(types.ExpiryWarningKey(legacyQueueKey), []byte{})
+	store.Set(keeper.LegacyKey(types.AllowanceSpendingKeyPrefix, long, short), suite.app.AppCodec().MustMarshalBinaryBare(&spending))
+
+	suite.Require().NoError(migrator.MigrateLegacyStore(ctx))
+
+	// only the keys of the current layout are left
+	queueKey := types.FeeAllowanceQueueKey(exp, long, short)
+	expected = append(expected,
+		queueKey,
+		types.ExpiryWarningKey(queueKey),
+		types.FeeAllowanceHeightQueueKey(20, short, other),
+		types.AllowanceSpendingKey(short, long),
+	)
+	for _, grant := range grants {
+		expected = append(expected,
+			types.FeeAllowanceKey(grant.Granter, grant.Grantee),
+			types.FeeAllowanceByGranterKey(grant.Granter, grant.Grantee),
+		)
+	}
+	suite.Require().ElementsMatch(expected, suite.storeKeys(ctx, nil))
+
+	// running it again changes nothing
+	suite.Require().NoError(migrator.MigrateLegacyStore(ctx))
+	suite.Require().ElementsMatch(expected, suite.storeKeys(ctx, nil))
+
+	for _, grant := range append(grants, current) {
+		stored, found, err := k.GetFeeGrant(ctx, grant.Granter, grant.Grantee)
+		suite.Require().NoError(err)
+		suite.Require().True(found)
+		suite.Require().Equal(grant, stored)
+	}
+	suite.Require().Equal(uint64(5), k.GetGrantsCount(ctx))
+
+	stored, found := k.GetAllowanceSpending(ctx, short, long)
+	suite.Require().True(found)
+	suite.Require().Equal(spending, stored)
+
+	// the grants to an address are not mixed up with the grants to the longer
+	// address starting with it
+	res, err := k.Allowances(sdk.WrapSDKContext(ctx), &types.QueryAllowancesRequest{Grantee: suite.addrs[2].String()})
+	suite.Require().NoError(err)
+	suite.Require().Len(res.Allowances, 1)
+	suite.Require().Equal(long.String(), res.Allowances[0].Granter)
+
+	// the grants are pruned once expired
+	k.RemoveExpiredAllowances(ctx.WithBlockHeight(21).WithBlockTime(exp.Add(time.Second)))
+	suite.Require().Equal(uint64(3), k.GetGrantsCount(ctx))
+	suite.Require().False(store.Has(queueKey))
+	suite.Require().False(store.Has(types.ExpiryWarningKey(queueKey)))
+}
+
+func (suite *KeeperTestSuite) TestMigrateLegacyStoreUnmigrated() {
+	ctx := suite.sdkCtx
+	k := suite.app.FeeGrantKeeper
+	store := ctx.KVStore(suite.app.GetKey(types.StoreKey))
+	migrator := keeper.NewMigrator(k)
+
+	allowance := &types.BasicAllowance{}
+	bz, err := k.MarshalFeeAllowance(allowance)
+	suite.Require().NoError(err)
+
+	// a grant which can be migrated
+	store.Set(keeper.LegacyKey(types.FeeAllowanceKeyPrefix, suite.addrs[1], suite.addrs[0]), bz)
+	store.Set(keeper.LegacyKey(types.FeeAllowanceByGranterKeyPrefix, suite.addrs[0], suite.addrs[1]), []byte{})
+
+	// a grant without a granter index entry, along with its expiration queue
+	// entry
+	exp := ctx.BlockTime().Add(time.Hour)
+	orphanKey := keeper.LegacyKey(types.FeeAllowanceKeyPrefix, suite.addrs[3], suite.addrs[2])
+	orphanQueueKey := keeper.LegacyKey(types.FeeAllowanceByQueueKey(exp), suite.addrs[3], suite.addrs[2])
+	store.Set(orphanKey, bz)
+	store.Set(orphanQueueKey, []byte{})
+
+	// a grant whose key can be split at two places matching granter index
+	// entries
+	ambiguousKey := keeper.LegacyKey(types.FeeAllowanceKeyPrefix, suite.addrs[2], suite.addrs[0])
+	rest := ambiguousKey[len(types.FeeAllowanceKeyPrefix):]
+	ambiguousIndexKeys := [][]byte{
+		keeper.LegacyKey(types.FeeAllowanceByGranterKeyPrefix, suite.addrs[0], suite.addrs[2]),
+		keeper.LegacyKey(types.FeeAllowanceByGranterKeyPrefix, rest[32:], rest[:32]),
+	}
+	store.Set(ambiguousKey, bz)
+	for _, key := range ambiguousIndexKeys {
+		store.Set(key, []byte{})
+	}
+
+	// the upgrade goes on, the keys which cannot be migrated being moved aside
+	suite.Require().NoError(migrator.MigrateLegacyStore(ctx))
+
+	stored, found, err := k.GetFeeGrant(ctx, suite.addrs[0], suite.addrs[1])
+	suite.Require().NoError(err)
+	suite.Require().True(found)
+	suite.Require().Equal(types.Grant{Granter: suite.addrs[0], Grantee: suite.addrs[1], Allowance: allowance}, stored)
+	suite.Require().Equal(uint64(1), k.GetGrantsCount(ctx))
+
+	unmigrated := [][]byte{orphanKey, orphanQueueKey, ambiguousKey, ambiguousIndexKeys[0], ambiguousIndexKeys[1]}
+	var expected [][]byte
+	for _, key := range unmigrated {
+		suite.Require().False(store.Has(key))
+		expected = append(expected, append(append([]byte{}, types.UnmigratedKeyPrefix...), key...))
+	}
+	suite.Require().ElementsMatch(expected, suite.storeKeys(ctx, types.UnmigratedKeyPrefix))
+
+	// running it again changes nothing
+	suite.Require().NoError(migrator.MigrateLegacyStore(ctx))
+	suite.Require().ElementsMatch(expected, suite.storeKeys(ctx, types.UnmigratedKeyPrefix))
+	suite.Require().Equal(uint64(1), k.GetGrantsCount(ctx))
+
+	// the grants left can all be iterated and pruned
+	var count int
+	suite.Require().NoError(k.IterateAllFeeAllowances(ctx, func(types.FeeAllowanceGrant) bool {
+		count++
+		return false
+	}))
+	suite.Require().Equal(1, count)
+	k.RemoveExpiredAllowances(ctx.WithBlockTime(exp.Add(time.Second)))
+}
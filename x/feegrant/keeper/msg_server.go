@@ -0,0 +1,242 @@
+package keeper
+
+import (
+	"context"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+type msgServer struct {
+	Keeper
+}
+
+// NewMsgServerImpl returns an implementation of the feegrant MsgServer interface
+// for the provided Keeper.
+func NewMsgServerImpl(k Keeper) types.MsgServer {
+	return &msgServer{
+		Keeper: k,
+	}
+}
+
+var _ types.MsgServer = msgServer{}
+
+// GrantFeeAllowance implements the MsgServer.GrantFeeAllowance method.
+func (k msgServer) GrantFeeAllowance(goCtx context.Context, msg *types.MsgGrantFeeAllowance) (*types.MsgGrantFeeAllowanceResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	grantee, err := sdk.AccAddressFromBech32(msg.Grantee)
+	if err != nil {
+		return nil, err
+	}
+
+	granter, err := sdk.AccAddressFromBech32(msg.Granter)
+	if err != nil {
+		return nil, err
+	}
+
+	// msgs reaching the handler without going through ValidateBasic may
+	// carry a nil or malformed allowance
+	allowance, err := k.DecodeAllowance(msg.Allowance)
+	if err != nil {
+		k.Logger(ctx).Info("rejected invalid fee allowance", "granter", msg.Granter, "grantee", msg.Grantee, "err", err)
+		return nil, err
+	}
+
+	if err := types.ValidateNewAllowance(allowance); err != nil {
+		return nil, err
+	}
+
+	if err := k.checkGranterAccount(ctx, granter); err != nil {
+		return nil, err
+	}
+
+	if err := k.checkGranteeAccount(ctx, grantee); err != nil {
+		return nil, err
+	}
+
+	// replacing a grant would silently reset its state, e.g. the current
+	// period of a PeriodicFeeAllowance, so it has to go through UpdateAllowance
+	_, found, err := k.Keeper.GetFeeGrant(ctx, granter, grantee)
+	if err != nil {
+		return nil, err
+	}
+
+	if found {
+		return nil, sdkerrors.Wrapf(types.ErrFeeAllowanceExists, "granter %s, grantee %s, use MsgUpdateAllowance to replace it", granter, grantee)
+	}
+
+	if err := k.Keeper.GrantFeeAllowance(ctx, granter, grantee, allowance); err != nil {
+		return nil, err
+	}
+
+	expiration, err := allowance.ExpiresAt()
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.MsgGrantFeeAllowanceResponse{Expiration: expiration}, nil
+}
+
+// RevokeFeeAllowance implements the MsgServer.RevokeFeeAllowance method.
+func (k msgServer) RevokeFeeAllowance(goCtx context.Context, msg *types.MsgRevokeFeeAllowance) (*types.MsgRevokeFeeAllowanceResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	grantee, err := sdk.AccAddressFromBech32(msg.Grantee)
+	if err != nil {
+		return nil, err
+	}
+
+	granter, err := sdk.AccAddressFromBech32(msg.Granter)
+	if err != nil {
+		return nil, err
+	}
+
+	grant, found, err := k.Keeper.GetFeeGrant(ctx, granter, grantee)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, sdkerrors.Wrapf(types.ErrNoAllowance, "granter %s, grantee %s", granter, grantee)
+	}
+
+	if err := k.Keeper.RevokeFeeAllowance(ctx, granter, grantee); err != nil {
+		return nil, err
+	}
+
+	spendLimit, err := grant.Allowance.RemainingSpendLimit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.MsgRevokeFeeAllowanceResponse{SpendLimit: spendLimit}, nil
+}
+
+// UpdateAllowance implements the MsgServer.UpdateAllowance method.
+func (k msgServer) UpdateAllowance(goCtx context.Context, msg *types.MsgUpdateAllowance) (*types.MsgUpdateAllowanceResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	grantee, err := sdk.AccAddressFromBech32(msg.Grantee)
+	if err != nil {
+		return nil, err
+	}
+
+	granter, err := sdk.AccAddressFromBech32(msg.Granter)
+	if err != nil {
+		return nil, err
+	}
+
+	allowance, err := k.DecodeAllowance(msg.Allowance)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := types.ValidateNewAllowance(allowance); err != nil {
+		return nil, err
+	}
+
+	if err := k.checkGranterAccount(ctx, granter); err != nil {
+		return nil, err
+	}
+
+	if err := k.Keeper.UpdateFeeAllowance(ctx, granter, grantee, allowance); err != nil {
+		return nil, err
+	}
+
+	return &types.MsgUpdateAllowanceResponse{}, nil
+}
+
+// GrantAllowances implements the MsgServer.GrantAllowances method.
+func (k msgServer) GrantAllowances(goCtx context.Context, msg *types.MsgGrantAllowances) (*types.MsgGrantAllowancesResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	granter, err := sdk.AccAddressFromBech32(msg.Granter)
+	if err != nil {
+		return nil, err
+	}
+
+	allowance, err := k.DecodeAllowance(msg.Allowance)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := types.ValidateNewAllowance(allowance); err != nil {
+		return nil, err
+	}
+
+	if err := k.checkGranterAccount(ctx, granter); err != nil {
+		return nil, err
+	}
+
+	// grant on a cached context so that nothing is stored unless every grant
+	// succeeds
+	cacheCtx, write := ctx.CacheContext()
+	for _, granteeStr := range msg.Grantees {
+		grantee, err := sdk.AccAddressFromBech32(granteeStr)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := k.checkGranteeAccount(cacheCtx, grantee); err != nil {
+			return nil, err
+		}
+
+		_, found, err := k.Keeper.GetFeeGrant(cacheCtx, granter, grantee)
+		if err != nil {
+			return nil, err
+		}
+
+		if found {
+			return nil, sdkerrors.Wrapf(types.ErrFeeAllowanceExists, "granter %s, grantee %s", granter, grantee)
+		}
+
+		if err := k.Keeper.GrantFeeAllowance(cacheCtx, granter, grantee, allowance); err != nil {
+			return nil, err
+		}
+	}
+	write()
+
+	return &types.MsgGrantAllowancesResponse{}, nil
+}
+
+// RevokeAllowancesByGranter implements the MsgServer.RevokeAllowancesByGranter method.
+func (k msgServer) RevokeAllowancesByGranter(goCtx context.Context, msg *types.MsgRevokeAllowancesByGranter) (*types.MsgRevokeAllowancesByGranterResponse, error) {
+	ctx := sdk.UnwrapSDKContext(goCtx)
+
+	granter, err := sdk.AccAddressFromBech32(msg.Granter)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := k.Keeper.RevokeAllowancesByGranter(ctx, granter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.MsgRevokeAllowancesByGranterResponse{Revoked: revoked}, nil
+}
+
+// checkGranterAccount rejects a module account granter whose module is not
+// registered with the account keeper, as the fees it grants could not be sent
+// out of its account.
+func (k msgServer) checkGranterAccount(ctx sdk.Context, granter sdk.AccAddress) error {
+	macc, ok := k.authKeeper.GetAccount(ctx, granter).(authtypes.ModuleAccountI)
+	if !ok || granter.Equals(k.authKeeper.GetModuleAddress(macc.GetName())) {
+		return nil
+	}
+
+	return sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "module account %s cannot pay fees", macc.GetName())
+}
+
+// checkGranteeAccount rejects a grantee without an account, unless the
+// AllowGrantToUnknownAccounts param allows granting to it.
+func (k msgServer) checkGranteeAccount(ctx sdk.Context, grantee sdk.AccAddress) error {
+	if k.GetParams(ctx).AllowGrantToUnknownAccounts || k.authKeeper.GetAccount(ctx, grantee) != nil {
+		return nil
+	}
+
+	return sdkerrors.Wrapf(sdkerrors.ErrUnknownAddress, "grantee %s has no account", grantee)
+}
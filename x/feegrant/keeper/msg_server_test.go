@@ -0,0 +1,438 @@
+package keeper_test
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/tendermint/tendermint/libs/log"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+func (suite *KeeperTestSuite) TestGrantFeeAllowance() {
+	ctx := sdk.WrapSDKContext(suite.sdkCtx)
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	expiration := suite.sdkCtx.BlockTime().Add(time.Hour).UTC()
+
+	testCases := []struct {
+		name      string
+		allowance types.FeeAllowanceI
+		expExp    *time.Time
+	}{
+		{
+			"basic allowance with expiration",
+			&types.BasicAllowance{SpendLimit: atom, Expiration: &expiration},
+			&expiration,
+		},
+		{
+			"periodic allowance with expiration",
+			&types.PeriodicFeeAllowance{
+				Basic:            types.BasicAllowance{SpendLimit: atom, Expiration: &expiration},
+				Period:           time.Hour,
+				PeriodSpendLimit: atom,
+			},
+			&expiration,
+		},
+		{
+			"allowance without expiration",
+			&types.BasicAllowance{SpendLimit: atom},
+			nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		grantee := suite.addrs[i+1]
+		suite.Run(tc.name, func() {
+			msg, err := types.NewMsgGrantFeeAllowance(tc.allowance, suite.addrs[0], grantee)
+			suite.Require().NoError(err)
+
+			res, err := suite.msgSrvr.GrantFeeAllowance(ctx, msg)
+			suite.Require().NoError(err)
+			suite.Require().Equal(tc.expExp, res.Expiration)
+		})
+	}
+}
+
+func (suite *KeeperTestSuite) TestGrantFeeAllowanceExisting() {
+	ctx := sdk.WrapSDKContext(suite.sdkCtx)
+	granter, grantee := suite.addrs[0], suite.addrs[1]
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+
+	first := &types.PeriodicFeeAllowance{
+		Basic:            types.BasicAllowance{SpendLimit: atom},
+		Period:           time.Hour,
+		PeriodReset:      suite.sdkCtx.BlockTime().Add(time.Hour),
+		PeriodSpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 100)),
+		PeriodCanSpend:   sdk.NewCoins(sdk.NewInt64Coin("atom", 40)),
+	}
+	msg, err := types.NewMsgGrantFeeAllowance(first, granter, grantee)
+	suite.Require().NoError(err)
+	_, err = suite.msgSrvr.GrantFeeAllowance(ctx, msg)
+	suite.Require().NoError(err)
+
+	// granting again is rejected and leaves the period state untouched
+	msg, err = types.NewMsgGrantFeeAllowance(&types.BasicAllowance{SpendLimit: atom}, granter, grantee)
+	suite.Require().NoError(err)
+	_, err = suite.msgSrvr.GrantFeeAllowance(ctx, msg)
+	suite.Require().True(errors.Is(err, types.ErrFeeAllowanceExists))
+
+	allowance, err := suite.app.FeeGrantKeeper.GetFeeAllowance(suite.sdkCtx, granter, grantee)
+	suite.Require().NoError(err)
+	suite.Require().Equal(first, allowance)
+
+	// the allowance can only be replaced through UpdateAllowance
+	update, err := types.NewMsgUpdateAllowance(&types.BasicAllowance{SpendLimit: atom}, granter, grantee)
+	suite.Require().NoError(err)
+	_, err = suite.msgSrvr.UpdateAllowance(ctx, update)
+	suite.Require().NoError(err)
+
+	allowance, err = suite.app.FeeGrantKeeper.GetFeeAllowance(suite.sdkCtx, granter, grantee)
+	suite.Require().NoError(err)
+	suite.Require().Equal(&types.BasicAllowance{SpendLimit: atom}, allowance)
+}
+
+func (suite *KeeperTestSuite) TestGrantFeeAllowanceInvalid() {
+	ctx := sdk.WrapSDKContext(suite.sdkCtx)
+
+	// unsorted coins are rejected before anything is stored
+	unsorted := sdk.Coins{sdk.NewInt64Coin("eth", 123), sdk.NewInt64Coin("atom", 555)}
+	msg, err := types.NewMsgGrantFeeAllowance(&types.BasicAllowance{SpendLimit: unsorted}, suite.addrs[0], suite.addrs[1])
+	suite.Require().NoError(err)
+
+	_, err = suite.msgSrvr.GrantFeeAllowance(ctx, msg)
+	suite.Require().True(errors.Is(err, sdkerrors.ErrInvalidCoins))
+
+	allowance, err := suite.app.FeeGrantKeeper.GetFeeAllowance(suite.sdkCtx, suite.addrs[0], suite.addrs[1])
+	suite.Require().NoError(err)
+	suite.Require().Nil(allowance)
+}
+
+func (suite *KeeperTestSuite) TestGrantFeeAllowancePeriodLimit() {
+	ctx := sdk.WrapSDKContext(suite.sdkCtx)
+
+	// a period limit above the spend limit could never be spent in full
+	allowance := &types.PeriodicFeeAllowance{
+		Basic:            types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 10))},
+		Period:           time.Hour,
+		PeriodSpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 20)),
+	}
+	msg, err := types.NewMsgGrantFeeAllowance(allowance, suite.addrs[0], suite.addrs[1])
+	suite.Require().NoError(err)
+
+	_, err = suite.msgSrvr.GrantFeeAllowance(ctx, msg)
+	suite.Require().True(errors.Is(err, sdkerrors.ErrInvalidCoins))
+
+	stored, err := suite.app.FeeGrantKeeper.GetFeeAllowance(suite.sdkCtx, suite.addrs[0], suite.addrs[1])
+	suite.Require().NoError(err)
+	suite.Require().Nil(stored)
+}
+
+func (suite *KeeperTestSuite) TestGrantFeeAllowanceUnknownGrantee() {
+	ctx := sdk.WrapSDKContext(suite.sdkCtx)
+	k := suite.app.FeeGrantKeeper
+	allowance := &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 555))}
+	known := suite.addrs[1]
+	unknown := sdk.AccAddress(bytes.Repeat([]byte{0xab}, sdk.AddrLen))
+	suite.Require().Nil(suite.app.AccountKeeper.GetAccount(suite.sdkCtx, unknown))
+
+	for _, allowUnknown := range []bool{true, false} {
+		params := k.GetParams(suite.sdkCtx)
+		params.AllowGrantToUnknownAccounts = allowUnknown
+		k.SetParams(suite.sdkCtx, params)
+
+		for _, grantee := range []sdk.AccAddress{known, unknown} {
+			msg, err := types.NewMsgGrantFeeAllowance(allowance, suite.addrs[0], grantee)
+			suite.Require().NoError(err)
+
+			_, err = suite.msgSrvr.GrantFeeAllowance(ctx, msg)
+			if allowUnknown || grantee.Equals(known) {
+				suite.Require().NoError(err)
+				suite.Require().NoError(k.RevokeFeeAllowance(suite.sdkCtx, suite.addrs[0], grantee))
+			} else {
+				suite.Require().True(errors.Is(err, sdkerrors.ErrUnknownAddress))
+			}
+		}
+
+		// the same goes for grants to several grantees at once
+		msg, err := types.NewMsgGrantAllowances(allowance, suite.addrs[0], []sdk.AccAddress{known, unknown})
+		suite.Require().NoError(err)
+
+		_, err = suite.msgSrvr.GrantAllowances(ctx, msg)
+		if allowUnknown {
+			suite.Require().NoError(err)
+			suite.Require().NoError(k.RevokeFeeAllowance(suite.sdkCtx, suite.addrs[0], known))
+			suite.Require().NoError(k.RevokeFeeAllowance(suite.sdkCtx, suite.addrs[0], unknown))
+		} else {
+			suite.Require().True(errors.Is(err, sdkerrors.ErrUnknownAddress))
+		}
+	}
+
+	suite.Require().Equal(uint64(0), k.GetGrantsCount(suite.sdkCtx))
+}
+
+func (suite *KeeperTestSuite) TestGrantFeeAllowanceModuleAccount() {
+	ctx := sdk.WrapSDKContext(suite.sdkCtx)
+	allowance := &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 555))}
+	grantee := suite.addrs[1]
+
+	// a module registered with the account keeper can grant fees
+	// GetModuleAccount creates the module account if needed
+	registered := suite.app.AccountKeeper.GetModuleAccount(suite.sdkCtx, distrtypes.ModuleName).GetAddress()
+	msg, err := types.NewMsgGrantFeeAllowance(allowance, registered, grantee)
+	suite.Require().NoError(err)
+	_, err = suite.msgSrvr.GrantFeeAllowance(ctx, msg)
+	suite.Require().NoError(err)
+
+	// a module account the app does not know of could not pay them
+	macc := authtypes.NewEmptyModuleAccount("unregistered")
+	suite.app.AccountKeeper.SetAccount(suite.sdkCtx, suite.app.AccountKeeper.NewAccount(suite.sdkCtx, macc))
+
+	msg, err = types.NewMsgGrantFeeAllowance(allowance, macc.GetAddress(), grantee)
+	suite.Require().NoError(err)
+	_, err = suite.msgSrvr.GrantFeeAllowance(ctx, msg)
+	suite.Require().True(errors.Is(err, sdkerrors.ErrUnauthorized))
+
+	update, err := types.NewMsgUpdateAllowance(allowance, macc.GetAddress(), grantee)
+	suite.Require().NoError(err)
+	_, err = suite.msgSrvr.UpdateAllowance(ctx, update)
+	suite.Require().True(errors.Is(err, sdkerrors.ErrUnauthorized))
+
+	grants, err := types.NewMsgGrantAllowances(allowance, macc.GetAddress(), []sdk.AccAddress{grantee})
+	suite.Require().NoError(err)
+	_, err = suite.msgSrvr.GrantAllowances(ctx, grants)
+	suite.Require().True(errors.Is(err, sdkerrors.ErrUnauthorized))
+
+	_, found, err := suite.app.FeeGrantKeeper.GetFeeGrant(suite.sdkCtx, macc.GetAddress(), grantee)
+	suite.Require().NoError(err)
+	suite.Require().False(found)
+}
+
+func (suite *KeeperTestSuite) TestGrantFeeAllowanceType() {
+	ctx := sdk.WrapSDKContext(suite.sdkCtx)
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+
+	registered, err := codectypes.NewAnyWithValue(&types.BasicAllowance{SpendLimit: atom})
+	suite.Require().NoError(err)
+	notAllowance, err := codectypes.NewAnyWithValue(&types.MsgRevokeFeeAllowance{})
+	suite.Require().NoError(err)
+
+	testCases := []struct {
+		name      string
+		allowance *codectypes.Any
+		valid     bool
+	}{
+		{
+			"registered allowance",
+			registered,
+			true,
+		},
+		{
+			"unregistered type URL",
+			&codectypes.Any{TypeUrl: "/cosmos.feegrant.v1beta1.UnknownAllowance", Value: registered.Value},
+			false,
+		},
+		{
+			"not an allowance",
+			notAllowance,
+			false,
+		},
+		{
+			"empty type URL",
+			&codectypes.Any{},
+			false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		suite.Run(tc.name, func() {
+			msg := &types.MsgGrantFeeAllowance{
+				Granter:   suite.addrs[0].String(),
+				Grantee:   suite.addrs[1].String(),
+				Allowance: tc.allowance,
+			}
+
+			_, err := suite.msgSrvr.GrantFeeAllowance(ctx, msg)
+			_, found, getErr := suite.app.FeeGrantKeeper.GetFeeGrant(suite.sdkCtx, suite.addrs[0], suite.addrs[1])
+			suite.Require().NoError(getErr)
+
+			if tc.valid {
+				suite.Require().NoError(err)
+				suite.Require().True(found)
+
+				suite.Require().NoError(suite.app.FeeGrantKeeper.RevokeFeeAllowance(suite.sdkCtx, suite.addrs[0], suite.addrs[1]))
+			} else {
+				suite.Require().True(errors.Is(err, types.ErrInvalidAllowanceType))
+				suite.Require().False(found)
+			}
+		})
+	}
+}
+
+func (suite *KeeperTestSuite) TestGrantFeeAllowanceNil() {
+	var buf bytes.Buffer
+	ctx := suite.sdkCtx.WithLogger(log.NewTMLogger(log.NewSyncWriter(&buf)))
+	msg := &types.MsgGrantFeeAllowance{Granter: suite.addrs[0].String(), Grantee: suite.addrs[1].String()}
+
+	suite.Require().NotPanics(func() {
+		_, err := suite.msgSrvr.GrantFeeAllowance(sdk.WrapSDKContext(ctx), msg)
+		suite.Require().True(errors.Is(err, types.ErrInvalidAllowanceType))
+	})
+
+	suite.Require().Contains(buf.String(), "rejected invalid fee allowance")
+	suite.Require().Contains(buf.String(), "granter="+msg.Granter)
+	suite.Require().Contains(buf.String(), "grantee="+msg.Grantee)
+
+	_, found, err := suite.app.FeeGrantKeeper.GetFeeGrant(ctx, suite.addrs[0], suite.addrs[1])
+	suite.Require().NoError(err)
+	suite.Require().False(found)
+}
+
+func (suite *KeeperTestSuite) TestRevokeFeeAllowance() {
+	ctx := sdk.WrapSDKContext(suite.sdkCtx)
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+
+	allowance, err := types.NewAllowedMsgAllowance(&types.BasicAllowance{SpendLimit: atom}, []string{"/cosmos.gov.v1beta1.MsgVote"})
+	suite.Require().NoError(err)
+
+	grant, err := types.NewMsgGrantFeeAllowance(allowance, suite.addrs[0], suite.addrs[1])
+	suite.Require().NoError(err)
+	_, err = suite.msgSrvr.GrantFeeAllowance(ctx, grant)
+	suite.Require().NoError(err)
+
+	revoke := types.NewMsgRevokeFeeAllowance(suite.addrs[0], suite.addrs[1])
+	res, err := suite.msgSrvr.RevokeFeeAllowance(ctx, &revoke)
+	suite.Require().NoError(err)
+	suite.Require().Equal(atom, res.SpendLimit)
+
+	// revoking a second time fails as there is nothing left to revoke
+	_, err = suite.msgSrvr.RevokeFeeAllowance(ctx, &revoke)
+	suite.Require().Error(err)
+}
+
+func (suite *KeeperTestSuite) TestUpdateAllowance() {
+	ctx := sdk.WrapSDKContext(suite.sdkCtx)
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	eth := sdk.NewCoins(sdk.NewInt64Coin("eth", 123))
+
+	grant, err := types.NewMsgGrantFeeAllowance(&types.BasicAllowance{SpendLimit: atom}, suite.addrs[0], suite.addrs[1])
+	suite.Require().NoError(err)
+	_, err = suite.msgSrvr.GrantFeeAllowance(ctx, grant)
+	suite.Require().NoError(err)
+
+	testCases := []struct {
+		name     string
+		granter  sdk.AccAddress
+		grantee  sdk.AccAddress
+		expErr   error
+		expGrant types.FeeAllowanceI
+	}{
+		{
+			"update existing grant",
+			suite.addrs[0], suite.addrs[1],
+			nil,
+			&types.BasicAllowance{SpendLimit: eth},
+		},
+		{
+			"update missing grant",
+			suite.addrs[0], suite.addrs[2],
+			types.ErrNoAllowance,
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			msg, err := types.NewMsgUpdateAllowance(&types.BasicAllowance{SpendLimit: eth}, tc.granter, tc.grantee)
+			suite.Require().NoError(err)
+
+			_, err = suite.msgSrvr.UpdateAllowance(ctx, msg)
+			if tc.expErr != nil {
+				suite.Require().True(errors.Is(err, tc.expErr))
+			} else {
+				suite.Require().NoError(err)
+			}
+
+			allowance, err := suite.app.FeeGrantKeeper.GetFeeAllowance(suite.sdkCtx, tc.granter, tc.grantee)
+			suite.Require().NoError(err)
+			suite.Require().Equal(tc.expGrant, allowance)
+		})
+	}
+}
+
+func (suite *KeeperTestSuite) TestGrantAllowances() {
+	ctx := sdk.WrapSDKContext(suite.sdkCtx)
+	k := suite.app.FeeGrantKeeper
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	allowance := &types.BasicAllowance{SpendLimit: atom}
+
+	msg, err := types.NewMsgGrantAllowances(allowance, suite.addrs[0], suite.addrs[1:3])
+	suite.Require().NoError(err)
+	_, err = suite.msgSrvr.GrantAllowances(ctx, msg)
+	suite.Require().NoError(err)
+
+	for _, grantee := range suite.addrs[1:3] {
+		grant, found, err := k.GetFeeGrant(suite.sdkCtx, suite.addrs[0], grantee)
+		suite.Require().NoError(err)
+		suite.Require().True(found)
+		suite.Require().Equal(allowance, grant.Allowance)
+	}
+
+	// addrs[2] already has a grant, so addrs[3] must not get one either
+	msg, err = types.NewMsgGrantAllowances(allowance, suite.addrs[0], []sdk.AccAddress{suite.addrs[3], suite.addrs[2]})
+	suite.Require().NoError(err)
+	_, err = suite.msgSrvr.GrantAllowances(ctx, msg)
+	suite.Require().True(errors.Is(err, types.ErrFeeAllowanceExists))
+
+	_, found, err := k.GetFeeGrant(suite.sdkCtx, suite.addrs[0], suite.addrs[3])
+	suite.Require().NoError(err)
+	suite.Require().False(found)
+}
+
+func (suite *KeeperTestSuite) TestRevokeAllowancesByGranter() {
+	k := suite.app.FeeGrantKeeper
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	allowance := &types.BasicAllowance{SpendLimit: atom}
+
+	grant, err := types.NewMsgGrantAllowances(allowance, suite.addrs[0], suite.addrs[1:])
+	suite.Require().NoError(err)
+	_, err = suite.msgSrvr.GrantAllowances(sdk.WrapSDKContext(suite.sdkCtx), grant)
+	suite.Require().NoError(err)
+
+	// a grant from another granter must be left alone
+	err = k.GrantFeeAllowance(suite.sdkCtx, suite.addrs[1], suite.addrs[0], allowance)
+	suite.Require().NoError(err)
+
+	ctx := suite.sdkCtx.WithEventManager(sdk.NewEventManager())
+	revoke := types.NewMsgRevokeAllowancesByGranter(suite.addrs[0])
+	res, err := suite.msgSrvr.RevokeAllowancesByGranter(sdk.WrapSDKContext(ctx), &revoke)
+	suite.Require().NoError(err)
+	suite.Require().Equal(uint64(3), res.Revoked)
+
+	events := ctx.EventManager().Events()
+	suite.Require().Len(events, 3)
+	for i, grantee := range suite.addrs[1:] {
+		suite.Require().Equal(types.EventTypeRevokeFeeGrant, events[i].Type)
+
+		_, found, err := k.GetFeeGrant(ctx, suite.addrs[0], grantee)
+		suite.Require().NoError(err)
+		suite.Require().False(found)
+	}
+
+	_, found, err := k.GetFeeGrant(ctx, suite.addrs[1], suite.addrs[0])
+	suite.Require().NoError(err)
+	suite.Require().True(found)
+
+	// the granter has no grants left, so revoking again is a no-op
+	ctx = ctx.WithEventManager(sdk.NewEventManager())
+	res, err = suite.msgSrvr.RevokeAllowancesByGranter(sdk.WrapSDKContext(ctx), &revoke)
+	suite.Require().NoError(err)
+	suite.Require().Equal(uint64(0), res.Revoked)
+	suite.Require().Empty(ctx.EventManager().Events())
+}
@@ -0,0 +1,67 @@
+package keeper
+
+import (
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+// maxRejections is the number of grants whose last rejection is kept.
+const maxRejections = 1000
+
+// rejectionLog keeps the last rejection of the most recently rejected grants,
+// so that wallets can tell why a tx was refused sponsorship once it was run or
+// simulated. It lives in memory rather than in a store, since the state
+// changes of a tx are discarded when its fee is refused. It is thus local to
+// the node, which only records the txs it runs, and lost on restart.
+type rejectionLog struct {
+	mu         sync.Mutex
+	rejections map[string]types.SponsorshipRejection
+	// keys are the keys of rejections, the oldest first
+	keys []string
+}
+
+func newRejectionLog() *rejectionLog {
+	return &rejectionLog{rejections: make(map[string]types.SponsorshipRejection)}
+}
+
+// record sets the last rejection of the grant at key, dropping the oldest
+// rejection if the log is full.
+func (l *rejectionLog) record(key []byte, rejection types.SponsorshipRejection) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.rejections[string(key)]; !ok {
+		if len(l.keys) == maxRejections {
+			delete(l.rejections, l.keys[0])
+			l.keys = l.keys[1:]
+		}
+		l.keys = append(l.keys, string(key))
+	}
+
+	l.rejections[string(key)] = rejection
+}
+
+// get returns the last rejection of the grant at key, if it is still kept.
+func (l *rejectionLog) get(key []byte) (types.SponsorshipRejection, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rejection, ok := l.rejections[string(key)]
+	return rejection, ok
+}
+
+// RecordRejection records that the allowance granted by granter to grantee
+// refused to pay the fees of a tx run, or simulated, in the block of ctx with
+// err, see GetRejection.
+func (k Keeper) RecordRejection(ctx sdk.Context, granter, grantee sdk.AccAddress, err error, simulate bool) {
+	k.rejections.record(types.FeeAllowanceKey(granter, grantee), types.NewSponsorshipRejection(err, ctx.BlockHeight(), simulate))
+}
+
+// GetRejection returns the last rejection recorded for the allowance granted
+// by granter to grantee, if any. Only the rejections of the most recently
+// rejected grants are kept, and only by the node which ran the txs.
+func (k Keeper) GetRejection(granter, grantee sdk.AccAddress) (types.SponsorshipRejection, bool) {
+	return k.rejections.get(types.FeeAllowanceKey(granter, grantee))
+}
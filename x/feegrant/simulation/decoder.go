@@ -0,0 +1,74 @@
+package simulation
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/kv"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+// NewDecodeStore returns a decoder function closure that unmarshals the KVPair's
+// Value to the corresponding feegrant type.
+func NewDecodeStore(cdc codec.Marshaler) func(kvA, kvB kv.Pair) string {
+	return func(kvA, kvB kv.Pair) string {
+		switch {
+		case bytes.Equal(kvA.Key[:1], types.FeeAllowanceKeyPrefix):
+			var allowanceA, allowanceB types.FeeAllowanceI
+			if err := cdc.UnmarshalInterface(kvA.Value, &allowanceA); err != nil {
+				panic(err)
+			}
+			if err := cdc.UnmarshalInterface(kvB.Value, &allowanceB); err != nil {
+				panic(err)
+			}
+			granterA, granteeA := types.ParseAddressesFromFeeAllowanceKey(kvA.Key)
+			granterB, granteeB := types.ParseAddressesFromFeeAllowanceKey(kvB.Key)
+			return fmt.Sprintf("%s -> %s: %v\n%s -> %s: %v", granterA, granteeA, allowanceA, granterB, granteeB, allowanceB)
+
+		case bytes.Equal(kvA.Key[:1], types.FeeAllowanceQueueKeyPrefix):
+			return fmt.Sprintf("%s\n%s", formatQueueKey(kvA.Key), formatQueueKey(kvB.Key))
+
+		case bytes.Equal(kvA.Key[:1], types.FeeAllowanceHeightQueueKeyPrefix):
+			return fmt.Sprintf("%s\n%s", formatHeightQueueKey(kvA.Key), formatHeightQueueKey(kvB.Key))
+
+		case bytes.Equal(kvA.Key[:1], types.ExpiryWarningKeyPrefix):
+			return fmt.Sprintf("%s\n%s", formatQueueKey(kvA.Key), formatQueueKey(kvB.Key))
+
+		case bytes.Equal(kvA.Key[:1], types.FeeAllowanceByGranterKeyPrefix):
+			granterA, granteeA := types.ParseAddressesFromFeeAllowanceByGranterKey(kvA.Key)
+			granterB, granteeB := types.ParseAddressesFromFeeAllowanceByGranterKey(kvB.Key)
+			return fmt.Sprintf("%s -> %s\n%s -> %s", granterA, granteeA, granterB, granteeB)
+
+		case bytes.Equal(kvA.Key[:1], types.GrantsCountKey):
+			return fmt.Sprintf("%d\n%d", sdk.BigEndianToUint64(kvA.Value), sdk.BigEndianToUint64(kvB.Value))
+
+		case bytes.Equal(kvA.Key[:1], types.AllowanceSpendingKeyPrefix):
+			var spendingA, spendingB types.AllowanceSpending
+			cdc.MustUnmarshalBinaryBare(kvA.Value, &spendingA)
+			cdc.MustUnmarshalBinaryBare(kvB.Value, &spendingB)
+			return fmt.Sprintf("%v\n%v", spendingA, spendingB)
+
+		case bytes.Equal(kvA.Key[:1], types.UnmigratedKeyPrefix):
+			return fmt.Sprintf("%X: %X\n%X: %X", kvA.Key[1:], kvA.Value, kvB.Key[1:], kvB.Value)
+
+		default:
+			panic(fmt.Sprintf("invalid feegrant key prefix %X", kvA.Key[:1]))
+		}
+	}
+}
+
+// formatQueueKey describes the grant and expiration time of a key created by
+// types.FeeAllowanceQueueKey or types.ExpiryWarningKey, which share a layout.
+func formatQueueKey(key []byte) string {
+	granter, grantee := types.ParseAddressesFromFeeAllowanceQueueKey(key)
+	return fmt.Sprintf("%s -> %s expires at %s", granter, grantee, types.ParseExpirationFromFeeAllowanceQueueKey(key))
+}
+
+// formatHeightQueueKey describes the grant and expiration height of a key
+// created by types.FeeAllowanceHeightQueueKey.
+func formatHeightQueueKey(key []byte) string {
+	granter, grantee := types.ParseAddressesFromFeeAllowanceHeightQueueKey(key)
+	return fmt.Sprintf("%s -> %s expires after height %d", granter, grantee, types.ParseExpirationHeightFromFeeAllowanceHeightQueueKey(key))
+}
@@ -0,0 +1,132 @@
+package simulation_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/kv"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/simulation"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+var (
+	granterAddr = sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	granteeAddr = sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+)
+
+func TestDecodeFeegrantStore(t *testing.T) {
+	cdc, _ := simapp.MakeCodecs()
+	dec := simulation.NewDecodeStore(cdc)
+
+	exp := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	allowance := &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 100)), Expiration: &exp}
+	allowanceBz, err := cdc.MarshalInterface(allowance)
+	require.NoError(t, err)
+	spending := types.AllowanceSpending{Spent: sdk.NewCoins(sdk.NewInt64Coin("atom", 10))}
+
+	kvPairs := kv.Pairs{
+		Pairs: []kv.Pair{
+			{Key: types.FeeAllowanceKey(granterAddr, granteeAddr), Value: allowanceBz},
+			{Key: types.FeeAllowanceQueueKey(exp, granterAddr, granteeAddr), Value: []byte{}},
+			{Key: types.FeeAllowanceByGranterKey(granterAddr, granteeAddr), Value: []byte{}},
+			{Key: types.GrantsCountKey, Value: sdk.Uint64ToBigEndian(7)},
+			{Key: types.AllowanceSpendingKey(granterAddr, granteeAddr), Value: cdc.MustMarshalBinaryBare(&spending)},
+			{Key: types.ExpiryWarningKey(types.FeeAllowanceQueueKey(exp, granterAddr, granteeAddr)), Value: []byte{}},
+			{Key: types.FeeAllowanceHeightQueueKey(42, granterAddr, granteeAddr), Value: []byte{}},
+			{Key: append(types.UnmigratedKeyPrefix, 0x00, 0xab), Value: []byte{0xcd}},
+			{Key: []byte{0x99}, Value: []byte{0x99}},
+		},
+	}
+
+	grant := fmt.Sprintf("%s -> %s", granterAddr, granteeAddr)
+	tests := []struct {
+		name        string
+		expectedLog string
+	}{
+		{"FeeAllowance", fmt.Sprintf("%s: %v\n%s: %v", grant, allowance, grant, allowance)},
+		{"FeeAllowanceQueue", fmt.Sprintf("%s expires at %s\n%s expires at %s", grant, exp, grant, exp)},
+		{"FeeAllowanceByGranter", fmt.Sprintf("%s\n%s", grant, grant)},
+		{"GrantsCount", "7\n7"},
+		{"AllowanceSpending", fmt.Sprintf("%v\n%v", spending, spending)},
+		{"ExpiryWarning", fmt.Sprintf("%s expires at %s\n%s expires at %s", grant, exp, grant, exp)},
+		{"FeeAllowanceHeightQueue", fmt.Sprintf("%s expires after height 42\n%s expires after height 42", grant, grant)},
+		{"Unmigrated", "00AB: CD\n00AB: CD"},
+		{"other", ""},
+	}
+	for i, tt := range tests {
+		i, tt := i, tt
+		t.Run(tt.name, func(t *testing.T) {
+			switch i {
+			case len(tests) - 1:
+				require.Panics(t, func() { dec(kvPairs.Pairs[i], kvPairs.Pairs[i]) }, tt.name)
+			default:
+				require.Equal(t, tt.expectedLog, dec(kvPairs.Pairs[i], kvPairs.Pairs[i]), tt.name)
+			}
+		})
+	}
+}
+
+func TestDecodeFeegrantStoreChanged(t *testing.T) {
+	cdc, _ := simapp.MakeCodecs()
+	dec := simulation.NewDecodeStore(cdc)
+
+	otherGrantee := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	expA := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	expB := expA.Add(time.Hour)
+
+	basic := &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 100)), Expiration: &expA}
+	allowed, err := types.NewAllowedMsgAllowance(basic, []string{"/cosmos.gov.v1beta1.MsgVote"})
+	require.NoError(t, err)
+
+	basicBz, err := cdc.MarshalInterface(basic)
+	require.NoError(t, err)
+	allowedBz, err := cdc.MarshalInterface(allowed)
+	require.NoError(t, err)
+
+	grantA := fmt.Sprintf("%s -> %s", granterAddr, granteeAddr)
+	grantB := fmt.Sprintf("%s -> %s", granterAddr, otherGrantee)
+
+	tests := []struct {
+		name        string
+		kvA, kvB    kv.Pair
+		expectedLog string
+	}{
+		{
+			"FeeAllowance",
+			kv.Pair{Key: types.FeeAllowanceKey(granterAddr, granteeAddr), Value: basicBz},
+			kv.Pair{Key: types.FeeAllowanceKey(granterAddr, otherGrantee), Value: allowedBz},
+			fmt.Sprintf("%s: %v\n%s: %v", grantA, basic, grantB, allowed),
+		},
+		{
+			"FeeAllowanceQueue",
+			kv.Pair{Key: types.FeeAllowanceQueueKey(expA, granterAddr, granteeAddr), Value: []byte{}},
+			kv.Pair{Key: types.FeeAllowanceQueueKey(expB, granterAddr, otherGrantee), Value: []byte{}},
+			fmt.Sprintf("%s expires at %s\n%s expires at %s", grantA, expA, grantB, expB),
+		},
+		{
+			"FeeAllowanceByGranter",
+			kv.Pair{Key: types.FeeAllowanceByGranterKey(granterAddr, granteeAddr), Value: []byte{}},
+			kv.Pair{Key: types.FeeAllowanceByGranterKey(granterAddr, otherGrantee), Value: []byte{}},
+			fmt.Sprintf("%s\n%s", grantA, grantB),
+		},
+		{
+			"GrantsCount",
+			kv.Pair{Key: types.GrantsCountKey, Value: sdk.Uint64ToBigEndian(7)},
+			kv.Pair{Key: types.GrantsCountKey, Value: sdk.Uint64ToBigEndian(8)},
+			"7\n8",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expectedLog, dec(tt.kvA, tt.kvB))
+		})
+	}
+}
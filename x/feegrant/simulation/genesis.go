@@ -0,0 +1,65 @@
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/types/module"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+// Simulation parameter constants
+const (
+	MaxGrantsPerGranter         = "max_grants_per_granter"
+	AllowGrantToUnknownAccounts = "allow_grant_to_unknown_accounts"
+	ExpiryWarningWindow         = "expiry_warning_window"
+)
+
+// GenMaxGrantsPerGranter randomized MaxGrantsPerGranter
+func GenMaxGrantsPerGranter(r *rand.Rand) uint64 {
+	return uint64(r.Intn(int(types.DefaultMaxGrantsPerGranter)) + 1)
+}
+
+// GenAllowGrantToUnknownAccounts randomized AllowGrantToUnknownAccounts
+func GenAllowGrantToUnknownAccounts(r *rand.Rand) bool {
+	return r.Intn(2) == 0
+}
+
+// GenExpiryWarningWindow randomized ExpiryWarningWindow
+func GenExpiryWarningWindow(r *rand.Rand) time.Duration {
+	return time.Duration(r.Intn(60*60*24)) * time.Second
+}
+
+// RandomizedGenState generates a random GenesisState for feegrant
+func RandomizedGenState(simState *module.SimulationState) {
+	var maxGrantsPerGranter uint64
+	simState.AppParams.GetOrGenerate(
+		simState.Cdc, MaxGrantsPerGranter, &maxGrantsPerGranter, simState.Rand,
+		func(r *rand.Rand) { maxGrantsPerGranter = GenMaxGrantsPerGranter(r) },
+	)
+
+	var allowGrantToUnknownAccounts bool
+	simState.AppParams.GetOrGenerate(
+		simState.Cdc, AllowGrantToUnknownAccounts, &allowGrantToUnknownAccounts, simState.Rand,
+		func(r *rand.Rand) { allowGrantToUnknownAccounts = GenAllowGrantToUnknownAccounts(r) },
+	)
+
+	var expiryWarningWindow time.Duration
+	simState.AppParams.GetOrGenerate(
+		simState.Cdc, ExpiryWarningWindow, &expiryWarningWindow, simState.Rand,
+		func(r *rand.Rand) { expiryWarningWindow = GenExpiryWarningWindow(r) },
+	)
+
+	feegrantGenesis := types.NewGenesisState(
+		types.NewParams(maxGrantsPerGranter, allowGrantToUnknownAccounts, expiryWarningWindow), []types.FeeAllowanceGrant{}, []types.AllowanceSpendingRecord{},
+	)
+
+	bz, err := json.MarshalIndent(&feegrantGenesis, "", " ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Selected randomly generated feegrant parameters:\n%s\n", bz)
+	simState.GenState[types.ModuleName] = simState.Cdc.MustMarshalJSON(feegrantGenesis)
+}
@@ -0,0 +1,201 @@
+package simulation
+
+import (
+	"math/rand"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/simapp/helpers"
+	simappparams "github.com/cosmos/cosmos-sdk/simapp/params"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/keeper"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+	"github.com/cosmos/cosmos-sdk/x/simulation"
+)
+
+// Simulation operation weights constants
+const (
+	OpWeightMsgGrantFeeAllowance  = "op_weight_msg_grant_fee_allowance"
+	OpWeightMsgRevokeFeeAllowance = "op_weight_msg_revoke_fee_allowance"
+)
+
+// WeightedOperations returns all the operations from the module with their respective weights
+func WeightedOperations(
+	appParams simtypes.AppParams, cdc codec.JSONMarshaler, ak types.AccountKeeper,
+	bk types.BankKeeper, k keeper.Keeper,
+) simulation.WeightedOperations {
+
+	var weightMsgGrantFeeAllowance int
+	appParams.GetOrGenerate(cdc, OpWeightMsgGrantFeeAllowance, &weightMsgGrantFeeAllowance, nil,
+		func(_ *rand.Rand) {
+			weightMsgGrantFeeAllowance = simappparams.DefaultWeightGrantFeeAllowance
+		},
+	)
+
+	var weightMsgRevokeFeeAllowance int
+	appParams.GetOrGenerate(cdc, OpWeightMsgRevokeFeeAllowance, &weightMsgRevokeFeeAllowance, nil,
+		func(_ *rand.Rand) {
+			weightMsgRevokeFeeAllowance = simappparams.DefaultWeightRevokeFeeAllowance
+		},
+	)
+
+	return simulation.WeightedOperations{
+		simulation.NewWeightedOperation(
+			weightMsgGrantFeeAllowance,
+			SimulateMsgGrantFeeAllowance(ak, bk, k),
+		),
+		simulation.NewWeightedOperation(
+			weightMsgRevokeFeeAllowance,
+			SimulateMsgRevokeFeeAllowance(ak, bk, k),
+		),
+	}
+}
+
+// SimulateMsgGrantFeeAllowance generates a MsgGrantFeeAllowance with random values.
+func SimulateMsgGrantFeeAllowance(ak types.AccountKeeper, bk types.BankKeeper, k keeper.Keeper) simtypes.Operation {
+	return func(
+		r *rand.Rand, app *baseapp.BaseApp, ctx sdk.Context, accs []simtypes.Account, chainID string,
+	) (simtypes.OperationMsg, []simtypes.FutureOperation, error) {
+		granter, _ := simtypes.RandomAcc(r, accs)
+		grantee, _ := simtypes.RandomAcc(r, accs)
+		if granter.Address.Equals(grantee.Address) {
+			return simtypes.NoOpMsg(types.ModuleName, types.TypeMsgGrantFeeAllowance, "grantee and granter cannot be same"), nil, nil
+		}
+
+		feeAllowance, err := k.GetFeeAllowance(ctx, granter.Address, grantee.Address)
+		if err != nil {
+			return simtypes.NoOpMsg(types.ModuleName, types.TypeMsgGrantFeeAllowance, "unable to get fee allowance"), nil, err
+		}
+
+		if feeAllowance != nil {
+			return simtypes.NoOpMsg(types.ModuleName, types.TypeMsgGrantFeeAllowance, "fee allowance already exists"), nil, nil
+		}
+
+		if countGrantsByGranter(ctx, k, granter.Address) >= k.GetParams(ctx).MaxGrantsPerGranter {
+			return simtypes.NoOpMsg(types.ModuleName, types.TypeMsgGrantFeeAllowance, "granter has too many grants"), nil, nil
+		}
+
+		account := ak.GetAccount(ctx, granter.Address)
+		spendable := bk.SpendableCoins(ctx, account.GetAddress())
+
+		fees, err := simtypes.RandomFees(r, ctx, spendable)
+		if err != nil {
+			return simtypes.NoOpMsg(types.ModuleName, types.TypeMsgGrantFeeAllowance, "unable to generate fees"), nil, err
+		}
+
+		spendLimit, err := simtypes.RandomFees(r, ctx, spendable.Sub(fees))
+		if err != nil {
+			return simtypes.NoOpMsg(types.ModuleName, types.TypeMsgGrantFeeAllowance, "unable to generate spend limit"), nil, err
+		}
+
+		msg, err := types.NewMsgGrantFeeAllowance(&types.BasicAllowance{SpendLimit: spendLimit}, granter.Address, grantee.Address)
+		if err != nil {
+			return simtypes.NoOpMsg(types.ModuleName, types.TypeMsgGrantFeeAllowance, "unable to build msg"), nil, err
+		}
+
+		txGen := simappparams.MakeTestEncodingConfig().TxConfig
+		tx, err := helpers.GenTx(
+			txGen,
+			[]sdk.Msg{msg},
+			fees,
+			helpers.DefaultGenTxGas,
+			chainID,
+			[]uint64{account.GetAccountNumber()},
+			[]uint64{account.GetSequence()},
+			granter.PrivKey,
+		)
+		if err != nil {
+			return simtypes.NoOpMsg(types.ModuleName, msg.Type(), "unable to generate mock tx"), nil, err
+		}
+
+		_, _, err = app.Deliver(txGen.TxEncoder(), tx)
+		if err != nil {
+			return simtypes.NoOpMsg(types.ModuleName, msg.Type(), "unable to deliver tx"), nil, err
+		}
+
+		return simtypes.NewOperationMsg(msg, true, ""), nil, nil
+	}
+}
+
+// SimulateMsgRevokeFeeAllowance generates a MsgRevokeFeeAllowance revoking a
+// random existing grant.
+func SimulateMsgRevokeFeeAllowance(ak types.AccountKeeper, bk types.BankKeeper, k keeper.Keeper) simtypes.Operation {
+	return func(
+		r *rand.Rand, app *baseapp.BaseApp, ctx sdk.Context, accs []simtypes.Account, chainID string,
+	) (simtypes.OperationMsg, []simtypes.FutureOperation, error) {
+		var grants []types.FeeAllowanceGrant
+		err := k.IterateAllFeeAllowances(ctx, func(grant types.FeeAllowanceGrant) bool {
+			grants = append(grants, grant)
+			return false
+		})
+		if err != nil {
+			return simtypes.NoOpMsg(types.ModuleName, types.TypeMsgRevokeFeeAllowance, "unable to iterate fee allowances"), nil, err
+		}
+
+		if len(grants) == 0 {
+			return simtypes.NoOpMsg(types.ModuleName, types.TypeMsgRevokeFeeAllowance, "no grants"), nil, nil
+		}
+
+		grant := grants[r.Intn(len(grants))]
+		granterAddr, err := sdk.AccAddressFromBech32(grant.Granter)
+		if err != nil {
+			return simtypes.NoOpMsg(types.ModuleName, types.TypeMsgRevokeFeeAllowance, "invalid granter"), nil, err
+		}
+		granteeAddr, err := sdk.AccAddressFromBech32(grant.Grantee)
+		if err != nil {
+			return simtypes.NoOpMsg(types.ModuleName, types.TypeMsgRevokeFeeAllowance, "invalid grantee"), nil, err
+		}
+
+		granter, found := simtypes.FindAccount(accs, granterAddr)
+		if !found {
+			return simtypes.NoOpMsg(types.ModuleName, types.TypeMsgRevokeFeeAllowance, "granter account not found"), nil, nil
+		}
+
+		account := ak.GetAccount(ctx, granter.Address)
+		spendable := bk.SpendableCoins(ctx, account.GetAddress())
+
+		fees, err := simtypes.RandomFees(r, ctx, spendable)
+		if err != nil {
+			return simtypes.NoOpMsg(types.ModuleName, types.TypeMsgRevokeFeeAllowance, "unable to generate fees"), nil, err
+		}
+
+		msg := types.NewMsgRevokeFeeAllowance(granterAddr, granteeAddr)
+
+		txGen := simappparams.MakeTestEncodingConfig().TxConfig
+		tx, err := helpers.GenTx(
+			txGen,
+			[]sdk.Msg{&msg},
+			fees,
+			helpers.DefaultGenTxGas,
+			chainID,
+			[]uint64{account.GetAccountNumber()},
+			[]uint64{account.GetSequence()},
+			granter.PrivKey,
+		)
+		if err != nil {
+			return simtypes.NoOpMsg(types.ModuleName, msg.Type(), "unable to generate mock tx"), nil, err
+		}
+
+		_, _, err = app.Deliver(txGen.TxEncoder(), tx)
+		if err != nil {
+			return simtypes.NoOpMsg(types.ModuleName, msg.Type(), "unable to deliver tx"), nil, err
+		}
+
+		return simtypes.NewOperationMsg(&msg, true, ""), nil, nil
+	}
+}
+
+// countGrantsByGranter returns the number of grants from granter.
+func countGrantsByGranter(ctx sdk.Context, k keeper.Keeper, granter sdk.AccAddress) uint64 {
+	res, err := k.AllowancesByGranter(sdk.WrapSDKContext(ctx), &types.QueryAllowancesByGranterRequest{
+		Granter:    granter.String(),
+		Pagination: &query.PageRequest{Limit: 1, CountTotal: true},
+	})
+	if err != nil {
+		return 0
+	}
+
+	return res.Pagination.Total
+}
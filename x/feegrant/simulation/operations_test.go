@@ -0,0 +1,168 @@
+package simulation_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/simapp"
+	simappparams "github.com/cosmos/cosmos-sdk/simapp/params"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/simulation"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+type SimTestSuite struct {
+	suite.Suite
+
+	ctx sdk.Context
+	app *simapp.SimApp
+}
+
+func (suite *SimTestSuite) SetupTest() {
+	checkTx := false
+	app := simapp.Setup(checkTx)
+	suite.app = app
+	suite.ctx = app.BaseApp.NewContext(checkTx, tmproto.Header{})
+}
+
+// TestWeightedOperations tests the weights of the operations.
+func (suite *SimTestSuite) TestWeightedOperations() {
+	cdc := suite.app.AppCodec()
+	appParams := make(simtypes.AppParams)
+
+	weightedOps := simulation.WeightedOperations(appParams, cdc, suite.app.AccountKeeper, suite.app.BankKeeper, suite.app.FeeGrantKeeper)
+
+	s := rand.NewSource(1)
+	r := rand.New(s)
+	accs := suite.getTestingAccounts(r, 3)
+
+	expected := []struct {
+		weight     int
+		opMsgRoute string
+		opMsgName  string
+	}{
+		{simappparams.DefaultWeightGrantFeeAllowance, types.ModuleName, types.TypeMsgGrantFeeAllowance},
+		{simappparams.DefaultWeightRevokeFeeAllowance, types.ModuleName, types.TypeMsgRevokeFeeAllowance},
+	}
+
+	for i, w := range weightedOps {
+		operationMsg, _, _ := w.Op()(r, suite.app.BaseApp, suite.ctx, accs, "")
+		// the following checks are very much dependent from the ordering of the output given
+		// by WeightedOperations. if the ordering in WeightedOperations changes some tests
+		// will fail
+		suite.Require().Equal(expected[i].weight, w.Weight(), "weight should be the same")
+		suite.Require().Equal(expected[i].opMsgRoute, operationMsg.Route, "route should be the same")
+		suite.Require().Equal(expected[i].opMsgName, operationMsg.Name, "operation Msg name should be the same")
+	}
+}
+
+// TestWeightedOperationsConfigured tests that the weights can be set through the app params.
+func (suite *SimTestSuite) TestWeightedOperationsConfigured() {
+	cdc := suite.app.AppCodec()
+	appParams := simtypes.AppParams{
+		simulation.OpWeightMsgGrantFeeAllowance:  []byte("7"),
+		simulation.OpWeightMsgRevokeFeeAllowance: []byte("3"),
+	}
+
+	weightedOps := simulation.WeightedOperations(appParams, cdc, suite.app.AccountKeeper, suite.app.BankKeeper, suite.app.FeeGrantKeeper)
+
+	suite.Require().Len(weightedOps, 2)
+	suite.Require().Equal(7, weightedOps[0].Weight())
+	suite.Require().Equal(3, weightedOps[1].Weight())
+}
+
+// TestSimulateMsgGrantFeeAllowance tests the normal scenario of a valid message of type TypeMsgGrantFeeAllowance.
+// Abonormal scenarios, where the message is created by an errors, are not tested here.
+func (suite *SimTestSuite) TestSimulateMsgGrantFeeAllowance() {
+	s := rand.NewSource(1)
+	r := rand.New(s)
+	accounts := suite.getTestingAccounts(r, 3)
+
+	// begin a new block
+	suite.app.BeginBlock(abci.RequestBeginBlock{Header: tmproto.Header{Height: suite.app.LastBlockHeight() + 1, AppHash: suite.app.LastCommitID().Hash}})
+
+	var (
+		operationMsg     simtypes.OperationMsg
+		futureOperations []simtypes.FutureOperation
+		err              error
+	)
+
+	// retry until distinct granter and grantee accounts are picked
+	op := simulation.SimulateMsgGrantFeeAllowance(suite.app.AccountKeeper, suite.app.BankKeeper, suite.app.FeeGrantKeeper)
+	for !operationMsg.OK {
+		operationMsg, futureOperations, err = op(r, suite.app.BaseApp, suite.ctx, accounts, "")
+		suite.Require().NoError(err)
+	}
+
+	var msg types.MsgGrantFeeAllowance
+	types.ModuleCdc.UnmarshalJSON(operationMsg.Msg, &msg)
+
+	suite.Require().NotEqual(msg.Granter, msg.Grantee)
+	suite.Require().Equal(types.TypeMsgGrantFeeAllowance, operationMsg.Name)
+	suite.Require().Equal(types.ModuleName, operationMsg.Route)
+	suite.Require().Len(futureOperations, 0)
+
+	granter, _ := sdk.AccAddressFromBech32(msg.Granter)
+	grantee, _ := sdk.AccAddressFromBech32(msg.Grantee)
+	feeAllowance, err := suite.app.FeeGrantKeeper.GetFeeAllowance(suite.ctx, granter, grantee)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(feeAllowance)
+}
+
+// TestSimulateMsgRevokeFeeAllowance tests the normal scenario of a valid message of type TypeMsgRevokeFeeAllowance.
+// Abonormal scenarios, where the message is created by an errors, are not tested here.
+func (suite *SimTestSuite) TestSimulateMsgRevokeFeeAllowance() {
+	s := rand.NewSource(1)
+	r := rand.New(s)
+	accounts := suite.getTestingAccounts(r, 3)
+
+	// begin a new block
+	suite.app.BeginBlock(abci.RequestBeginBlock{Header: tmproto.Header{Height: suite.app.LastBlockHeight() + 1, AppHash: suite.app.LastCommitID().Hash}})
+
+	granter, grantee := accounts[0].Address, accounts[1].Address
+	err := suite.app.FeeGrantKeeper.GrantFeeAllowance(suite.ctx, granter, grantee, &types.BasicAllowance{})
+	suite.Require().NoError(err)
+
+	op := simulation.SimulateMsgRevokeFeeAllowance(suite.app.AccountKeeper, suite.app.BankKeeper, suite.app.FeeGrantKeeper)
+	operationMsg, futureOperations, err := op(r, suite.app.BaseApp, suite.ctx, accounts, "")
+	suite.Require().NoError(err)
+
+	var msg types.MsgRevokeFeeAllowance
+	types.ModuleCdc.UnmarshalJSON(operationMsg.Msg, &msg)
+
+	suite.Require().True(operationMsg.OK)
+	suite.Require().Equal(granter.String(), msg.Granter)
+	suite.Require().Equal(grantee.String(), msg.Grantee)
+	suite.Require().Equal(types.TypeMsgRevokeFeeAllowance, operationMsg.Name)
+	suite.Require().Len(futureOperations, 0)
+
+	feeAllowance, err := suite.app.FeeGrantKeeper.GetFeeAllowance(suite.ctx, granter, grantee)
+	suite.Require().NoError(err)
+	suite.Require().Nil(feeAllowance)
+}
+
+func (suite *SimTestSuite) getTestingAccounts(r *rand.Rand, n int) []simtypes.Account {
+	accounts := simtypes.RandomAccounts(r, n)
+
+	initAmt := sdk.TokensFromConsensusPower(200)
+	initCoins := sdk.NewCoins(sdk.NewCoin(sdk.DefaultBondDenom, initAmt))
+
+	// add coins to the accounts
+	for _, account := range accounts {
+		acc := suite.app.AccountKeeper.NewAccountWithAddress(suite.ctx, account.Address)
+		suite.app.AccountKeeper.SetAccount(suite.ctx, acc)
+		err := suite.app.BankKeeper.SetBalances(suite.ctx, account.Address, initCoins)
+		suite.Require().NoError(err)
+	}
+
+	return accounts
+}
+
+func TestSimTestSuite(t *testing.T) {
+	suite.Run(t, new(SimTestSuite))
+}
@@ -0,0 +1,32 @@
+package simulation
+
+import (
+	"fmt"
+	"math/rand"
+
+	simtypes "github.com/cosmos/cosmos-sdk/types/simulation"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+	"github.com/cosmos/cosmos-sdk/x/simulation"
+)
+
+// ParamChanges defines the parameters that can be modified by param change proposals
+// on the simulation
+func ParamChanges(r *rand.Rand) []simtypes.ParamChange {
+	return []simtypes.ParamChange{
+		simulation.NewSimParamChange(types.ModuleName, string(types.KeyMaxGrantsPerGranter),
+			func(r *rand.Rand) string {
+				return fmt.Sprintf("\"%d\"", GenMaxGrantsPerGranter(r))
+			},
+		),
+		simulation.NewSimParamChange(types.ModuleName, string(types.KeyAllowGrantToUnknownAccounts),
+			func(r *rand.Rand) string {
+				return fmt.Sprintf("%v", GenAllowGrantToUnknownAccounts(r))
+			},
+		),
+		simulation.NewSimParamChange(types.ModuleName, string(types.KeyExpiryWarningWindow),
+			func(r *rand.Rand) string {
+				return fmt.Sprintf("\"%d\"", GenExpiryWarningWindow(r))
+			},
+		),
+	}
+}
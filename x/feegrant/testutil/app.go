@@ -0,0 +1,265 @@
+package testutil
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	simappparams "github.com/cosmos/cosmos-sdk/simapp/params"
+	"github.com/cosmos/cosmos-sdk/std"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	authante "github.com/cosmos/cosmos-sdk/x/auth/ante"
+	authkeeper "github.com/cosmos/cosmos-sdk/x/auth/keeper"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	bankkeeper "github.com/cosmos/cosmos-sdk/x/bank/keeper"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/ante"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/keeper"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+	"github.com/cosmos/cosmos-sdk/x/params"
+	paramskeeper "github.com/cosmos/cosmos-sdk/x/params/keeper"
+	paramstypes "github.com/cosmos/cosmos-sdk/x/params/types"
+)
+
+const (
+	chainID = "feegrant-test-chain"
+
+	// DefaultGas is the gas limit of the transactions delivered by App.
+	DefaultGas = 1000000
+)
+
+// ModuleBasics are the modules of App.
+var ModuleBasics = module.NewBasicManager(
+	auth.AppModuleBasic{}, bank.AppModuleBasic{}, params.AppModuleBasic{}, feegrant.AppModuleBasic{},
+)
+
+// App is a minimal application with only the auth, bank, params and feegrant
+// modules. It uses the feegrant AnteHandler, so that transactions delivered to
+// it exercise the same fee deduction path as a full application.
+type App struct {
+	*baseapp.BaseApp
+
+	EncodingConfig simappparams.EncodingConfig
+	AccountKeeper  authkeeper.AccountKeeper
+	BankKeeper     bankkeeper.Keeper
+	FeeGrantKeeper keeper.Keeper
+
+	mm *module.Manager
+}
+
+// Account is a genesis account that App can sign transactions for.
+type Account struct {
+	PrivKey cryptotypes.PrivKey
+	Address sdk.AccAddress
+}
+
+// MakeEncodingConfig returns the encoding config of App.
+func MakeEncodingConfig() simappparams.EncodingConfig {
+	encodingConfig := simappparams.MakeTestEncodingConfig()
+	std.RegisterLegacyAminoCodec(encodingConfig.Amino)
+	std.RegisterInterfaces(encodingConfig.InterfaceRegistry)
+	ModuleBasics.RegisterLegacyAminoCodec(encodingConfig.Amino)
+	ModuleBasics.RegisterInterfaces(encodingConfig.InterfaceRegistry)
+
+	return encodingConfig
+}
+
+// NewApp returns an App backed by an in-memory database. The chain is not
+// initialized yet, see Setup.
+func NewApp() *App {
+	return newApp(false)
+}
+
+// NewDeferredApp returns an App like NewApp's, except that fee allowances are
+// only used by its post handler, once the msgs of a transaction succeeded.
+// The chain is not initialized yet, see SetupDeferred.
+func NewDeferredApp() *App {
+	return newApp(true)
+}
+
+func newApp(deferred bool) *App {
+	encodingConfig := MakeEncodingConfig()
+	appCodec := encodingConfig.Marshaler
+
+	bApp := baseapp.NewBaseApp("feegrant-test", log.NewNopLogger(), dbm.NewMemDB(), encodingConfig.TxConfig.TxDecoder())
+	bApp.SetInterfaceRegistry(encodingConfig.InterfaceRegistry)
+
+	keys := sdk.NewKVStoreKeys(authtypes.StoreKey, banktypes.StoreKey, paramstypes.StoreKey, types.StoreKey)
+	tkeys := sdk.NewTransientStoreKeys(paramstypes.TStoreKey)
+
+	paramsKeeper := paramskeeper.NewKeeper(appCodec, encodingConfig.Amino, keys[paramstypes.StoreKey], tkeys[paramstypes.TStoreKey])
+
+	app := &App{
+		BaseApp:        bApp,
+		EncodingConfig: encodingConfig,
+	}
+	app.AccountKeeper = authkeeper.NewAccountKeeper(
+		appCodec, keys[authtypes.StoreKey], paramsKeeper.Subspace(authtypes.ModuleName), authtypes.ProtoBaseAccount,
+		map[string][]string{authtypes.FeeCollectorName: nil},
+	)
+	app.BankKeeper = bankkeeper.NewBaseKeeper(
+		appCodec, keys[banktypes.StoreKey], app.AccountKeeper, paramsKeeper.Subspace(banktypes.ModuleName), map[string]bool{},
+	)
+	app.FeeGrantKeeper = keeper.NewKeeper(appCodec, keys[types.StoreKey], paramsKeeper.Subspace(types.ModuleName), app.AccountKeeper)
+
+	app.mm = module.NewManager(
+		auth.NewAppModule(appCodec, app.AccountKeeper, nil),
+		bank.NewAppModule(appCodec, app.BankKeeper, app.AccountKeeper),
+		feegrant.NewAppModule(appCodec, app.AccountKeeper, app.BankKeeper, app.FeeGrantKeeper),
+		params.NewAppModule(paramsKeeper),
+	)
+	app.mm.SetOrderBeginBlockers(types.ModuleName)
+	app.mm.SetOrderInitGenesis(authtypes.ModuleName, banktypes.ModuleName, types.ModuleName)
+	app.mm.RegisterRoutes(app.Router(), app.QueryRouter(), encodingConfig.Amino)
+	app.mm.RegisterServices(module.NewConfigurator(app.MsgServiceRouter(), app.GRPCQueryRouter()))
+
+	app.MountKVStores(keys)
+	app.MountTransientStores(tkeys)
+
+	app.SetInitChainer(func(ctx sdk.Context, req abci.RequestInitChain) abci.ResponseInitChain {
+		var genesisState map[string]json.RawMessage
+		if err := json.Unmarshal(req.AppStateBytes, &genesisState); err != nil {
+			panic(err)
+		}
+		return app.mm.InitGenesis(ctx, appCodec, genesisState)
+	})
+	app.SetBeginBlocker(app.mm.BeginBlock)
+	app.SetEndBlocker(app.mm.EndBlock)
+	if deferred {
+		app.SetAnteHandler(
+			ante.NewDeferredAnteHandler(
+				app.AccountKeeper, app.BankKeeper, app.FeeGrantKeeper, authante.DefaultSigVerificationGasConsumer,
+				encodingConfig.TxConfig.SignModeHandler(),
+			),
+		)
+		app.SetPostHandler(ante.NewPostHandler(app.AccountKeeper, app.BankKeeper, app.FeeGrantKeeper))
+	} else {
+		app.SetAnteHandler(
+			ante.NewAnteHandler(
+				app.AccountKeeper, app.BankKeeper, app.FeeGrantKeeper, authante.DefaultSigVerificationGasConsumer,
+				encodingConfig.TxConfig.SignModeHandler(),
+			),
+		)
+	}
+
+	if err := app.LoadLatestVersion(); err != nil {
+		panic(err)
+	}
+
+	return app
+}
+
+// Setup returns an App whose chain was initialized with numAccounts accounts,
+// each holding coins, and the first block committed.
+func Setup(t *testing.T, numAccounts int, coins sdk.Coins) (*App, []Account) {
+	return setup(t, NewApp(), numAccounts, coins)
+}
+
+// SetupDeferred is Setup for an App returned by NewDeferredApp.
+func SetupDeferred(t *testing.T, numAccounts int, coins sdk.Coins) (*App, []Account) {
+	return setup(t, NewDeferredApp(), numAccounts, coins)
+}
+
+func setup(t *testing.T, app *App, numAccounts int, coins sdk.Coins) (*App, []Account) {
+	appCodec := app.EncodingConfig.Marshaler
+
+	accounts := make([]Account, numAccounts)
+	genAccs := make(authtypes.GenesisAccounts, numAccounts)
+	balances := make([]banktypes.Balance, numAccounts)
+	supply := sdk.NewCoins()
+	for i := range accounts {
+		privKey := secp256k1.GenPrivKey()
+		addr := sdk.AccAddress(privKey.PubKey().Address())
+
+		accounts[i] = Account{PrivKey: privKey, Address: addr}
+		genAccs[i] = authtypes.NewBaseAccount(addr, nil, uint64(i), 0)
+		balances[i] = banktypes.Balance{Address: addr.String(), Coins: coins}
+		supply = supply.Add(coins...)
+	}
+
+	genesisState := ModuleBasics.DefaultGenesis(appCodec)
+	genesisState[authtypes.ModuleName] = appCodec.MustMarshalJSON(authtypes.NewGenesisState(authtypes.DefaultParams(), genAccs))
+	genesisState[banktypes.ModuleName] = appCodec.MustMarshalJSON(
+		banktypes.NewGenesisState(banktypes.DefaultParams(), balances, supply, []banktypes.Metadata{}),
+	)
+
+	stateBytes, err := json.Marshal(genesisState)
+	require.NoError(t, err)
+
+	app.InitChain(abci.RequestInitChain{ChainId: chainID, AppStateBytes: stateBytes})
+	app.Commit()
+
+	return app, accounts
+}
+
+// Context returns a context reading the last committed state.
+func (app *App) Context() sdk.Context {
+	return app.NewContext(true, tmproto.Header{Height: app.LastBlockHeight()})
+}
+
+// SignAndDeliver signs a transaction with msgs by signer, paying fee optionally
+// out of an allowance from feeGranter, and delivers it in a new block, which is
+// committed whatever the outcome.
+func (app *App) SignAndDeliver(t *testing.T, signer Account, feeGranter sdk.AccAddress, fee sdk.Coins, msgs ...sdk.Msg) (*sdk.Result, error) {
+	txConfig := app.EncodingConfig.TxConfig
+	acc := app.AccountKeeper.GetAccount(app.Context(), signer.Address)
+	require.NotNil(t, acc, "account %s does not exist", signer.Address)
+
+	txBuilder := txConfig.NewTxBuilder()
+	require.NoError(t, txBuilder.SetMsgs(msgs...))
+	txBuilder.SetFeeAmount(fee)
+	txBuilder.SetGasLimit(DefaultGas)
+	txBuilder.(interface{ SetFeeGranter(sdk.AccAddress) }).SetFeeGranter(feeGranter)
+
+	signMode := txConfig.SignModeHandler().DefaultMode()
+	sig := signing.SignatureV2{
+		PubKey:   signer.PrivKey.PubKey(),
+		Data:     &signing.SingleSignatureData{SignMode: signMode},
+		Sequence: acc.GetSequence(),
+	}
+	require.NoError(t, txBuilder.SetSignatures(sig))
+
+	signerData := authsigning.SignerData{ChainID: chainID, AccountNumber: acc.GetAccountNumber(), Sequence: acc.GetSequence()}
+	signBytes, err := txConfig.SignModeHandler().GetSignBytes(signMode, signerData, txBuilder.GetTx())
+	require.NoError(t, err)
+	sig.Data.(*signing.SingleSignatureData).Signature, err = signer.PrivKey.Sign(signBytes)
+	require.NoError(t, err)
+	require.NoError(t, txBuilder.SetSignatures(sig))
+
+	header := tmproto.Header{ChainID: chainID, Height: app.LastBlockHeight() + 1}
+	app.BeginBlock(abci.RequestBeginBlock{Header: header})
+	_, res, err := app.Deliver(txConfig.TxEncoder(), txBuilder.GetTx())
+	app.EndBlock(abci.RequestEndBlock{Height: header.Height})
+	app.Commit()
+
+	return res, err
+}
+
+// GrantFeeAllowance delivers a MsgGrantFeeAllowance from granter to grantee,
+// with the granter paying fee.
+func (app *App) GrantFeeAllowance(t *testing.T, granter Account, grantee sdk.AccAddress, allowance types.FeeAllowanceI, fee sdk.Coins) (*sdk.Result, error) {
+	msg, err := types.NewMsgGrantFeeAllowance(allowance, granter.Address, grantee)
+	require.NoError(t, err)
+
+	return app.SignAndDeliver(t, granter, nil, fee, msg)
+}
+
+// DeliverGrantedFeeTx delivers a transaction with msgs signed by grantee whose
+// fee is paid out of the allowance granted by granter.
+func (app *App) DeliverGrantedFeeTx(t *testing.T, granter sdk.AccAddress, grantee Account, fee sdk.Coins, msgs ...sdk.Msg) (*sdk.Result, error) {
+	return app.SignAndDeliver(t, grantee, granter, fee, msgs...)
+}
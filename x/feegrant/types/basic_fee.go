@@ -0,0 +1,140 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+var _ FeeAllowanceI = (*BasicAllowance)(nil)
+
+// MinExpiration is the earliest Expiration accepted by ValidateBasic. Anything
+// before it is almost certainly an unset or mistyped time.
+var MinExpiration = time.Unix(0, 0).UTC()
+
+// Accept can use fee payment requested as well as timestamp of the current block
+// to determine whether or not to process this.
+//
+// If it returns an error, the fee payment is rejected, otherwise it is accepted.
+// The FeeAllowance implementation is expected to update it's internal state
+// and will be saved again after an acceptance.
+//
+// If remove is true (regardless of the error), the FeeAllowance will be deleted from storage
+// (eg. when it is used up).
+func (a *BasicAllowance) Accept(ctx sdk.Context, fee sdk.Coins, _ []sdk.Msg) (bool, error) {
+	if a.isExpired(ctx.BlockHeight(), ctx.BlockTime()) {
+		return true, sdkerrors.Wrap(ErrFeeLimitExpired, "basic allowance")
+	}
+
+	// an empty spend limit means the allowance is unlimited, and an empty fee
+	// leaves it as is
+	if a.SpendLimit.Empty() || fee.IsZero() {
+		return false, nil
+	}
+
+	left, invalid := a.SpendLimit.SafeSub(fee)
+	if invalid {
+		return false, sdkerrors.Wrap(ErrFeeLimitExceeded, "basic allowance")
+	}
+
+	a.SpendLimit = left
+	return left.IsZero(), nil
+}
+
+// isExpired returns whether the allowance has expired by the block at height
+// and blockTime.
+func (a BasicAllowance) isExpired(height int64, blockTime time.Time) bool {
+	if a.ExpirationHeight > 0 && height > a.ExpirationHeight {
+		return true
+	}
+
+	return a.Expiration != nil && blockTime.After(*a.Expiration)
+}
+
+// CanAccept checks whether Accept would accept fee, see FeeAllowanceI.
+func (a *BasicAllowance) CanAccept(ctx sdk.Context, fee sdk.Coins, msgs []sdk.Msg) error {
+	// accept on a copy, Accept only replaces the fields it updates
+	basic := *a
+	_, err := basic.Accept(ctx, fee, msgs)
+	return err
+}
+
+// ExpiresAt returns the expiry time of the BasicAllowance. It is nil for an
+// allowance expiring at a block height.
+func (a *BasicAllowance) ExpiresAt() (*time.Time, error) {
+	return a.Expiration, nil
+}
+
+// ExpiresAtHeight returns the expiration height of the BasicAllowance, which
+// is 0 for an allowance expiring at a time.
+func (a *BasicAllowance) ExpiresAtHeight() (int64, error) {
+	return a.ExpirationHeight, nil
+}
+
+// Remaining returns the SpendLimit left, or empty coins once expired.
+func (a *BasicAllowance) Remaining(ctx sdk.Context) (sdk.Coins, error) {
+	if a.isExpired(ctx.BlockHeight(), ctx.BlockTime()) {
+		return sdk.Coins{}, nil
+	}
+
+	return a.SpendLimit, nil
+}
+
+// RemainingSpendLimit returns the SpendLimit, see FeeAllowanceI.
+func (a *BasicAllowance) RemainingSpendLimit() (sdk.Coins, error) {
+	return a.SpendLimit, nil
+}
+
+// ValidateBasic implements FeeAllowance and enforces basic sanity checks. An
+// empty SpendLimit is valid and means the allowance is unlimited.
+func (a BasicAllowance) ValidateBasic() error {
+	if err := a.SpendLimit.Validate(); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidCoins, "spend limit is invalid: %s", err)
+	}
+
+	if a.Expiration != nil && a.Expiration.Before(MinExpiration) {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "expiration %s is before %s", a.Expiration, MinExpiration)
+	}
+
+	if a.ExpirationHeight < 0 {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "expiration height %d is negative", a.ExpirationHeight)
+	}
+
+	if a.Expiration != nil && a.ExpirationHeight > 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "only one of expiration and expiration height can be set")
+	}
+
+	return nil
+}
+
+// String implements the Stringer interface, e.g.
+// BasicAllowance{spend_limit: 100uatom, expiration: 2024-01-01T00:00:00Z} or
+// BasicAllowance{spend_limit: 100uatom, expiration: height 1000}.
+func (a BasicAllowance) String() string {
+	expiration := formatExpiration(a.Expiration)
+	if a.ExpirationHeight > 0 {
+		expiration = fmt.Sprintf("height %d", a.ExpirationHeight)
+	}
+
+	return fmt.Sprintf("BasicAllowance{spend_limit: %s, expiration: %s}", formatSpendLimit(a.SpendLimit), expiration)
+}
+
+// formatSpendLimit formats a spend limit, an empty one meaning no limit.
+func formatSpendLimit(limit sdk.Coins) string {
+	if limit.Empty() {
+		return "unlimited"
+	}
+
+	return limit.String()
+}
+
+// formatExpiration formats an optional expiration in UTC.
+func formatExpiration(exp *time.Time) string {
+	if exp == nil {
+		return "never"
+	}
+
+	return exp.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,363 @@
+package types_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+func TestBasicFeeValidAllow(t *testing.T) {
+	app := simapp.Setup(false)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{})
+
+	eth := sdk.NewCoins(sdk.NewInt64Coin("eth", 10))
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	smallAtom := sdk.NewCoins(sdk.NewInt64Coin("atom", 43))
+	bigAtom := sdk.NewCoins(sdk.NewInt64Coin("atom", 1000))
+	leftAtom := sdk.NewCoins(sdk.NewInt64Coin("atom", 512))
+
+	now := time.Now().UTC()
+	oneHour := now.Add(1 * time.Hour)
+
+	cases := map[string]struct {
+		allowance *types.BasicAllowance
+		// all other checks are ignored if valid=false
+		fee         sdk.Coins
+		blockTime   time.Time
+		blockHeight int64
+		valid       bool
+		accept      bool
+		remove      bool
+		remains     sdk.Coins
+	}{
+		"empty": {
+			allowance: &types.BasicAllowance{},
+			valid:     true,
+			accept:    true,
+		},
+		"small fee without expire": {
+			allowance: &types.BasicAllowance{
+				SpendLimit: atom,
+			},
+			valid:   true,
+			fee:     smallAtom,
+			accept:  true,
+			remove:  false,
+			remains: leftAtom,
+		},
+		"all fee without expire": {
+			allowance: &types.BasicAllowance{
+				SpendLimit: smallAtom,
+			},
+			valid:  true,
+			fee:    smallAtom,
+			accept: true,
+			remove: true,
+		},
+		"wrong fee": {
+			allowance: &types.BasicAllowance{
+				SpendLimit: smallAtom,
+			},
+			valid:  true,
+			fee:    eth,
+			accept: false,
+		},
+		"non-expired": {
+			allowance: &types.BasicAllowance{
+				SpendLimit: atom,
+				Expiration: &oneHour,
+			},
+			valid:     true,
+			fee:       smallAtom,
+			blockTime: now,
+			accept:    true,
+			remove:    false,
+			remains:   leftAtom,
+		},
+		"expired": {
+			allowance: &types.BasicAllowance{
+				SpendLimit: atom,
+				Expiration: &now,
+			},
+			valid:     true,
+			fee:       smallAtom,
+			blockTime: oneHour,
+			accept:    false,
+			remove:    true,
+		},
+		"non-expired height": {
+			allowance: &types.BasicAllowance{
+				SpendLimit:       atom,
+				ExpirationHeight: 100,
+			},
+			valid:       true,
+			fee:         smallAtom,
+			blockTime:   oneHour,
+			blockHeight: 100,
+			accept:      true,
+			remove:      false,
+			remains:     leftAtom,
+		},
+		"expired height": {
+			allowance: &types.BasicAllowance{
+				SpendLimit:       atom,
+				ExpirationHeight: 100,
+			},
+			valid:       true,
+			fee:         smallAtom,
+			blockTime:   now,
+			blockHeight: 101,
+			accept:      false,
+			remove:      true,
+		},
+		"both time and height expiration": {
+			allowance: &types.BasicAllowance{
+				SpendLimit:       atom,
+				Expiration:       &oneHour,
+				ExpirationHeight: 100,
+			},
+			valid: false,
+		},
+		"fee more than allowed": {
+			allowance: &types.BasicAllowance{
+				SpendLimit: atom,
+				Expiration: &oneHour,
+			},
+			valid:     true,
+			fee:       bigAtom,
+			blockTime: now,
+			accept:    false,
+		},
+		"with out spend limit": {
+			allowance: &types.BasicAllowance{
+				Expiration: &oneHour,
+			},
+			valid:     true,
+			fee:       bigAtom,
+			blockTime: now,
+			accept:    true,
+		},
+		"expired no spend limit": {
+			allowance: &types.BasicAllowance{
+				Expiration: &now,
+			},
+			valid:     true,
+			fee:       bigAtom,
+			blockTime: oneHour,
+			accept:    false,
+			remove:    true,
+		},
+	}
+
+	for name, stc := range cases {
+		tc := stc // to make scopelint happy
+		t.Run(name, func(t *testing.T) {
+			err := tc.allowance.ValidateBasic()
+			if !tc.valid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			ctx := ctx.WithBlockTime(tc.blockTime).WithBlockHeight(tc.blockHeight)
+
+			// now try to deduct
+			removed, err := tc.allowance.Accept(ctx, tc.fee, []sdk.Msg{})
+			if !tc.accept {
+				require.Error(t, err)
+				require.Equal(t, tc.remove, removed)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Equal(t, tc.remove, removed)
+			if !removed {
+				require.Equal(t, tc.allowance.SpendLimit, tc.remains)
+			}
+		})
+	}
+}
+
+func TestBasicFeeUnlimitedRoundTrip(t *testing.T) {
+	app := simapp.Setup(false)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{})
+
+	// an empty spend limit survives marshaling and still means unlimited
+	bz, err := (&types.BasicAllowance{}).Marshal()
+	require.NoError(t, err)
+
+	var allowance types.BasicAllowance
+	require.NoError(t, allowance.Unmarshal(bz))
+	require.True(t, allowance.SpendLimit.Empty())
+
+	removed, err := allowance.Accept(ctx, sdk.NewCoins(sdk.NewInt64Coin("atom", 1000000)), nil)
+	require.NoError(t, err)
+	require.False(t, removed)
+}
+
+func TestBasicFeeValidateBasic(t *testing.T) {
+	atom := sdk.NewInt64Coin("atom", 555)
+	eth := sdk.NewInt64Coin("eth", 10)
+	farPast := types.MinExpiration.Add(-time.Second)
+	oneHour := time.Now().Add(time.Hour)
+
+	cases := map[string]struct {
+		allowance types.BasicAllowance
+		valid     bool
+	}{
+		"nil spend limit": {
+			allowance: types.BasicAllowance{},
+			valid:     true,
+		},
+		"valid spend limit": {
+			allowance: types.BasicAllowance{SpendLimit: sdk.NewCoins(atom, eth), Expiration: &oneHour},
+			valid:     true,
+		},
+		"unsorted spend limit": {
+			allowance: types.BasicAllowance{SpendLimit: sdk.Coins{eth, atom}},
+		},
+		"negative spend limit": {
+			allowance: types.BasicAllowance{SpendLimit: sdk.Coins{sdk.Coin{Denom: "atom", Amount: sdk.NewInt(-1)}}},
+		},
+		"zero spend limit": {
+			allowance: types.BasicAllowance{SpendLimit: sdk.Coins{sdk.NewInt64Coin("atom", 0)}},
+		},
+		"expiration in the far past": {
+			allowance: types.BasicAllowance{SpendLimit: sdk.NewCoins(atom), Expiration: &farPast},
+		},
+		"expiration height": {
+			allowance: types.BasicAllowance{SpendLimit: sdk.NewCoins(atom), ExpirationHeight: 100},
+			valid:     true,
+		},
+		"negative expiration height": {
+			allowance: types.BasicAllowance{SpendLimit: sdk.NewCoins(atom), ExpirationHeight: -1},
+		},
+		"both expiration and expiration height": {
+			allowance: types.BasicAllowance{SpendLimit: sdk.NewCoins(atom), Expiration: &oneHour, ExpirationHeight: 100},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			err := tc.allowance.ValidateBasic()
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestBasicFeeEmptyFee(t *testing.T) {
+	app := simapp.Setup(false)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: time.Now()})
+
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+
+	for _, fee := range []sdk.Coins{nil, {}, {sdk.NewInt64Coin("atom", 0)}} {
+		allowance := &types.BasicAllowance{SpendLimit: atom}
+
+		remove, err := allowance.Accept(ctx, fee, nil)
+		require.NoError(t, err)
+		require.False(t, remove)
+		require.Equal(t, atom, allowance.SpendLimit)
+	}
+}
+
+func TestBasicFeeRemainingExpirationHeight(t *testing.T) {
+	app := simapp.Setup(false)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: time.Now(), Height: 100})
+
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	allowance := &types.BasicAllowance{SpendLimit: atom, ExpirationHeight: 100}
+
+	height, err := allowance.ExpiresAtHeight()
+	require.NoError(t, err)
+	require.Equal(t, int64(100), height)
+
+	// the allowance can still be used at its expiration height
+	remaining, err := allowance.Remaining(ctx)
+	require.NoError(t, err)
+	require.Equal(t, atom, remaining)
+
+	remaining, err = allowance.Remaining(ctx.WithBlockHeight(101))
+	require.NoError(t, err)
+	require.Empty(t, remaining)
+}
+
+func TestBasicFeeIBCDenom(t *testing.T) {
+	app := simapp.Setup(false)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: time.Now()})
+
+	ibcDenom := "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2"
+	limit := sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 100), sdk.NewInt64Coin("atom", 10))
+
+	allowance := &types.BasicAllowance{SpendLimit: limit}
+	require.NoError(t, allowance.ValidateBasic())
+
+	remove, err := allowance.Accept(ctx, sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 60)), nil)
+	require.NoError(t, err)
+	require.False(t, remove)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 40), sdk.NewInt64Coin("atom", 10)), allowance.SpendLimit)
+
+	// the voucher of another channel is a different denom
+	otherDenom := "ibc/46B44899322F3CD854D2D46DEEF881958467CDD4B3B10086DA49296BBED94BED"
+	_, err = allowance.Accept(ctx, sdk.NewCoins(sdk.NewInt64Coin(otherDenom, 1)), nil)
+	require.True(t, errors.Is(err, types.ErrFeeLimitExceeded), err)
+
+	_, err = allowance.Accept(ctx, sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 41)), nil)
+	require.True(t, errors.Is(err, types.ErrFeeLimitExceeded), err)
+
+	remove, err = allowance.Accept(ctx, sdk.NewCoins(sdk.NewInt64Coin(ibcDenom, 40), sdk.NewInt64Coin("atom", 10)), nil)
+	require.NoError(t, err)
+	require.True(t, remove)
+}
+
+func TestBasicFeeString(t *testing.T) {
+	exp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	local := time.Date(2024, 1, 1, 0, 0, 0, 5e8, time.FixedZone("CET", 3600))
+
+	cases := map[string]struct {
+		allowance types.BasicAllowance
+		expected  string
+	}{
+		"limited": {
+			types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("uatom", 100)), Expiration: &exp},
+			"BasicAllowance{spend_limit: 100uatom, expiration: 2024-01-01T00:00:00Z}",
+		},
+		"unlimited": {
+			types.BasicAllowance{},
+			"BasicAllowance{spend_limit: unlimited, expiration: never}",
+		},
+		"several denoms": {
+			types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("eth", 2), sdk.NewInt64Coin("uatom", 1))},
+			"BasicAllowance{spend_limit: 2eth,1uatom, expiration: never}",
+		},
+		"expiration height": {
+			types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("uatom", 100)), ExpirationHeight: 1000},
+			"BasicAllowance{spend_limit: 100uatom, expiration: height 1000}",
+		},
+		"non UTC expiration": {
+			types.BasicAllowance{Expiration: &local},
+			"BasicAllowance{spend_limit: unlimited, expiration: 2023-12-31T23:00:00.5Z}",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.allowance.String())
+			require.Equal(t, tc.expected, fmt.Sprint(&tc.allowance))
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package types
+
+import (
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/codec/types"
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/msgservice"
+)
+
+// RegisterLegacyAminoCodec registers the necessary x/feegrant interfaces and concrete types
+// on the provided LegacyAmino codec. These types are used for Amino JSON serialization.
+func RegisterLegacyAminoCodec(cdc *codec.LegacyAmino) {
+	cdc.RegisterInterface((*FeeAllowanceI)(nil), nil)
+	cdc.RegisterConcrete(&BasicAllowance{}, "cosmos-sdk/BasicAllowance", nil)
+	cdc.RegisterConcrete(&PeriodicFeeAllowance{}, "cosmos-sdk/PeriodicFeeAllowance", nil)
+	cdc.RegisterConcrete(&AllowedMsgAllowance{}, "cosmos-sdk/AllowedMsgAllowance", nil)
+	cdc.RegisterConcrete(&AllowedDenomAllowance{}, "cosmos-sdk/AllowedDenomAllowance", nil)
+	cdc.RegisterConcrete(&CappedFractionAllowance{}, "cosmos-sdk/CappedFractionAllowance", nil)
+	cdc.RegisterConcrete(&MsgGrantFeeAllowance{}, "cosmos-sdk/MsgGrantFeeAllowance", nil)
+	cdc.RegisterConcrete(&MsgRevokeFeeAllowance{}, "cosmos-sdk/MsgRevokeFeeAllowance", nil)
+	cdc.RegisterConcrete(&MsgUpdateAllowance{}, "cosmos-sdk/MsgUpdateAllowance", nil)
+	cdc.RegisterConcrete(&MsgGrantAllowances{}, "cosmos-sdk/MsgGrantAllowances", nil)
+	cdc.RegisterConcrete(&MsgRevokeAllowancesByGranter{}, "cosmos-sdk/MsgRevokeAllowancesByGranter", nil)
+}
+
+// RegisterInterfaces registers the interfaces types with the interface registry
+func RegisterInterfaces(registry types.InterfaceRegistry) {
+	registry.RegisterImplementations((*sdk.Msg)(nil),
+		&MsgGrantFeeAllowance{},
+		&MsgRevokeFeeAllowance{},
+		&MsgUpdateAllowance{},
+		&MsgGrantAllowances{},
+		&MsgRevokeAllowancesByGranter{},
+	)
+
+	registry.RegisterInterface(
+		"cosmos.feegrant.v1beta1.FeeAllowanceI",
+		(*FeeAllowanceI)(nil),
+		&BasicAllowance{},
+		&PeriodicFeeAllowance{},
+		&AllowedMsgAllowance{},
+		&AllowedDenomAllowance{},
+		&CappedFractionAllowance{},
+	)
+
+	msgservice.RegisterMsgServiceDesc(registry, &_Msg_serviceDesc)
+}
+
+// UnpackAllowance unpacks an allowance from an Any against the interface
+// registry behind unpacker. It fails with ErrInvalidAllowanceType if the Any is
+// empty or its type URL is not registered as a FeeAllowanceI.
+func UnpackAllowance(unpacker types.AnyUnpacker, any *types.Any) (FeeAllowanceI, error) {
+	if any == nil {
+		return nil, sdkerrors.Wrap(ErrInvalidAllowanceType, "missing allowance")
+	}
+
+	var allowance FeeAllowanceI
+	if err := unpacker.UnpackAny(any, &allowance); err != nil {
+		return nil, sdkerrors.Wrapf(ErrInvalidAllowanceType, "%s: %s", any.TypeUrl, err)
+	}
+
+	if allowance == nil {
+		return nil, sdkerrors.Wrap(ErrInvalidAllowanceType, "missing allowance type URL")
+	}
+
+	return allowance, nil
+}
+
+var (
+	amino = codec.NewLegacyAmino()
+
+	// ModuleCdc references the global x/feegrant module codec. Note, the codec
+	// should ONLY be used in certain instances of tests and for JSON encoding as Amino
+	// is still used for that purpose.
+	//
+	// The actual codec used for serialization should be provided to x/feegrant and
+	// defined at the application level.
+	ModuleCdc = codec.NewAminoCodec(amino)
+)
+
+func init() {
+	RegisterLegacyAminoCodec(amino)
+	cryptocodec.RegisterCrypto(amino)
+	amino.Seal()
+}
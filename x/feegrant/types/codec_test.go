@@ -0,0 +1,92 @@
+package types_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/require"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/testutil/testdata"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+// packUncached packs msg into an Any without a cached value, as it would be
+// after decoding.
+func packUncached(t *testing.T, msg proto.Message) *codectypes.Any {
+	any, err := codectypes.NewAnyWithValue(msg)
+	require.NoError(t, err)
+
+	return &codectypes.Any{TypeUrl: any.TypeUrl, Value: any.Value}
+}
+
+func TestUnpackAllowance(t *testing.T) {
+	registry := codectypes.NewInterfaceRegistry()
+	types.RegisterInterfaces(registry)
+
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	basic := &types.BasicAllowance{SpendLimit: atom}
+	allowedMsg, err := types.NewAllowedMsgAllowance(basic, []string{"/cosmos.bank.v1beta1.MsgSend"})
+	require.NoError(t, err)
+	allowedDenom, err := types.NewAllowedDenomAllowance(basic, []string{"atom"})
+	require.NoError(t, err)
+	fraction, err := types.NewCappedFractionAllowance(basic, sdk.NewDecWithPrec(5, 1))
+	require.NoError(t, err)
+
+	allowances := []types.FeeAllowanceI{
+		basic,
+		&types.PeriodicFeeAllowance{
+			Basic:            *basic,
+			Period:           time.Hour,
+			PeriodSpendLimit: atom,
+			PeriodCanSpend:   atom,
+			PeriodReset:      time.Unix(1000, 0).UTC(),
+		},
+		allowedMsg,
+		allowedDenom,
+		fraction,
+	}
+
+	for _, allowance := range allowances {
+		allowance := allowance
+		t.Run(proto.MessageName(allowance.(proto.Message)), func(t *testing.T) {
+			unpacked, err := types.UnpackAllowance(registry, packUncached(t, allowance.(proto.Message)))
+			require.NoError(t, err)
+			require.IsType(t, allowance, unpacked)
+			require.NoError(t, unpacked.ValidateBasic())
+
+			ctx := sdk.Context{}.WithBlockTime(time.Unix(0, 0))
+			expected, err := allowance.Remaining(ctx)
+			require.NoError(t, err)
+			remaining, err := unpacked.Remaining(ctx)
+			require.NoError(t, err)
+			require.Equal(t, expected, remaining)
+		})
+	}
+}
+
+func TestUnpackAllowanceInvalid(t *testing.T) {
+	registry := codectypes.NewInterfaceRegistry()
+	types.RegisterInterfaces(registry)
+
+	revoke := types.NewMsgRevokeFeeAllowance(sdk.AccAddress("granter"), sdk.AccAddress("grantee"))
+
+	cases := map[string]*codectypes.Any{
+		"nil":                      nil,
+		"empty":                    {},
+		"registered non-allowance": packUncached(t, &revoke),
+		"unregistered type":        packUncached(t, &testdata.Dog{Name: "spot"}),
+	}
+
+	for name, any := range cases {
+		any := any
+		t.Run(name, func(t *testing.T) {
+			allowance, err := types.UnpackAllowance(registry, any)
+			require.True(t, errors.Is(err, types.ErrInvalidAllowanceType), err)
+			require.Nil(t, allowance)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package types
+
+import (
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+var (
+	_ FeeAllowanceI                 = (*AllowedDenomAllowance)(nil)
+	_ types.UnpackInterfacesMessage = (*AllowedDenomAllowance)(nil)
+)
+
+// NewAllowedDenomAllowance creates new denom filtered fee allowance.
+func NewAllowedDenomAllowance(allowance FeeAllowanceI, allowedDenoms []string) (*AllowedDenomAllowance, error) {
+	msg, ok := allowance.(proto.Message)
+	if !ok {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrPackAny, "cannot proto marshal %T", allowance)
+	}
+	any, err := types.NewAnyWithValue(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AllowedDenomAllowance{
+		Allowance:     any,
+		AllowedDenoms: allowedDenoms,
+	}, nil
+}
+
+// UnpackInterfaces implements UnpackInterfacesMessage.UnpackInterfaces
+func (a *AllowedDenomAllowance) UnpackInterfaces(unpacker types.AnyUnpacker) error {
+	var allowance FeeAllowanceI
+	return unpacker.UnpackAny(a.Allowance, &allowance)
+}
+
+// GetAllowance returns allowed fee allowance.
+func (a *AllowedDenomAllowance) GetAllowance() (FeeAllowanceI, error) {
+	if a.Allowance == nil {
+		return nil, sdkerrors.Wrap(ErrNoAllowance, "allowance should not be empty")
+	}
+
+	allowance, ok := a.Allowance.GetCachedValue().(FeeAllowanceI)
+	if !ok {
+		return nil, sdkerrors.Wrap(ErrNoAllowance, "failed to get allowance")
+	}
+
+	return allowance, nil
+}
+
+// SetAllowance sets allowed fee allowance.
+func (a *AllowedDenomAllowance) SetAllowance(allowance FeeAllowanceI) error {
+	var err error
+	a.Allowance, err = types.NewAnyWithValue(allowance.(proto.Message))
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrPackAny, "cannot proto marshal %T", allowance)
+	}
+
+	return nil
+}
+
+// Accept checks that every coin of the fee is in an allowed denom before
+// delegating to the wrapped allowance. A fee in a disallowed denom returns an
+// error and leaves the wrapped allowance untouched.
+func (a *AllowedDenomAllowance) Accept(ctx sdk.Context, fee sdk.Coins, msgs []sdk.Msg) (bool, error) {
+	if err := a.checkDenoms(fee); err != nil {
+		return false, err
+	}
+
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return false, err
+	}
+
+	remove, err := allowance.Accept(ctx, fee, msgs)
+	if err == nil && !remove {
+		if err = a.SetAllowance(allowance); err != nil {
+			return false, err
+		}
+	}
+
+	return remove, err
+}
+
+// CanAccept checks whether Accept would accept fee, see FeeAllowanceI.
+func (a *AllowedDenomAllowance) CanAccept(ctx sdk.Context, fee sdk.Coins, msgs []sdk.Msg) error {
+	if err := a.checkDenoms(fee); err != nil {
+		return err
+	}
+
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return err
+	}
+
+	return allowance.CanAccept(ctx, fee, msgs)
+}
+
+// checkDenoms returns an error unless every coin of fee is in an allowed denom.
+func (a *AllowedDenomAllowance) checkDenoms(fee sdk.Coins) error {
+	denoms := make(map[string]bool, len(a.AllowedDenoms))
+	for _, denom := range a.AllowedDenoms {
+		denoms[denom] = true
+	}
+
+	for _, coin := range fee {
+		if !denoms[coin.Denom] {
+			return sdkerrors.Wrapf(ErrDenomNotAllowed, "fee denom %s", coin.Denom)
+		}
+	}
+
+	return nil
+}
+
+// ValidateBasic implements FeeAllowance and enforces basic sanity checks
+func (a *AllowedDenomAllowance) ValidateBasic() error {
+	if a.Allowance == nil {
+		return sdkerrors.Wrap(ErrNoAllowance, "allowance should not be empty")
+	}
+
+	if len(a.AllowedDenoms) == 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "allowed denoms should not be empty")
+	}
+
+	for _, denom := range a.AllowedDenoms {
+		if err := sdk.ValidateDenom(denom); err != nil {
+			return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, err.Error())
+		}
+	}
+
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return err
+	}
+
+	return allowance.ValidateBasic()
+}
+
+// ExpiresAt returns the expiry time of the wrapped allowance.
+func (a *AllowedDenomAllowance) ExpiresAt() (*time.Time, error) {
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return nil, err
+	}
+
+	return allowance.ExpiresAt()
+}
+
+// ExpiresAtHeight returns the expiration height of the wrapped allowance.
+func (a *AllowedDenomAllowance) ExpiresAtHeight() (int64, error) {
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return 0, err
+	}
+
+	return allowance.ExpiresAtHeight()
+}
+
+// Remaining returns what remains on the wrapped allowance in the allowed
+// denoms.
+func (a *AllowedDenomAllowance) Remaining(ctx sdk.Context) (sdk.Coins, error) {
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return nil, err
+	}
+
+	remaining, err := allowance.Remaining(ctx)
+	if err != nil || remaining.Empty() {
+		return remaining, err
+	}
+
+	allowed := sdk.Coins{}
+	for _, denom := range a.AllowedDenoms {
+		if amount := remaining.AmountOf(denom); amount.IsPositive() {
+			allowed = allowed.Add(sdk.NewCoin(denom, amount))
+		}
+	}
+
+	return allowed, nil
+}
+
+// RemainingSpendLimit returns the spend limit left on the wrapped allowance.
+func (a *AllowedDenomAllowance) RemainingSpendLimit() (sdk.Coins, error) {
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return nil, err
+	}
+
+	return allowance.RemainingSpendLimit()
+}
@@ -0,0 +1,110 @@
+package types_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+func TestAllowedDenomAllowance(t *testing.T) {
+	app := simapp.Setup(false)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: time.Now()})
+
+	limit := sdk.NewCoins(sdk.NewInt64Coin("atom", 100), sdk.NewInt64Coin("eth", 100), sdk.NewInt64Coin("gov", 100))
+
+	cases := map[string]struct {
+		allowed  []string
+		fee      sdk.Coins
+		accept   bool
+		expLimit sdk.Coins
+	}{
+		"single denom": {
+			allowed:  []string{"atom"},
+			fee:      sdk.NewCoins(sdk.NewInt64Coin("atom", 10)),
+			accept:   true,
+			expLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 90), sdk.NewInt64Coin("eth", 100), sdk.NewInt64Coin("gov", 100)),
+		},
+		"multiple denoms": {
+			allowed:  []string{"atom", "eth"},
+			fee:      sdk.NewCoins(sdk.NewInt64Coin("atom", 10), sdk.NewInt64Coin("eth", 20)),
+			accept:   true,
+			expLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 90), sdk.NewInt64Coin("eth", 80), sdk.NewInt64Coin("gov", 100)),
+		},
+		"disallowed denom": {
+			allowed:  []string{"atom"},
+			fee:      sdk.NewCoins(sdk.NewInt64Coin("gov", 10)),
+			accept:   false,
+			expLimit: limit,
+		},
+		"allowed and disallowed denoms": {
+			allowed:  []string{"atom", "eth"},
+			fee:      sdk.NewCoins(sdk.NewInt64Coin("atom", 10), sdk.NewInt64Coin("gov", 10)),
+			accept:   false,
+			expLimit: limit,
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			allowance, err := types.NewAllowedDenomAllowance(&types.BasicAllowance{SpendLimit: limit}, tc.allowed)
+			require.NoError(t, err)
+			require.NoError(t, allowance.ValidateBasic())
+
+			remove, err := allowance.Accept(ctx, tc.fee, nil)
+			require.False(t, remove)
+			if tc.accept {
+				require.NoError(t, err)
+			} else {
+				require.True(t, errors.Is(err, types.ErrDenomNotAllowed))
+			}
+
+			inner, err := allowance.GetAllowance()
+			require.NoError(t, err)
+			require.Equal(t, tc.expLimit, inner.(*types.BasicAllowance).SpendLimit)
+		})
+	}
+}
+
+func TestAllowedDenomAllowanceNilInner(t *testing.T) {
+	app := simapp.Setup(false)
+	now := time.Now()
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: now})
+
+	allowance := &types.AllowedDenomAllowance{AllowedDenoms: []string{"atom"}}
+	require.True(t, errors.Is(allowance.ValidateBasic(), types.ErrNoAllowance))
+
+	fee := sdk.NewCoins(sdk.NewInt64Coin("atom", 10))
+	remove, err := allowance.Accept(ctx, fee, nil)
+	require.False(t, remove)
+	require.True(t, errors.Is(err, types.ErrNoAllowance))
+
+	require.True(t, errors.Is(allowance.CanAccept(ctx, fee, nil), types.ErrNoAllowance))
+
+	_, err = allowance.ExpiresAt()
+	require.True(t, errors.Is(err, types.ErrNoAllowance))
+
+	_, err = allowance.Remaining(ctx)
+	require.True(t, errors.Is(err, types.ErrNoAllowance))
+}
+
+func TestAllowedDenomAllowanceValidateBasic(t *testing.T) {
+	basic := &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 100))}
+
+	allowance, err := types.NewAllowedDenomAllowance(basic, nil)
+	require.NoError(t, err)
+	require.Error(t, allowance.ValidateBasic())
+
+	allowance, err = types.NewAllowedDenomAllowance(basic, []string{"!nvalid"})
+	require.NoError(t, err)
+	require.Error(t, allowance.ValidateBasic())
+
+	require.Error(t, (&types.AllowedDenomAllowance{AllowedDenoms: []string{"atom"}}).ValidateBasic())
+}
@@ -0,0 +1,33 @@
+package types
+
+import (
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+// Codes for feegrant errors
+const (
+	DefaultCodespace = ModuleName
+)
+
+var (
+	// ErrFeeLimitExceeded error if there are not enough allowance to cover the fees
+	ErrFeeLimitExceeded = sdkerrors.Register(DefaultCodespace, 2, "fee limit exceeded")
+	// ErrFeeLimitExpired error if the allowance has expired
+	ErrFeeLimitExpired = sdkerrors.Register(DefaultCodespace, 3, "fee allowance expired")
+	// ErrInvalidDuration error if the Duration is invalid or doesn't match the expiration
+	ErrInvalidDuration = sdkerrors.Register(DefaultCodespace, 4, "invalid duration")
+	// ErrNoAllowance error if there is no allowance for that pair
+	ErrNoAllowance = sdkerrors.Register(DefaultCodespace, 5, "no allowance")
+	// ErrMessageNotAllowed error if the message is not in the allowed messages list
+	ErrMessageNotAllowed = sdkerrors.Register(DefaultCodespace, 6, "message not allowed")
+	// ErrDenomNotAllowed error if a fee is paid in a denom which is not in the allowed denoms list
+	ErrDenomNotAllowed = sdkerrors.Register(DefaultCodespace, 7, "fee denom not allowed")
+	// ErrTooManyGrants error if a granter already has the maximum number of active grants
+	ErrTooManyGrants = sdkerrors.Register(DefaultCodespace, 8, "too many grants")
+	// ErrAllowanceTooLarge error if an encoded allowance exceeds MaxAllowanceBytes
+	ErrAllowanceTooLarge = sdkerrors.Register(DefaultCodespace, 9, "allowance too large")
+	// ErrFeeAllowanceExists error if a grant already exists for that pair
+	ErrFeeAllowanceExists = sdkerrors.Register(DefaultCodespace, 10, "fee allowance already exists")
+	// ErrInvalidAllowanceType error if an Any does not hold a registered fee allowance
+	ErrInvalidAllowanceType = sdkerrors.Register(DefaultCodespace, 11, "invalid fee allowance type")
+)
@@ -0,0 +1,15 @@
+package types
+
+// feegrant module events
+const (
+	EventTypeSetFeeGrant    = "set_feegrant"
+	EventTypeRevokeFeeGrant = "revoke_feegrant"
+	EventTypePruneFeeGrant  = "prune_feegrant"
+	EventTypeUseFeeGrant    = "use_feegrant"
+
+	AttributeKeyGranter = "granter"
+	AttributeKeyGrantee = "grantee"
+	AttributeKeyAmount  = "amount"
+
+	AttributeValueCategory = ModuleName
+)
@@ -0,0 +1,138 @@
+package types
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+// FeeAllowanceI implementations are tied to a given fee delegator and delegatee,
+// and are used to enforce fee grant limits.
+type FeeAllowanceI interface {
+	// Accept can use fee payment requested as well as timestamp of the current block
+	// to determine whether or not to process this.
+	//
+	// If it returns an error, the fee payment is rejected, otherwise it is accepted.
+	// The FeeAllowance implementation is expected to update it's internal state
+	// and will be saved again after an acceptance.
+	//
+	// If remove is true (regardless of the error), the FeeAllowance will be deleted from storage
+	// (eg. when it is used up).
+	//
+	// An empty or zero fee must leave the FeeAllowance unchanged and not remove it,
+	// unless the FeeAllowance has expired.
+	Accept(ctx sdk.Context, fee sdk.Coins, msgs []sdk.Msg) (remove bool, err error)
+
+	// CanAccept returns the error Accept would return for the same fee and
+	// msgs, without modifying the FeeAllowance.
+	CanAccept(ctx sdk.Context, fee sdk.Coins, msgs []sdk.Msg) error
+
+	// ValidateBasic should evaluate this FeeAllowance for internal consistency.
+	// Don't allow negative amounts, or negative periods for example.
+	ValidateBasic() error
+
+	// ExpiresAt returns the expiry time of the allowance, or nil if it never
+	// expires.
+	ExpiresAt() (*time.Time, error)
+
+	// ExpiresAtHeight returns the last block height at which the allowance can
+	// be used, or 0 if it does not expire at a height.
+	ExpiresAtHeight() (int64, error)
+
+	// Remaining returns the fees that could be spent from this FeeAllowance in
+	// the block of ctx, without modifying it. It returns empty coins once the
+	// allowance has expired, as well as when the allowance has no spend limit.
+	Remaining(ctx sdk.Context) (sdk.Coins, error)
+
+	// RemainingSpendLimit returns the spend limit left on the FeeAllowance
+	// over its whole lifetime, regardless of any period or expiration, or nil
+	// if it has no spend limit.
+	RemainingSpendLimit() (sdk.Coins, error)
+}
+
+// wrappingAllowance is implemented by the allowances of this package which
+// wrap another allowance.
+type wrappingAllowance interface {
+	GetAllowance() (FeeAllowanceI, error)
+}
+
+// ValidateNewAllowance checks that allowance can be granted. On top of
+// ValidateBasic, which an allowance must pass for as long as it is granted, it
+// rejects a PeriodicFeeAllowance, possibly wrapped in other allowances, whose
+// PeriodSpendLimit exceeds its basic SpendLimit.
+func ValidateNewAllowance(allowance FeeAllowanceI) error {
+	if err := allowance.ValidateBasic(); err != nil {
+		return err
+	}
+
+	return validateSpendLimits(allowance)
+}
+
+func validateSpendLimits(allowance FeeAllowanceI) error {
+	if periodic, ok := allowance.(*PeriodicFeeAllowance); ok {
+		return periodic.validateSpendLimits()
+	}
+
+	if wrapper, ok := allowance.(wrappingAllowance); ok {
+		inner, err := wrapper.GetAllowance()
+		if err != nil {
+			return err
+		}
+
+		return validateSpendLimits(inner)
+	}
+
+	return nil
+}
+
+// IsExpired returns whether allowance, possibly wrapped in other allowances, has
+// expired by the block of ctx, by its expiration time or height, as Accept does.
+func IsExpired(ctx sdk.Context, allowance FeeAllowanceI) (bool, error) {
+	exp, err := allowance.ExpiresAt()
+	if err != nil {
+		return false, err
+	}
+
+	expHeight, err := allowance.ExpiresAtHeight()
+	if err != nil {
+		return false, err
+	}
+
+	basic := BasicAllowance{Expiration: exp, ExpirationHeight: expHeight}
+	return basic.isExpired(ctx.BlockHeight(), ctx.BlockTime()), nil
+}
+
+// SetExpiration sets the expiration time of allowance, which must be one of the
+// allowances of this package, possibly wrapped in others. It fails with
+// ErrInvalidAllowanceType for an allowance of another type.
+func SetExpiration(allowance FeeAllowanceI, exp time.Time) error {
+	switch a := allowance.(type) {
+	case *BasicAllowance:
+		a.Expiration = &exp
+		return nil
+	case *PeriodicFeeAllowance:
+		a.Basic.Expiration = &exp
+		return nil
+	}
+
+	wrapper, ok := allowance.(interface {
+		wrappingAllowance
+		SetAllowance(FeeAllowanceI) error
+	})
+	if !ok {
+		return sdkerrors.Wrapf(ErrInvalidAllowanceType, "cannot set the expiration of %T", allowance)
+	}
+
+	inner, err := wrapper.GetAllowance()
+	if err != nil {
+		return err
+	}
+
+	if err := SetExpiration(inner, exp); err != nil {
+		return err
+	}
+
+	// repack the wrapped allowance, whose encoding is stale
+	return wrapper.SetAllowance(inner)
+}
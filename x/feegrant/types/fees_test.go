@@ -0,0 +1,278 @@
+package types_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+)
+
+// TestFeeAllowanceIAnyRoundTrip checks that every concrete allowance is
+// registered as a FeeAllowanceI and survives being packed into an Any.
+func TestFeeAllowanceIAnyRoundTrip(t *testing.T) {
+	registry := codectypes.NewInterfaceRegistry()
+	types.RegisterInterfaces(registry)
+	cdc := codec.NewProtoCodec(registry)
+
+	exp := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	basic := &types.BasicAllowance{SpendLimit: atom, Expiration: &exp}
+	periodic := &types.PeriodicFeeAllowance{
+		Basic:            *basic,
+		Period:           time.Hour,
+		PeriodSpendLimit: atom,
+		PeriodCanSpend:   atom,
+		PeriodReset:      exp.Add(-time.Hour),
+	}
+	filtered, err := types.NewAllowedMsgAllowance(periodic, []string{"/cosmos.gov.v1beta1.MsgVote"})
+	require.NoError(t, err)
+	denoms, err := types.NewAllowedDenomAllowance(basic, []string{"atom"})
+	require.NoError(t, err)
+
+	for name, allowance := range map[string]types.FeeAllowanceI{
+		"basic":    basic,
+		"periodic": periodic,
+		"filtered": filtered,
+		"denoms":   denoms,
+	} {
+		allowance := allowance
+		t.Run(name, func(t *testing.T) {
+			msg, ok := allowance.(proto.Message)
+			require.True(t, ok)
+			any, err := codectypes.NewAnyWithValue(msg)
+			require.NoError(t, err)
+
+			bz, err := cdc.MarshalBinaryBare(any)
+			require.NoError(t, err)
+
+			var decodedAny codectypes.Any
+			require.NoError(t, cdc.UnmarshalBinaryBare(bz, &decodedAny))
+
+			var decoded types.FeeAllowanceI
+			require.NoError(t, cdc.UnpackAny(&decodedAny, &decoded))
+			require.NoError(t, decoded.ValidateBasic())
+			require.Equal(t, allowance, decoded)
+		})
+	}
+}
+
+// TestAcceptFeeExceedsLimit checks that a fee going over a spend limit is
+// rejected with ErrFeeLimitExceeded, without panicking nor spending anything.
+func TestAcceptFeeExceedsLimit(t *testing.T) {
+	app := simapp.Setup(false)
+	now := time.Now()
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: now})
+
+	limit := sdk.NewCoins(sdk.NewInt64Coin("atom", 100), sdk.NewInt64Coin("eth", 10))
+	allowances := map[string]func() types.FeeAllowanceI{
+		"basic": func() types.FeeAllowanceI {
+			return &types.BasicAllowance{SpendLimit: limit}
+		},
+		"periodic basic limit": func() types.FeeAllowanceI {
+			return &types.PeriodicFeeAllowance{
+				Basic:            types.BasicAllowance{SpendLimit: limit},
+				Period:           time.Hour,
+				PeriodSpendLimit: limit.Add(limit...),
+				PeriodCanSpend:   limit.Add(limit...),
+				PeriodReset:      now.Add(time.Hour),
+			}
+		},
+		"periodic period limit": func() types.FeeAllowanceI {
+			return &types.PeriodicFeeAllowance{
+				Period:           time.Hour,
+				PeriodSpendLimit: limit,
+				PeriodCanSpend:   limit,
+				PeriodReset:      now.Add(time.Hour),
+			}
+		},
+	}
+	fees := map[string]sdk.Coins{
+		"one denom over the limit": sdk.NewCoins(sdk.NewInt64Coin("atom", 1), sdk.NewInt64Coin("eth", 11)),
+		"denom not in the limit":   sdk.NewCoins(sdk.NewInt64Coin("atom", 1), sdk.NewInt64Coin("btc", 1)),
+	}
+
+	for name, newAllowance := range allowances {
+		for feeName, fee := range fees {
+			newAllowance, fee := newAllowance, fee
+			t.Run(name+"/"+feeName, func(t *testing.T) {
+				allowance := newAllowance()
+
+				var (
+					remove bool
+					err    error
+				)
+				require.NotPanics(t, func() {
+					remove, err = allowance.Accept(ctx, fee, nil)
+				})
+				require.True(t, errors.Is(err, types.ErrFeeLimitExceeded), err)
+				require.False(t, remove)
+				require.Equal(t, newAllowance(), allowance)
+			})
+		}
+	}
+}
+
+// TestCanAccept checks that CanAccept agrees with Accept, for every allowance,
+// without modifying the allowance.
+func TestCanAccept(t *testing.T) {
+	app := simapp.Setup(false)
+	now := time.Now()
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: now})
+
+	addrs := simapp.AddTestAddrs(app, ctx, 2, sdk.NewInt(10))
+	vote := govtypes.NewMsgVote(addrs[0], 1, govtypes.OptionYes)
+	send := banktypes.NewMsgSend(addrs[0], addrs[1], sdk.NewCoins(sdk.NewInt64Coin("atom", 1)))
+
+	limit := sdk.NewCoins(sdk.NewInt64Coin("atom", 100))
+	newBasic := func() *types.BasicAllowance {
+		return &types.BasicAllowance{SpendLimit: limit}
+	}
+	newPeriodic := func() *types.PeriodicFeeAllowance {
+		// the period is over, so that Accept also resets it
+		return &types.PeriodicFeeAllowance{
+			Basic:            *newBasic(),
+			Period:           time.Hour,
+			PeriodSpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 50)),
+			PeriodCanSpend:   sdk.NewCoins(sdk.NewInt64Coin("atom", 5)),
+			PeriodReset:      now.Add(-time.Minute),
+		}
+	}
+
+	allowances := map[string]func() types.FeeAllowanceI{
+		"basic":    func() types.FeeAllowanceI { return newBasic() },
+		"periodic": func() types.FeeAllowanceI { return newPeriodic() },
+		"expired": func() types.FeeAllowanceI {
+			expired := now.Add(-time.Hour)
+			return &types.BasicAllowance{SpendLimit: limit, Expiration: &expired}
+		},
+		"filtered": func() types.FeeAllowanceI {
+			allowance, err := types.NewAllowedMsgAllowance(newPeriodic(), []string{"/cosmos.gov.v1beta1.MsgVote"})
+			require.NoError(t, err)
+			return allowance
+		},
+		"denoms": func() types.FeeAllowanceI {
+			allowance, err := types.NewAllowedDenomAllowance(newPeriodic(), []string{"atom"})
+			require.NoError(t, err)
+			return allowance
+		},
+		"fraction": func() types.FeeAllowanceI {
+			allowance, err := types.NewCappedFractionAllowance(newPeriodic(), sdk.NewDecWithPrec(5, 1))
+			require.NoError(t, err)
+			return allowance
+		},
+	}
+	fees := map[string]sdk.Coins{
+		"within the period limit": sdk.NewCoins(sdk.NewInt64Coin("atom", 20)),
+		"over the period limit":   sdk.NewCoins(sdk.NewInt64Coin("atom", 80)),
+		"whole spend limit":       limit,
+		"over the spend limit":    sdk.NewCoins(sdk.NewInt64Coin("atom", 101)),
+		"denom not allowed":       sdk.NewCoins(sdk.NewInt64Coin("eth", 1)),
+	}
+	msgs := map[string][]sdk.Msg{
+		"vote": {vote},
+		"send": {vote, send},
+	}
+
+	for name, newAllowance := range allowances {
+		for feeName, fee := range fees {
+			for msgsName, msgs := range msgs {
+				newAllowance, fee, msgs := newAllowance, fee, msgs
+				t.Run(name+"/"+feeName+"/"+msgsName, func(t *testing.T) {
+					allowance := newAllowance()
+					err := allowance.CanAccept(ctx, fee, msgs)
+					require.Equal(t, newAllowance(), allowance)
+
+					_, acceptErr := newAllowance().Accept(ctx, fee, msgs)
+					if acceptErr == nil {
+						require.NoError(t, err)
+					} else {
+						require.EqualError(t, err, acceptErr.Error())
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestRemainingSpendLimit(t *testing.T) {
+	limit := sdk.NewCoins(sdk.NewInt64Coin("atom", 100))
+	periodic := &types.PeriodicFeeAllowance{
+		Basic:            types.BasicAllowance{SpendLimit: limit},
+		Period:           time.Hour,
+		PeriodSpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 10)),
+	}
+	capped, err := types.NewCappedFractionAllowance(periodic, sdk.NewDecWithPrec(5, 1))
+	require.NoError(t, err)
+	denoms, err := types.NewAllowedDenomAllowance(capped, []string{"atom"})
+	require.NoError(t, err)
+	filtered, err := types.NewAllowedMsgAllowance(denoms, []string{"/cosmos.gov.v1beta1.MsgVote"})
+	require.NoError(t, err)
+
+	cases := map[string]struct {
+		allowance types.FeeAllowanceI
+		expected  sdk.Coins
+	}{
+		"basic":     {&types.BasicAllowance{SpendLimit: limit}, limit},
+		"unlimited": {&types.BasicAllowance{}, nil},
+		"periodic":  {periodic, limit},
+		"wrapped":   {filtered, limit},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			remaining, err := tc.allowance.RemainingSpendLimit()
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, remaining)
+		})
+	}
+
+	// a wrapper without an inner allowance
+	_, err = (&types.AllowedDenomAllowance{}).RemainingSpendLimit()
+	require.True(t, errors.Is(err, types.ErrNoAllowance))
+}
+
+func TestIsExpired(t *testing.T) {
+	now := time.Now()
+	ctx := sdk.Context{}.WithBlockTime(now).WithBlockHeight(10)
+	past := now.Add(-time.Minute)
+
+	wrap := func(allowance types.FeeAllowanceI) types.FeeAllowanceI {
+		wrapped, err := types.NewAllowedDenomAllowance(allowance, []string{"atom"})
+		require.NoError(t, err)
+		return wrapped
+	}
+
+	cases := map[string]struct {
+		allowance types.FeeAllowanceI
+		expired   bool
+	}{
+		"no expiration":     {&types.BasicAllowance{}, false},
+		"expired time":      {&types.BasicAllowance{Expiration: &past}, true},
+		"at height":         {&types.BasicAllowance{ExpirationHeight: 10}, false},
+		"expired height":    {&types.BasicAllowance{ExpirationHeight: 9}, true},
+		"wrapped height":    {wrap(&types.BasicAllowance{ExpirationHeight: 9}), true},
+		"wrapped periodic":  {wrap(&types.PeriodicFeeAllowance{Basic: types.BasicAllowance{Expiration: &past}}), true},
+		"wrapped unexpired": {wrap(&types.BasicAllowance{ExpirationHeight: 10}), false},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			expired, err := types.IsExpired(ctx, tc.allowance)
+			require.NoError(t, err)
+			require.Equal(t, tc.expired, expired)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package types
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+// terms of a grant filter expression, see ParseGrantFilter
+const (
+	FilterTermType           = "type"
+	FilterTermGranter        = "granter"
+	FilterTermGrantee        = "grantee"
+	FilterTermMinRemaining   = "min_remaining"
+	FilterTermExpiringBefore = "expiring_before"
+)
+
+// GrantFilter selects grants by the terms of a filter expression. Its unset
+// fields match any grant.
+type GrantFilter struct {
+	// Granter is the address of the granter of the grants.
+	Granter sdk.AccAddress
+	// Grantee is the address of the grantee of the grants.
+	Grantee sdk.AccAddress
+	// Type is the type URL of the allowance of the grants, e.g.
+	// /cosmos.feegrant.v1beta1.BasicAllowance. A wrapped allowance is not
+	// matched by its inner type.
+	Type string
+	// MinRemaining is the minimum amount of fees the allowance of the grants
+	// can still pay, see FeeAllowanceI.Remaining. An unexpired allowance
+	// without a spend limit has more than any amount left.
+	MinRemaining sdk.Coins
+	// ExpiringBefore is the time, excluded, before which the allowance of the
+	// grants expires. Allowances without an expiration time do not match.
+	ExpiringBefore *time.Time
+}
+
+// ParseGrantFilter parses a filter expression made of terms separated by
+// spaces, all of which a grant must match, e.g.
+//
+//	granter=cosmos1... type=/cosmos.feegrant.v1beta1.BasicAllowance min_remaining=10atom,5stake
+//
+// The terms are type, granter, grantee, min_remaining, whose value is a list
+// of coins, and expiring_before, whose value is an RFC 3339 time. Each term
+// may only appear once. An empty expression matches all grants.
+func ParseGrantFilter(expr string) (GrantFilter, error) {
+	var filter GrantFilter
+
+	seen := make(map[string]bool)
+	for _, term := range strings.Fields(expr) {
+		kv := strings.SplitN(term, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return GrantFilter{}, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "filter term %q is not of the form key=value", term)
+		}
+
+		key, value := kv[0], kv[1]
+		if seen[key] {
+			return GrantFilter{}, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "duplicate filter term %s", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case FilterTermType:
+			filter.Type = value
+		case FilterTermGranter:
+			filter.Granter, err = sdk.AccAddressFromBech32(value)
+		case FilterTermGrantee:
+			filter.Grantee, err = sdk.AccAddressFromBech32(value)
+		case FilterTermMinRemaining:
+			filter.MinRemaining, err = sdk.ParseCoinsNormalized(value)
+		case FilterTermExpiringBefore:
+			var t time.Time
+			t, err = time.Parse(time.RFC3339, value)
+			filter.ExpiringBefore = &t
+		default:
+			return GrantFilter{}, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "unknown filter term %s", key)
+		}
+
+		if err != nil {
+			return GrantFilter{}, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid %s: %s", key, err)
+		}
+	}
+
+	return filter, nil
+}
+
+// Matches returns whether the grant from granter to grantee of allowance
+// matches the filter by the block of ctx.
+func (f GrantFilter) Matches(ctx sdk.Context, granter, grantee sdk.AccAddress, allowance FeeAllowanceI) (bool, error) {
+	if f.Granter != nil && !f.Granter.Equals(granter) {
+		return false, nil
+	}
+
+	if f.Grantee != nil && !f.Grantee.Equals(grantee) {
+		return false, nil
+	}
+
+	if f.Type != "" {
+		msg, ok := allowance.(proto.Message)
+		if !ok || "/"+proto.MessageName(msg) != f.Type {
+			return false, nil
+		}
+	}
+
+	if f.ExpiringBefore != nil {
+		exp, err := allowance.ExpiresAt()
+		if err != nil {
+			return false, err
+		}
+
+		if exp == nil || !exp.Before(*f.ExpiringBefore) {
+			return false, nil
+		}
+	}
+
+	if !f.MinRemaining.Empty() {
+		return f.hasMinRemaining(ctx, allowance)
+	}
+
+	return true, nil
+}
+
+func (f GrantFilter) hasMinRemaining(ctx sdk.Context, allowance FeeAllowanceI) (bool, error) {
+	expired, err := IsExpired(ctx, allowance)
+	if err != nil || expired {
+		return false, err
+	}
+
+	remaining, err := allowance.Remaining(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if !remaining.Empty() {
+		return remaining.IsAllGTE(f.MinRemaining), nil
+	}
+
+	// nothing left to spend, unless there is no limit to begin with
+	limit, err := allowance.RemainingSpendLimit()
+	if err != nil {
+		return false, err
+	}
+
+	return limit.Empty(), nil
+}
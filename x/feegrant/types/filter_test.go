@@ -0,0 +1,79 @@
+package types_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+func TestParseGrantFilter(t *testing.T) {
+	granter := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	grantee := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	exp := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		expr   string
+		valid  bool
+		filter types.GrantFilter
+	}{
+		"empty": {
+			expr:  "  ",
+			valid: true,
+		},
+		"all terms": {
+			expr: "type=/cosmos.feegrant.v1beta1.BasicAllowance granter=" + granter.String() + " grantee=" + grantee.String() +
+				"  min_remaining=5stake,10atom expiring_before=2022-01-01T00:00:00Z",
+			valid: true,
+			filter: types.GrantFilter{
+				Granter:        granter,
+				Grantee:        grantee,
+				Type:           "/cosmos.feegrant.v1beta1.BasicAllowance",
+				MinRemaining:   sdk.NewCoins(sdk.NewInt64Coin("atom", 10), sdk.NewInt64Coin("stake", 5)),
+				ExpiringBefore: &exp,
+			},
+		},
+		"no value": {
+			expr: "granter=",
+		},
+		"no key": {
+			expr: "=5stake",
+		},
+		"not a term": {
+			expr: "granter",
+		},
+		"unknown term": {
+			expr: "fraction=0.5",
+		},
+		"duplicate term": {
+			expr: "type=a type=b",
+		},
+		"invalid address": {
+			expr: "grantee=cosmos1invalid",
+		},
+		"invalid coins": {
+			expr: "min_remaining=5",
+		},
+		"invalid time": {
+			expr: "expiring_before=2022-01-01",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			filter, err := types.ParseGrantFilter(tc.expr)
+			if !tc.valid {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.filter, filter)
+		})
+	}
+}
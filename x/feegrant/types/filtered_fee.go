@@ -0,0 +1,255 @@
+package types
+
+import (
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+var (
+	_ FeeAllowanceI                 = (*AllowedMsgAllowance)(nil)
+	_ types.UnpackInterfacesMessage = (*AllowedMsgAllowance)(nil)
+)
+
+// NewAllowedMsgAllowance creates new filtered fee allowance.
+func NewAllowedMsgAllowance(allowance FeeAllowanceI, allowedMsgs []string) (*AllowedMsgAllowance, error) {
+	msg, ok := allowance.(proto.Message)
+	if !ok {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrPackAny, "cannot proto marshal %T", allowance)
+	}
+	any, err := types.NewAnyWithValue(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AllowedMsgAllowance{
+		Allowance:       any,
+		AllowedMessages: allowedMsgs,
+	}, nil
+}
+
+// UnpackInterfaces implements UnpackInterfacesMessage.UnpackInterfaces
+func (a *AllowedMsgAllowance) UnpackInterfaces(unpacker types.AnyUnpacker) error {
+	var allowance FeeAllowanceI
+	return unpacker.UnpackAny(a.Allowance, &allowance)
+}
+
+// GetAllowance returns allowed fee allowance.
+func (a *AllowedMsgAllowance) GetAllowance() (FeeAllowanceI, error) {
+	if a.Allowance == nil {
+		return nil, sdkerrors.Wrap(ErrNoAllowance, "allowance should not be empty")
+	}
+
+	allowance, ok := a.Allowance.GetCachedValue().(FeeAllowanceI)
+	if !ok {
+		return nil, sdkerrors.Wrap(ErrNoAllowance, "failed to get allowance")
+	}
+
+	return allowance, nil
+}
+
+// SetAllowance sets allowed fee allowance.
+func (a *AllowedMsgAllowance) SetAllowance(allowance FeeAllowanceI) error {
+	var err error
+	a.Allowance, err = types.NewAnyWithValue(allowance.(proto.Message))
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrPackAny, "cannot proto marshal %T", allowance)
+	}
+
+	return nil
+}
+
+// Accept checks that every message is of an allowed type before delegating to
+// the wrapped allowance. A disallowed message returns an error but never
+// removes the grant.
+func (a *AllowedMsgAllowance) Accept(ctx sdk.Context, fee sdk.Coins, msgs []sdk.Msg) (bool, error) {
+	if !a.allMsgTypesAllowed(msgs) {
+		return false, sdkerrors.Wrap(ErrMessageNotAllowed, "message does not exist in allowed messages")
+	}
+
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return false, err
+	}
+
+	remove, err := allowance.Accept(ctx, fee, msgs)
+	if err == nil && !remove {
+		if err = a.SetAllowance(allowance); err != nil {
+			return false, err
+		}
+	}
+
+	return remove, err
+}
+
+// CanAccept checks whether Accept would accept fee, see FeeAllowanceI.
+func (a *AllowedMsgAllowance) CanAccept(ctx sdk.Context, fee sdk.Coins, msgs []sdk.Msg) error {
+	if !a.allMsgTypesAllowed(msgs) {
+		return sdkerrors.Wrap(ErrMessageNotAllowed, "message does not exist in allowed messages")
+	}
+
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return err
+	}
+
+	return allowance.CanAccept(ctx, fee, msgs)
+}
+
+func (a *AllowedMsgAllowance) allowedMsgsToMap() map[string]bool {
+	msgsMap := make(map[string]bool, len(a.AllowedMessages))
+	for _, msg := range a.AllowedMessages {
+		msgsMap[msg] = true
+	}
+
+	return msgsMap
+}
+
+func (a *AllowedMsgAllowance) allMsgTypesAllowed(msgs []sdk.Msg) bool {
+	msgsMap := a.allowedMsgsToMap()
+
+	for _, msg := range msgs {
+		if !msgsMap[msgTypeURL(msg)] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ValidateBasic implements FeeAllowance and enforces basic sanity checks
+func (a *AllowedMsgAllowance) ValidateBasic() error {
+	if a.Allowance == nil {
+		return sdkerrors.Wrap(ErrNoAllowance, "allowance should not be empty")
+	}
+
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return err
+	}
+
+	// nested message filters would only intersect, and recurse on Accept,
+	// even with other allowances wrapped in between
+	nested, err := wrapsAllowedMsgAllowance(allowance)
+	if err != nil {
+		return err
+	}
+	if nested {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidType, "allowed msg allowance cannot wrap another allowed msg allowance")
+	}
+
+	return allowance.ValidateBasic()
+}
+
+// wrapsAllowedMsgAllowance returns whether allowance, or any allowance it
+// wraps, is an AllowedMsgAllowance.
+func wrapsAllowedMsgAllowance(allowance FeeAllowanceI) (bool, error) {
+	for {
+		if _, ok := allowance.(*AllowedMsgAllowance); ok {
+			return true, nil
+		}
+
+		wrapper, ok := allowance.(wrappingAllowance)
+		if !ok {
+			return false, nil
+		}
+
+		var err error
+		if allowance, err = wrapper.GetAllowance(); err != nil {
+			return false, err
+		}
+	}
+}
+
+// ExpiresAt returns the expiry time of the wrapped allowance.
+func (a *AllowedMsgAllowance) ExpiresAt() (*time.Time, error) {
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return nil, err
+	}
+
+	return allowance.ExpiresAt()
+}
+
+// ExpiresAtHeight returns the expiration height of the wrapped allowance.
+func (a *AllowedMsgAllowance) ExpiresAtHeight() (int64, error) {
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return 0, err
+	}
+
+	return allowance.ExpiresAtHeight()
+}
+
+// Remaining returns what remains on the wrapped allowance.
+func (a *AllowedMsgAllowance) Remaining(ctx sdk.Context) (sdk.Coins, error) {
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return nil, err
+	}
+
+	return allowance.Remaining(ctx)
+}
+
+// RemainingSpendLimit returns the spend limit left on the wrapped allowance.
+func (a *AllowedMsgAllowance) RemainingSpendLimit() (sdk.Coins, error) {
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return nil, err
+	}
+
+	return allowance.RemainingSpendLimit()
+}
+
+// DisallowedMsgTypes returns the type URLs, out of typeURLs, of the msgs which
+// allowance does not allow. Only an AllowedMsgAllowance, possibly wrapped in
+// other allowances, filters msgs: other allowances allow every msg.
+func DisallowedMsgTypes(allowance FeeAllowanceI, typeURLs []string) ([]string, error) {
+	if a, ok := allowance.(*AllowedMsgAllowance); ok {
+		msgsMap := a.allowedMsgsToMap()
+
+		var allowed, disallowed []string
+		for _, typeURL := range typeURLs {
+			if msgsMap[typeURL] {
+				allowed = append(allowed, typeURL)
+			} else {
+				disallowed = append(disallowed, typeURL)
+			}
+		}
+
+		// the wrapped allowance may filter msgs further
+		inner, err := a.GetAllowance()
+		if err != nil {
+			return nil, err
+		}
+
+		innerDisallowed, err := DisallowedMsgTypes(inner, allowed)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(disallowed, innerDisallowed...), nil
+	}
+
+	wrapper, ok := allowance.(wrappingAllowance)
+	if !ok {
+		return nil, nil
+	}
+
+	inner, err := wrapper.GetAllowance()
+	if err != nil {
+		return nil, err
+	}
+
+	return DisallowedMsgTypes(inner, typeURLs)
+}
+
+// msgTypeURL returns the TypeURL of a sdk.Msg, as it would be used to pack it
+// into an Any.
+func msgTypeURL(msg sdk.Msg) string {
+	return "/" + proto.MessageName(msg)
+}
@@ -0,0 +1,131 @@
+package types_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+)
+
+func TestAllowedMsgAllowance(t *testing.T) {
+	app := simapp.Setup(false)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: time.Now()})
+
+	addrs := simapp.AddTestAddrs(app, ctx, 2, sdk.NewInt(10))
+	vote := govtypes.NewMsgVote(addrs[0], 1, govtypes.OptionYes)
+	send := banktypes.NewMsgSend(addrs[0], addrs[1], sdk.NewCoins(sdk.NewInt64Coin("atom", 1)))
+
+	voteURL := "/cosmos.gov.v1beta1.MsgVote"
+	fee := sdk.NewCoins(sdk.NewInt64Coin("atom", 10))
+
+	cases := map[string]struct {
+		allowed []string
+		msgs    []sdk.Msg
+		accept  bool
+	}{
+		"allowed only": {
+			allowed: []string{voteURL},
+			msgs:    []sdk.Msg{vote, vote},
+			accept:  true,
+		},
+		"mixed allowed and disallowed": {
+			allowed: []string{voteURL},
+			msgs:    []sdk.Msg{vote, send},
+			accept:  false,
+		},
+		"empty allowed list": {
+			allowed: []string{},
+			msgs:    []sdk.Msg{vote},
+			accept:  false,
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			basic := &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 100))}
+			allowance, err := types.NewAllowedMsgAllowance(basic, tc.allowed)
+			require.NoError(t, err)
+			require.NoError(t, allowance.ValidateBasic())
+
+			remove, err := allowance.Accept(ctx, fee, tc.msgs)
+			require.False(t, remove)
+			if !tc.accept {
+				require.True(t, errors.Is(err, types.ErrMessageNotAllowed))
+
+				inner, err := allowance.GetAllowance()
+				require.NoError(t, err)
+				require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("atom", 100)), inner.(*types.BasicAllowance).SpendLimit)
+				return
+			}
+			require.NoError(t, err)
+
+			inner, err := allowance.GetAllowance()
+			require.NoError(t, err)
+			require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("atom", 90)), inner.(*types.BasicAllowance).SpendLimit)
+		})
+	}
+}
+
+func TestAllowedMsgAllowanceNested(t *testing.T) {
+	basic := &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 100))}
+	inner, err := types.NewAllowedMsgAllowance(basic, []string{"/cosmos.gov.v1beta1.MsgVote"})
+	require.NoError(t, err)
+
+	nested, err := types.NewAllowedMsgAllowance(inner, []string{"/cosmos.gov.v1beta1.MsgVote"})
+	require.NoError(t, err)
+
+	err = nested.ValidateBasic()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "cannot wrap another allowed msg allowance")
+
+	// a filter nested deeper, under other wrapping allowances, is rejected too
+	denoms, err := types.NewAllowedDenomAllowance(inner, []string{"atom"})
+	require.NoError(t, err)
+	fraction, err := types.NewCappedFractionAllowance(denoms, sdk.NewDecWithPrec(5, 1))
+	require.NoError(t, err)
+
+	for name, wrapped := range map[string]types.FeeAllowanceI{"denoms": denoms, "fraction": fraction} {
+		require.NoError(t, wrapped.ValidateBasic(), name)
+
+		nested, err := types.NewAllowedMsgAllowance(wrapped, []string{"/cosmos.gov.v1beta1.MsgVote"})
+		require.NoError(t, err)
+
+		err = nested.ValidateBasic()
+		require.Error(t, err, name)
+		require.Contains(t, err.Error(), "cannot wrap another allowed msg allowance", name)
+	}
+
+	// filters wrapping other allowances only are fine
+	denoms, err = types.NewAllowedDenomAllowance(basic, []string{"atom"})
+	require.NoError(t, err)
+	filtered, err := types.NewAllowedMsgAllowance(denoms, []string{"/cosmos.gov.v1beta1.MsgVote"})
+	require.NoError(t, err)
+	require.NoError(t, filtered.ValidateBasic())
+}
+
+func TestAllowedMsgAllowanceNilInner(t *testing.T) {
+	app := simapp.Setup(false)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: time.Now()})
+
+	addrs := simapp.AddTestAddrs(app, ctx, 1, sdk.NewInt(10))
+	vote := govtypes.NewMsgVote(addrs[0], 1, govtypes.OptionYes)
+
+	allowance := &types.AllowedMsgAllowance{AllowedMessages: []string{"/cosmos.gov.v1beta1.MsgVote"}}
+	require.True(t, errors.Is(allowance.ValidateBasic(), types.ErrNoAllowance))
+
+	remove, err := allowance.Accept(ctx, sdk.NewCoins(sdk.NewInt64Coin("atom", 10)), []sdk.Msg{vote})
+	require.False(t, remove)
+	require.True(t, errors.Is(err, types.ErrNoAllowance))
+
+	_, err = allowance.ExpiresAt()
+	require.True(t, errors.Is(err, types.ErrNoAllowance))
+}
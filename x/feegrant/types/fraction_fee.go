@@ -0,0 +1,186 @@
+package types
+
+import (
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+var (
+	_ FeeAllowanceI                 = (*CappedFractionAllowance)(nil)
+	_ types.UnpackInterfacesMessage = (*CappedFractionAllowance)(nil)
+)
+
+// NewCappedFractionAllowance creates a new allowance paying the given fraction
+// of each fee out of allowance.
+func NewCappedFractionAllowance(allowance FeeAllowanceI, fraction sdk.Dec) (*CappedFractionAllowance, error) {
+	msg, ok := allowance.(proto.Message)
+	if !ok {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrPackAny, "cannot proto marshal %T", allowance)
+	}
+	any, err := types.NewAnyWithValue(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CappedFractionAllowance{
+		Allowance: any,
+		Fraction:  fraction,
+	}, nil
+}
+
+// UnpackInterfaces implements UnpackInterfacesMessage.UnpackInterfaces
+func (a *CappedFractionAllowance) UnpackInterfaces(unpacker types.AnyUnpacker) error {
+	var allowance FeeAllowanceI
+	return unpacker.UnpackAny(a.Allowance, &allowance)
+}
+
+// GetAllowance returns the wrapped fee allowance.
+func (a *CappedFractionAllowance) GetAllowance() (FeeAllowanceI, error) {
+	if a.Allowance == nil {
+		return nil, sdkerrors.Wrap(ErrNoAllowance, "allowance should not be empty")
+	}
+
+	allowance, ok := a.Allowance.GetCachedValue().(FeeAllowanceI)
+	if !ok {
+		return nil, sdkerrors.Wrap(ErrNoAllowance, "failed to get allowance")
+	}
+
+	return allowance, nil
+}
+
+// SetAllowance sets the wrapped fee allowance.
+func (a *CappedFractionAllowance) SetAllowance(allowance FeeAllowanceI) error {
+	var err error
+	a.Allowance, err = types.NewAnyWithValue(allowance.(proto.Message))
+	if err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrPackAny, "cannot proto marshal %T", allowance)
+	}
+
+	return nil
+}
+
+// CoveredFee returns the part of fee paid by this allowance: Fraction of the
+// amount of each coin, rounded down.
+func (a *CappedFractionAllowance) CoveredFee(fee sdk.Coins) sdk.Coins {
+	covered := sdk.Coins{}
+	for _, coin := range fee {
+		amount := coin.Amount.ToDec().Mul(a.Fraction).TruncateInt()
+		if amount.IsPositive() {
+			covered = covered.Add(sdk.NewCoin(coin.Denom, amount))
+		}
+	}
+
+	return covered
+}
+
+// Accept delegates only the covered part of the fee, see CoveredFee, to the
+// wrapped allowance.
+func (a *CappedFractionAllowance) Accept(ctx sdk.Context, fee sdk.Coins, msgs []sdk.Msg) (bool, error) {
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return false, err
+	}
+
+	remove, err := allowance.Accept(ctx, a.CoveredFee(fee), msgs)
+	if err == nil && !remove {
+		if err = a.SetAllowance(allowance); err != nil {
+			return false, err
+		}
+	}
+
+	return remove, err
+}
+
+// CanAccept checks whether Accept would accept fee, see FeeAllowanceI.
+func (a *CappedFractionAllowance) CanAccept(ctx sdk.Context, fee sdk.Coins, msgs []sdk.Msg) error {
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return err
+	}
+
+	return allowance.CanAccept(ctx, a.CoveredFee(fee), msgs)
+}
+
+// ValidateBasic implements FeeAllowance and enforces basic sanity checks
+func (a *CappedFractionAllowance) ValidateBasic() error {
+	if a.Allowance == nil {
+		return sdkerrors.Wrap(ErrNoAllowance, "allowance should not be empty")
+	}
+
+	if a.Fraction.IsNil() || !a.Fraction.IsPositive() || a.Fraction.GT(sdk.OneDec()) {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "fraction must be greater than 0 and at most 1, got %s", a.Fraction)
+	}
+
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return err
+	}
+
+	return allowance.ValidateBasic()
+}
+
+// ExpiresAt returns the expiry time of the wrapped allowance.
+func (a *CappedFractionAllowance) ExpiresAt() (*time.Time, error) {
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return nil, err
+	}
+
+	return allowance.ExpiresAt()
+}
+
+// ExpiresAtHeight returns the expiration height of the wrapped allowance.
+func (a *CappedFractionAllowance) ExpiresAtHeight() (int64, error) {
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return 0, err
+	}
+
+	return allowance.ExpiresAtHeight()
+}
+
+// Remaining returns what remains on the wrapped allowance.
+func (a *CappedFractionAllowance) Remaining(ctx sdk.Context) (sdk.Coins, error) {
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return nil, err
+	}
+
+	return allowance.Remaining(ctx)
+}
+
+// RemainingSpendLimit returns the spend limit left on the wrapped allowance.
+func (a *CappedFractionAllowance) RemainingSpendLimit() (sdk.Coins, error) {
+	allowance, err := a.GetAllowance()
+	if err != nil {
+		return nil, err
+	}
+
+	return allowance.RemainingSpendLimit()
+}
+
+// CoveredFee returns the part of fee paid by allowance. Only a
+// CappedFractionAllowance, possibly wrapped in other allowances, covers less
+// than the whole fee.
+func CoveredFee(allowance FeeAllowanceI, fee sdk.Coins) (sdk.Coins, error) {
+	wrapper, ok := allowance.(wrappingAllowance)
+	if !ok {
+		return fee, nil
+	}
+
+	if capped, ok := allowance.(*CappedFractionAllowance); ok {
+		fee = capped.CoveredFee(fee)
+	}
+
+	inner, err := wrapper.GetAllowance()
+	if err != nil {
+		return nil, err
+	}
+
+	return CoveredFee(inner, fee)
+}
@@ -0,0 +1,128 @@
+package types_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+func TestCappedFractionAllowance(t *testing.T) {
+	app := simapp.Setup(false)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: time.Now()})
+
+	limit := sdk.NewCoins(sdk.NewInt64Coin("atom", 1000), sdk.NewInt64Coin("eth", 1000))
+
+	cases := map[string]struct {
+		fraction   sdk.Dec
+		fee        sdk.Coins
+		valid      bool
+		expCovered sdk.Coins
+	}{
+		"zero coverage": {
+			fraction: sdk.ZeroDec(),
+			fee:      sdk.NewCoins(sdk.NewInt64Coin("atom", 100)),
+			valid:    false,
+		},
+		"more than full coverage": {
+			fraction: sdk.NewDecWithPrec(15, 1),
+			fee:      sdk.NewCoins(sdk.NewInt64Coin("atom", 100)),
+			valid:    false,
+		},
+		"half coverage": {
+			fraction:   sdk.NewDecWithPrec(5, 1),
+			fee:        sdk.NewCoins(sdk.NewInt64Coin("atom", 100), sdk.NewInt64Coin("eth", 40)),
+			valid:      true,
+			expCovered: sdk.NewCoins(sdk.NewInt64Coin("atom", 50), sdk.NewInt64Coin("eth", 20)),
+		},
+		"half coverage rounds down": {
+			fraction:   sdk.NewDecWithPrec(5, 1),
+			fee:        sdk.NewCoins(sdk.NewInt64Coin("atom", 101), sdk.NewInt64Coin("eth", 3)),
+			valid:      true,
+			expCovered: sdk.NewCoins(sdk.NewInt64Coin("atom", 50), sdk.NewInt64Coin("eth", 1)),
+		},
+		"half coverage of a single unit covers nothing": {
+			fraction:   sdk.NewDecWithPrec(5, 1),
+			fee:        sdk.NewCoins(sdk.NewInt64Coin("atom", 1)),
+			valid:      true,
+			expCovered: sdk.Coins{},
+		},
+		"full coverage": {
+			fraction:   sdk.OneDec(),
+			fee:        sdk.NewCoins(sdk.NewInt64Coin("atom", 101), sdk.NewInt64Coin("eth", 3)),
+			valid:      true,
+			expCovered: sdk.NewCoins(sdk.NewInt64Coin("atom", 101), sdk.NewInt64Coin("eth", 3)),
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			allowance, err := types.NewCappedFractionAllowance(&types.BasicAllowance{SpendLimit: limit}, tc.fraction)
+			require.NoError(t, err)
+			if !tc.valid {
+				require.Error(t, allowance.ValidateBasic())
+				return
+			}
+			require.NoError(t, allowance.ValidateBasic())
+
+			covered, err := types.CoveredFee(allowance, tc.fee)
+			require.NoError(t, err)
+			require.Equal(t, tc.expCovered, covered)
+
+			remove, err := allowance.Accept(ctx, tc.fee, nil)
+			require.NoError(t, err)
+			require.False(t, remove)
+
+			inner, err := allowance.GetAllowance()
+			require.NoError(t, err)
+			require.Equal(t, limit.Sub(tc.expCovered), inner.(*types.BasicAllowance).SpendLimit)
+		})
+	}
+}
+
+func TestCoveredFeeThroughFilters(t *testing.T) {
+	fee := sdk.NewCoins(sdk.NewInt64Coin("atom", 100))
+	basic := &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 1000))}
+
+	covered, err := types.CoveredFee(basic, fee)
+	require.NoError(t, err)
+	require.Equal(t, fee, covered)
+
+	fraction, err := types.NewCappedFractionAllowance(basic, sdk.NewDecWithPrec(25, 2))
+	require.NoError(t, err)
+	filtered, err := types.NewAllowedMsgAllowance(fraction, []string{"/cosmos.bank.v1beta1.MsgSend"})
+	require.NoError(t, err)
+
+	covered, err = types.CoveredFee(filtered, fee)
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("atom", 25)), covered)
+}
+
+func TestCappedFractionAllowanceNilInner(t *testing.T) {
+	app := simapp.Setup(false)
+	now := time.Now()
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: now})
+
+	allowance := &types.CappedFractionAllowance{Fraction: sdk.NewDecWithPrec(5, 1)}
+	require.True(t, errors.Is(allowance.ValidateBasic(), types.ErrNoAllowance))
+
+	fee := sdk.NewCoins(sdk.NewInt64Coin("atom", 10))
+	remove, err := allowance.Accept(ctx, fee, nil)
+	require.False(t, remove)
+	require.True(t, errors.Is(err, types.ErrNoAllowance))
+
+	require.True(t, errors.Is(allowance.CanAccept(ctx, fee, nil), types.ErrNoAllowance))
+
+	_, err = allowance.ExpiresAt()
+	require.True(t, errors.Is(err, types.ErrNoAllowance))
+
+	_, err = allowance.Remaining(ctx)
+	require.True(t, errors.Is(err, types.ErrNoAllowance))
+}
@@ -0,0 +1,121 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var _ types.UnpackInterfacesMessage = GenesisState{}
+
+// NewGenesisState creates new GenesisState object
+func NewGenesisState(params Params, entries []FeeAllowanceGrant, spendings []AllowanceSpendingRecord) *GenesisState {
+	return &GenesisState{
+		Params:             params,
+		FeeAllowances:      entries,
+		AllowanceSpendings: spendings,
+	}
+}
+
+// NewAllowanceSpendingRecord creates a new AllowanceSpendingRecord object
+func NewAllowanceSpendingRecord(granter, grantee sdk.AccAddress, spending AllowanceSpending) AllowanceSpendingRecord {
+	return AllowanceSpendingRecord{
+		Granter:  granter.String(),
+		Grantee:  grantee.String(),
+		Spending: spending,
+	}
+}
+
+// DefaultGenesisState returns the default feegrant genesis state
+func DefaultGenesisState() *GenesisState {
+	return &GenesisState{
+		Params:             DefaultParams(),
+		FeeAllowances:      []FeeAllowanceGrant{},
+		AllowanceSpendings: []AllowanceSpendingRecord{},
+	}
+}
+
+// ValidateGenesis ensures the params and all grants in the genesis state are
+// valid, that no granter/grantee pair appears more than once and that no
+// granter has more grants than MaxGrantsPerGranter. Spending records must be
+// valid, and each must belong to a grant of the genesis state.
+func ValidateGenesis(data GenesisState) error {
+	if err := data.Params.Validate(); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(data.FeeAllowances))
+	grantsByGranter := make(map[string]uint64)
+
+	for _, f := range data.FeeAllowances {
+		granter, err := sdk.AccAddressFromBech32(f.Granter)
+		if err != nil {
+			return err
+		}
+		grantee, err := sdk.AccAddressFromBech32(f.Grantee)
+		if err != nil {
+			return err
+		}
+
+		key := string(FeeAllowanceKey(granter, grantee))
+		if seen[key] {
+			return fmt.Errorf("duplicate fee allowance from granter %s to grantee %s", f.Granter, f.Grantee)
+		}
+		seen[key] = true
+
+		grantsByGranter[f.Granter]++
+		if grantsByGranter[f.Granter] > data.Params.MaxGrantsPerGranter {
+			return fmt.Errorf("granter %s has more than %d fee allowances", f.Granter, data.Params.MaxGrantsPerGranter)
+		}
+
+		grant, err := f.GetFeeGrant()
+		if err != nil {
+			return err
+		}
+		if err := grant.ValidateBasic(); err != nil {
+			return err
+		}
+	}
+
+	seenSpendings := make(map[string]bool, len(data.AllowanceSpendings))
+	for _, r := range data.AllowanceSpendings {
+		granter, err := sdk.AccAddressFromBech32(r.Granter)
+		if err != nil {
+			return err
+		}
+		grantee, err := sdk.AccAddressFromBech32(r.Grantee)
+		if err != nil {
+			return err
+		}
+
+		key := string(FeeAllowanceKey(granter, grantee))
+		if !seen[key] {
+			return fmt.Errorf("spending record without a fee allowance from granter %s to grantee %s", r.Granter, r.Grantee)
+		}
+		if seenSpendings[key] {
+			return fmt.Errorf("duplicate spending record from granter %s to grantee %s", r.Granter, r.Grantee)
+		}
+		seenSpendings[key] = true
+
+		if err := r.Spending.Spent.Validate(); err != nil {
+			return fmt.Errorf("invalid spent fees from granter %s to grantee %s: %w", r.Granter, r.Grantee, err)
+		}
+		if err := r.Spending.OriginalLimit.Validate(); err != nil {
+			return fmt.Errorf("invalid original limit from granter %s to grantee %s: %w", r.Granter, r.Grantee, err)
+		}
+	}
+
+	return nil
+}
+
+// UnpackInterfaces implements UnpackInterfacesMessage.UnpackInterfaces
+func (data GenesisState) UnpackInterfaces(unpacker types.AnyUnpacker) error {
+	for _, f := range data.FeeAllowances {
+		if err := f.UnpackInterfaces(unpacker); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package types_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+func TestValidateGenesis(t *testing.T) {
+	granter := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	grantee := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+
+	grant, err := types.NewFeeAllowanceGrant(granter, grantee, &types.BasicAllowance{SpendLimit: atom})
+	require.NoError(t, err)
+	reverse, err := types.NewFeeAllowanceGrant(grantee, granter, &types.BasicAllowance{SpendLimit: atom})
+	require.NoError(t, err)
+	other, err := types.NewFeeAllowanceGrant(granter, sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address()), &types.BasicAllowance{SpendLimit: atom})
+	require.NoError(t, err)
+	invalid, err := types.NewFeeAllowanceGrant(granter, grantee, &types.BasicAllowance{SpendLimit: sdk.Coins{sdk.Coin{Denom: "atom", Amount: sdk.NewInt(-1)}}})
+	require.NoError(t, err)
+
+	spending := types.NewAllowanceSpendingRecord(granter, grantee, types.AllowanceSpending{Spent: atom, OriginalLimit: atom, Since: time.Now()})
+	negative := types.NewAllowanceSpendingRecord(granter, grantee, types.AllowanceSpending{Spent: sdk.Coins{sdk.Coin{Denom: "atom", Amount: sdk.NewInt(-1)}}})
+	badLimit := types.NewAllowanceSpendingRecord(granter, grantee, types.AllowanceSpending{OriginalLimit: sdk.Coins{sdk.Coin{Denom: "atom", Amount: sdk.NewInt(0)}}})
+
+	cases := map[string]struct {
+		params    types.Params
+		grants    []types.FeeAllowanceGrant
+		spendings []types.AllowanceSpendingRecord
+		valid     bool
+	}{
+		"empty": {
+			params: types.DefaultParams(),
+			grants: nil,
+			valid:  true,
+		},
+		"valid": {
+			params: types.DefaultParams(),
+			grants: []types.FeeAllowanceGrant{grant, reverse},
+			valid:  true,
+		},
+		"duplicate pair": {
+			params: types.DefaultParams(),
+			grants: []types.FeeAllowanceGrant{grant, reverse, grant},
+			valid:  false,
+		},
+		"invalid allowance": {
+			params: types.DefaultParams(),
+			grants: []types.FeeAllowanceGrant{invalid},
+			valid:  false,
+		},
+		"invalid granter": {
+			params: types.DefaultParams(),
+			grants: []types.FeeAllowanceGrant{{Granter: "foo", Grantee: grantee.String(), Allowance: grant.Allowance}},
+			valid:  false,
+		},
+		"invalid params": {
+			params: types.NewParams(0, true, 0),
+			grants: nil,
+			valid:  false,
+		},
+		"granter at the limit": {
+			params: types.NewParams(2, true, 0),
+			grants: []types.FeeAllowanceGrant{grant, other, reverse},
+			valid:  true,
+		},
+		"granter over the limit": {
+			params: types.NewParams(1, true, 0),
+			grants: []types.FeeAllowanceGrant{grant, other, reverse},
+			valid:  false,
+		},
+		"valid spending": {
+			params:    types.DefaultParams(),
+			grants:    []types.FeeAllowanceGrant{grant},
+			spendings: []types.AllowanceSpendingRecord{spending},
+			valid:     true,
+		},
+		"spending without a grant": {
+			params:    types.DefaultParams(),
+			grants:    []types.FeeAllowanceGrant{reverse},
+			spendings: []types.AllowanceSpendingRecord{spending},
+			valid:     false,
+		},
+		"duplicate spending": {
+			params:    types.DefaultParams(),
+			grants:    []types.FeeAllowanceGrant{grant},
+			spendings: []types.AllowanceSpendingRecord{spending, spending},
+			valid:     false,
+		},
+		"invalid spent fees": {
+			params:    types.DefaultParams(),
+			grants:    []types.FeeAllowanceGrant{grant},
+			spendings: []types.AllowanceSpendingRecord{negative},
+			valid:     false,
+		},
+		"invalid original limit": {
+			params:    types.DefaultParams(),
+			grants:    []types.FeeAllowanceGrant{grant},
+			spendings: []types.AllowanceSpendingRecord{badLimit},
+			valid:     false,
+		},
+		"invalid spending grantee": {
+			params:    types.DefaultParams(),
+			grants:    []types.FeeAllowanceGrant{grant},
+			spendings: []types.AllowanceSpendingRecord{{Granter: granter.String(), Grantee: "foo"}},
+			valid:     false,
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			err := types.ValidateGenesis(*types.NewGenesisState(tc.params, tc.grants, tc.spendings))
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
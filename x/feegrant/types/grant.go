@@ -0,0 +1,57 @@
+package types
+
+import (
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+var (
+	_ types.UnpackInterfacesMessage = &FeeAllowanceGrant{}
+)
+
+// Grant is a fee allowance from a granter to a grantee, with the allowance
+// already unpacked.
+type Grant struct {
+	Granter   sdk.AccAddress
+	Grantee   sdk.AccAddress
+	Allowance FeeAllowanceI
+}
+
+// NewFeeAllowanceGrant creates a new FeeAllowanceGrant.
+//nolint:interfacer
+func NewFeeAllowanceGrant(granter, grantee sdk.AccAddress, feeAllowance FeeAllowanceI) (FeeAllowanceGrant, error) {
+	msg, ok := feeAllowance.(proto.Message)
+	if !ok {
+		return FeeAllowanceGrant{}, sdkerrors.Wrapf(sdkerrors.ErrPackAny, "cannot proto marshal %T", feeAllowance)
+	}
+
+	any, err := types.NewAnyWithValue(msg)
+	if err != nil {
+		return FeeAllowanceGrant{}, err
+	}
+
+	return FeeAllowanceGrant{
+		Granter:   granter.String(),
+		Grantee:   grantee.String(),
+		Allowance: any,
+	}, nil
+}
+
+// GetFeeGrant unpacks allowance
+func (a FeeAllowanceGrant) GetFeeGrant() (FeeAllowanceI, error) {
+	allowance, ok := a.Allowance.GetCachedValue().(FeeAllowanceI)
+	if !ok {
+		return nil, sdkerrors.Wrap(ErrNoAllowance, "failed to get allowance")
+	}
+
+	return allowance, nil
+}
+
+// UnpackInterfaces implements UnpackInterfacesMessage.UnpackInterfaces
+func (a FeeAllowanceGrant) UnpackInterfaces(unpacker types.AnyUnpacker) error {
+	var allowance FeeAllowanceI
+	return unpacker.UnpackAny(a.Allowance, &allowance)
+}
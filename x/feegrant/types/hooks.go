@@ -0,0 +1,44 @@
+package types
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// FeegrantHooks event hooks for fee grants, which other modules may implement
+// to react when allowances are granted, revoked, used or about to expire.
+type FeegrantHooks interface {
+	AfterGrant(ctx sdk.Context, granter, grantee sdk.AccAddress, allowance FeeAllowanceI)         // Must be called when an allowance is granted or updated
+	AfterRevoke(ctx sdk.Context, granter, grantee sdk.AccAddress)                                 // Must be called when a grant is revoked by its granter
+	AfterUseAllowance(ctx sdk.Context, granter, grantee sdk.AccAddress, fee sdk.Coins)            // Must be called when a grant pays a fee
+	BeforeAllowanceExpires(ctx sdk.Context, granter, grantee sdk.AccAddress, expiresAt time.Time) // Must be called once when a grant enters the expiry warning window
+}
+
+// combine multiple feegrant hooks, all hook functions are run in array sequence
+type MultiFeegrantHooks []FeegrantHooks
+
+func NewMultiFeegrantHooks(hooks ...FeegrantHooks) MultiFeegrantHooks {
+	return hooks
+}
+
+func (h MultiFeegrantHooks) AfterGrant(ctx sdk.Context, granter, grantee sdk.AccAddress, allowance FeeAllowanceI) {
+	for i := range h {
+		h[i].AfterGrant(ctx, granter, grantee, allowance)
+	}
+}
+func (h MultiFeegrantHooks) AfterRevoke(ctx sdk.Context, granter, grantee sdk.AccAddress) {
+	for i := range h {
+		h[i].AfterRevoke(ctx, granter, grantee)
+	}
+}
+func (h MultiFeegrantHooks) AfterUseAllowance(ctx sdk.Context, granter, grantee sdk.AccAddress, fee sdk.Coins) {
+	for i := range h {
+		h[i].AfterUseAllowance(ctx, granter, grantee, fee)
+	}
+}
+func (h MultiFeegrantHooks) BeforeAllowanceExpires(ctx sdk.Context, granter, grantee sdk.AccAddress, expiresAt time.Time) {
+	for i := range h {
+		h[i].BeforeAllowanceExpires(ctx, granter, grantee, expiresAt)
+	}
+}
@@ -0,0 +1,270 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	// ModuleName is the module name constant used in many places
+	ModuleName = "feegrant"
+
+	// StoreKey is the store key string for feegrant
+	StoreKey = ModuleName
+
+	// RouterKey is the message route for feegrant
+	RouterKey = ModuleName
+
+	// QuerierRoute is the querier route for feegrant
+	QuerierRoute = ModuleName
+
+	// MsgServiceName is the full name of the feegrant Msg service
+	MsgServiceName = "cosmos.feegrant.v1beta1.Msg"
+
+	// ConsensusVersion is the version of the store layout. The stores of the
+	// pre-release builds of the module are migrated to it by keeper.Migrator.
+	ConsensusVersion = 1
+)
+
+// full method names of the feegrant Msg service, which route its service Msgs
+const (
+	MsgGrantFeeAllowanceMethod         = "/" + MsgServiceName + "/GrantFeeAllowance"
+	MsgRevokeFeeAllowanceMethod        = "/" + MsgServiceName + "/RevokeFeeAllowance"
+	MsgUpdateAllowanceMethod           = "/" + MsgServiceName + "/UpdateAllowance"
+	MsgGrantAllowancesMethod           = "/" + MsgServiceName + "/GrantAllowances"
+	MsgRevokeAllowancesByGranterMethod = "/" + MsgServiceName + "/RevokeAllowancesByGranter"
+)
+
+// type URLs of the feegrant Msgs, as packed into an Any
+const (
+	MsgGrantFeeAllowanceTypeURL         = "/cosmos.feegrant.v1beta1.MsgGrantFeeAllowance"
+	MsgRevokeFeeAllowanceTypeURL        = "/cosmos.feegrant.v1beta1.MsgRevokeFeeAllowance"
+	MsgUpdateAllowanceTypeURL           = "/cosmos.feegrant.v1beta1.MsgUpdateAllowance"
+	MsgGrantAllowancesTypeURL           = "/cosmos.feegrant.v1beta1.MsgGrantAllowances"
+	MsgRevokeAllowancesByGranterTypeURL = "/cosmos.feegrant.v1beta1.MsgRevokeAllowancesByGranter"
+)
+
+// MsgRoute relates a feegrant Msg, identified by its type URL, to its legacy
+// type and to the Msg service method handling it.
+type MsgRoute struct {
+	TypeURL string
+	Type    string
+	Method  string
+}
+
+// MsgRoutes are the routes of all the feegrant Msgs, one per method of the Msg
+// service.
+var MsgRoutes = []MsgRoute{
+	{MsgGrantFeeAllowanceTypeURL, TypeMsgGrantFeeAllowance, MsgGrantFeeAllowanceMethod},
+	{MsgRevokeFeeAllowanceTypeURL, TypeMsgRevokeFeeAllowance, MsgRevokeFeeAllowanceMethod},
+	{MsgUpdateAllowanceTypeURL, TypeMsgUpdateAllowance, MsgUpdateAllowanceMethod},
+	{MsgGrantAllowancesTypeURL, TypeMsgGrantAllowances, MsgGrantAllowancesMethod},
+	{MsgRevokeAllowancesByGranterTypeURL, TypeMsgRevokeAllowancesByGranter, MsgRevokeAllowancesByGranterMethod},
+}
+
+// MsgRouteByTypeURL returns the route of the feegrant Msg with typeURL, if any.
+func MsgRouteByTypeURL(typeURL string) (MsgRoute, bool) {
+	for _, route := range MsgRoutes {
+		if route.TypeURL == typeURL {
+			return route, true
+		}
+	}
+
+	return MsgRoute{}, false
+}
+
+var (
+	// FeeAllowanceKeyPrefix is the set of the kvstore for fee allowance data
+	FeeAllowanceKeyPrefix = []byte{0x00}
+
+	// FeeAllowanceQueueKeyPrefix is the set of the kvstore for fee allowances
+	// indexed by expiration time
+	FeeAllowanceQueueKeyPrefix = []byte{0x01}
+
+	// FeeAllowanceByGranterKeyPrefix is the set of the kvstore for fee
+	// allowances indexed by granter
+	FeeAllowanceByGranterKeyPrefix = []byte{0x02}
+
+	// GrantsCountKey is the key of the number of grants in the store
+	GrantsCountKey = []byte{0x03}
+
+	// AllowanceSpendingKeyPrefix is the set of the kvstore for the fees spent
+	// out of each grant
+	AllowanceSpendingKeyPrefix = []byte{0x04}
+
+	// ExpiryWarningKeyPrefix is the set of the kvstore for the expiration queue
+	// entries whose grant was already warned about
+	ExpiryWarningKeyPrefix = []byte{0x05}
+
+	// FeeAllowanceHeightQueueKeyPrefix is the set of the kvstore for fee
+	// allowances indexed by expiration height
+	FeeAllowanceHeightQueueKeyPrefix = []byte{0x06}
+
+	// UnmigratedKeyPrefix is the set of the kvstore for the keys of a
+	// pre-release layout which could not be migrated, stored under their
+	// original key for operators to repair
+	UnmigratedKeyPrefix = []byte{0x07}
+)
+
+// The addresses in the keys of the store are prefixed with their length, see
+// LengthPrefix, so that a key made of several addresses can be split whatever
+// their length, and that the grants to an address are not mixed up with the
+// grants to a longer address starting with it.
+
+// LengthPrefix returns addr prefixed with its length, on one byte.
+func LengthPrefix(addr sdk.AccAddress) []byte {
+	if len(addr) > 255 {
+		panic(fmt.Sprintf("address length %d exceeds 255 bytes", len(addr)))
+	}
+
+	return append([]byte{byte(len(addr))}, addr...)
+}
+
+// ParseLengthPrefixedAddress extracts the address from bz, which must only hold
+// a length prefixed address, e.g. the rest of a key after the prefix returned by
+// FeeAllowancePrefixByGrantee or FeeAllowancePrefixByGranter.
+func ParseLengthPrefixedAddress(bz []byte) sdk.AccAddress {
+	addr, rest := splitLengthPrefixedAddress(bz)
+	if len(rest) != 0 {
+		panic("unexpected key length")
+	}
+
+	return addr
+}
+
+// splitLengthPrefixedAddress extracts the length prefixed address at the start
+// of bz, and returns it along with the rest of bz.
+func splitLengthPrefixedAddress(bz []byte) (sdk.AccAddress, []byte) {
+	if len(bz) == 0 || len(bz) < 1+int(bz[0]) {
+		panic("unexpected key length")
+	}
+
+	addrLen := 1 + int(bz[0])
+	return sdk.AccAddress(bz[1:addrLen]), bz[addrLen:]
+}
+
+// parseAddressPair extracts the two length prefixed addresses making up bz.
+func parseAddressPair(bz []byte) (first, second sdk.AccAddress) {
+	first, rest := splitLengthPrefixedAddress(bz)
+	return first, ParseLengthPrefixedAddress(rest)
+}
+
+// FeeAllowanceKey is the canonical key to store a grant from granter to grantee
+// We store by grantee first to allow searching by everyone who granted to you
+func FeeAllowanceKey(granter sdk.AccAddress, grantee sdk.AccAddress) []byte {
+	return append(FeeAllowancePrefixByGrantee(grantee), LengthPrefix(granter)...)
+}
+
+// FeeAllowancePrefixByGrantee returns a prefix to scan for all grants to this given address.
+func FeeAllowancePrefixByGrantee(grantee sdk.AccAddress) []byte {
+	return append(FeeAllowanceKeyPrefix, LengthPrefix(grantee)...)
+}
+
+// ParseAddressesFromFeeAllowanceKey extracts the granter and grantee addresses
+// from a key created by FeeAllowanceKey.
+func ParseAddressesFromFeeAllowanceKey(key []byte) (granter, grantee sdk.AccAddress) {
+	grantee, granter = parseAddressPair(key[len(FeeAllowanceKeyPrefix):])
+	return granter, grantee
+}
+
+// FeeAllowanceByQueueKey returns a key prefix for all fee allowances expiring at exp.
+func FeeAllowanceByQueueKey(exp time.Time) []byte {
+	return append(FeeAllowanceQueueKeyPrefix, sdk.FormatTimeBytes(exp)...)
+}
+
+// FeeAllowanceQueueKey is the key in the expiration queue for a grant from
+// granter to grantee expiring at exp.
+func FeeAllowanceQueueKey(exp time.Time, granter sdk.AccAddress, grantee sdk.AccAddress) []byte {
+	key := FeeAllowanceByQueueKey(exp)
+	key = append(key, LengthPrefix(grantee)...)
+	return append(key, LengthPrefix(granter)...)
+}
+
+// ParseAddressesFromFeeAllowanceQueueKey extracts the granter and grantee
+// addresses from a key created by FeeAllowanceQueueKey.
+func ParseAddressesFromFeeAllowanceQueueKey(key []byte) (granter, grantee sdk.AccAddress) {
+	timeLen := len(sdk.FormatTimeBytes(time.Time{}))
+	grantee, granter = parseAddressPair(key[len(FeeAllowanceQueueKeyPrefix)+timeLen:])
+	return granter, grantee
+}
+
+// ParseExpirationFromFeeAllowanceQueueKey extracts the expiration time from a
+// key created by FeeAllowanceQueueKey.
+func ParseExpirationFromFeeAllowanceQueueKey(key []byte) time.Time {
+	timeLen := len(sdk.FormatTimeBytes(time.Time{}))
+	start := len(FeeAllowanceQueueKeyPrefix)
+	exp, err := sdk.ParseTimeBytes(key[start : start+timeLen])
+	if err != nil {
+		panic(err)
+	}
+
+	return exp
+}
+
+// FeeAllowanceByGranterKey is the key in the granter index for a grant from
+// granter to grantee.
+func FeeAllowanceByGranterKey(granter, grantee sdk.AccAddress) []byte {
+	return append(FeeAllowancePrefixByGranter(granter), LengthPrefix(grantee)...)
+}
+
+// ParseAddressesFromFeeAllowanceByGranterKey extracts the granter and grantee
+// addresses from a key created by FeeAllowanceByGranterKey.
+func ParseAddressesFromFeeAllowanceByGranterKey(key []byte) (granter, grantee sdk.AccAddress) {
+	return parseAddressPair(key[len(FeeAllowanceByGranterKeyPrefix):])
+}
+
+// FeeAllowancePrefixByGranter returns a prefix to scan the granter index for
+// all grants from this given address.
+func FeeAllowancePrefixByGranter(granter sdk.AccAddress) []byte {
+	return append(FeeAllowanceByGranterKeyPrefix, LengthPrefix(granter)...)
+}
+
+// AllowanceSpendingKey is the key of the spending record of the grant from
+// granter to grantee.
+func AllowanceSpendingKey(granter, grantee sdk.AccAddress) []byte {
+	key := append(AllowanceSpendingKeyPrefix, LengthPrefix(grantee)...)
+	return append(key, LengthPrefix(granter)...)
+}
+
+// ParseAddressesFromAllowanceSpendingKey extracts the granter and grantee
+// addresses from a key created by AllowanceSpendingKey.
+func ParseAddressesFromAllowanceSpendingKey(key []byte) (granter, grantee sdk.AccAddress) {
+	grantee, granter = parseAddressPair(key[len(AllowanceSpendingKeyPrefix):])
+	return granter, grantee
+}
+
+// ExpiryWarningKey is the key recording that the grant with the expiration
+// queue entry queueKey was warned about, see FeeAllowanceQueueKey.
+func ExpiryWarningKey(queueKey []byte) []byte {
+	return append(ExpiryWarningKeyPrefix, queueKey[len(FeeAllowanceQueueKeyPrefix):]...)
+}
+
+// FeeAllowanceByHeightQueueKey returns a key prefix for all fee allowances
+// expiring after height.
+func FeeAllowanceByHeightQueueKey(height int64) []byte {
+	return append(FeeAllowanceHeightQueueKeyPrefix, sdk.Uint64ToBigEndian(uint64(height))...)
+}
+
+// FeeAllowanceHeightQueueKey is the key in the expiration height queue for a
+// grant from granter to grantee expiring after height.
+func FeeAllowanceHeightQueueKey(height int64, granter sdk.AccAddress, grantee sdk.AccAddress) []byte {
+	key := FeeAllowanceByHeightQueueKey(height)
+	key = append(key, LengthPrefix(grantee)...)
+	return append(key, LengthPrefix(granter)...)
+}
+
+// ParseAddressesFromFeeAllowanceHeightQueueKey extracts the granter and
+// grantee addresses from a key created by FeeAllowanceHeightQueueKey.
+func ParseAddressesFromFeeAllowanceHeightQueueKey(key []byte) (granter, grantee sdk.AccAddress) {
+	grantee, granter = parseAddressPair(key[len(FeeAllowanceHeightQueueKeyPrefix)+8:])
+	return granter, grantee
+}
+
+// ParseExpirationHeightFromFeeAllowanceHeightQueueKey extracts the expiration
+// height from a key created by FeeAllowanceHeightQueueKey.
+func ParseExpirationHeightFromFeeAllowanceHeightQueueKey(key []byte) int64 {
+	start := len(FeeAllowanceHeightQueueKeyPrefix)
+	return int64(sdk.BigEndianToUint64(key[start : start+8]))
+}
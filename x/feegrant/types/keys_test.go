@@ -0,0 +1,106 @@
+package types_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+// serviceDescRecorder is a gogogrpc.Server recording the service descs
+// registered on it.
+type serviceDescRecorder struct {
+	descs []*grpc.ServiceDesc
+}
+
+func (r *serviceDescRecorder) RegisterService(sd *grpc.ServiceDesc, _ interface{}) {
+	r.descs = append(r.descs, sd)
+}
+
+func TestMsgRoutes(t *testing.T) {
+	// the generated service desc cannot refer to the constants, so check it
+	// routes each Msg to the method of MsgRoutes
+	recorder := &serviceDescRecorder{}
+	types.RegisterMsgServer(recorder, nil)
+	require.Len(t, recorder.descs, 1)
+
+	sd := recorder.descs[0]
+	require.Equal(t, types.MsgServiceName, sd.ServiceName)
+	require.Len(t, sd.Methods, len(types.MsgRoutes))
+
+	errStop := errors.New("stop")
+	for _, method := range sd.Methods {
+		var typeURL string
+		_, err := method.Handler(nil, context.Background(), func(req interface{}) error {
+			typeURL = "/" + proto.MessageName(req.(proto.Message))
+			return errStop
+		}, nil)
+		require.True(t, errors.Is(err, errStop))
+
+		route, ok := types.MsgRouteByTypeURL(typeURL)
+		require.True(t, ok, "no route for %s", typeURL)
+		require.Equal(t, "/"+sd.ServiceName+"/"+method.MethodName, route.Method)
+	}
+
+	msgs := []sdk.Msg{
+		&types.MsgGrantFeeAllowance{},
+		&types.MsgRevokeFeeAllowance{},
+		&types.MsgUpdateAllowance{},
+		&types.MsgGrantAllowances{},
+		&types.MsgRevokeAllowancesByGranter{},
+	}
+	require.Len(t, types.MsgRoutes, len(msgs))
+
+	for _, msg := range msgs {
+		route, ok := types.MsgRouteByTypeURL("/" + proto.MessageName(msg))
+		require.True(t, ok, "no route for %T", msg)
+		require.Equal(t, msg.Type(), route.Type)
+		require.Equal(t, types.RouterKey, msg.Route())
+	}
+
+	_, ok := types.MsgRouteByTypeURL("/cosmos.feegrant.v1beta1.MsgUnknown")
+	require.False(t, ok)
+}
+
+func TestParseAddressesFromKeys(t *testing.T) {
+	short := sdk.AccAddress(bytes.Repeat([]byte{1}, 20))
+	long := sdk.AccAddress(bytes.Repeat([]byte{1}, 32))
+	exp := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, addrs := range [][2]sdk.AccAddress{{short, long}, {long, short}, {long, long}} {
+		granter, grantee := addrs[0], addrs[1]
+
+		parsedGranter, parsedGrantee := types.ParseAddressesFromFeeAllowanceKey(types.FeeAllowanceKey(granter, grantee))
+		require.Equal(t, granter, parsedGranter)
+		require.Equal(t, grantee, parsedGrantee)
+
+		parsedGranter, parsedGrantee = types.ParseAddressesFromFeeAllowanceByGranterKey(types.FeeAllowanceByGranterKey(granter, grantee))
+		require.Equal(t, granter, parsedGranter)
+		require.Equal(t, grantee, parsedGrantee)
+
+		parsedGranter, parsedGrantee = types.ParseAddressesFromFeeAllowanceQueueKey(types.FeeAllowanceQueueKey(exp, granter, grantee))
+		require.Equal(t, granter, parsedGranter)
+		require.Equal(t, grantee, parsedGrantee)
+
+		parsedGranter, parsedGrantee = types.ParseAddressesFromFeeAllowanceHeightQueueKey(types.FeeAllowanceHeightQueueKey(42, granter, grantee))
+		require.Equal(t, granter, parsedGranter)
+		require.Equal(t, grantee, parsedGrantee)
+
+		parsedGranter, parsedGrantee = types.ParseAddressesFromAllowanceSpendingKey(types.AllowanceSpendingKey(granter, grantee))
+		require.Equal(t, granter, parsedGranter)
+		require.Equal(t, grantee, parsedGrantee)
+	}
+
+	// the grants to an address are not under the prefix of a shorter address
+	// it starts with
+	require.False(t, bytes.HasPrefix(types.FeeAllowanceKey(short, long), types.FeeAllowancePrefixByGrantee(short)))
+	require.False(t, bytes.HasPrefix(types.FeeAllowanceByGranterKey(long, short), types.FeeAllowancePrefixByGranter(short)))
+}
@@ -0,0 +1,46 @@
+package types
+
+// sizedMarshaler is implemented by the generated protobuf messages.
+type sizedMarshaler interface {
+	Size() int
+	MarshalToSizedBuffer(dAtA []byte) (int, error)
+}
+
+// MarshalAppend appends the protobuf encoding of the message to dst and returns
+// the extended slice. Unlike Marshal, it does not allocate when dst has enough
+// spare capacity, so callers encoding many messages can reuse a single buffer.
+func (m *MsgGrantFeeAllowance) MarshalAppend(dst []byte) ([]byte, error) {
+	return marshalAppend(m, dst)
+}
+
+// MarshalAppend appends the protobuf encoding of the message to dst and returns
+// the extended slice, see MsgGrantFeeAllowance.MarshalAppend.
+func (m *MsgRevokeFeeAllowance) MarshalAppend(dst []byte) ([]byte, error) {
+	return marshalAppend(m, dst)
+}
+
+// MarshalAppend appends the protobuf encoding of the message to dst and returns
+// the extended slice, see MsgGrantFeeAllowance.MarshalAppend.
+func (m *MsgUpdateAllowance) MarshalAppend(dst []byte) ([]byte, error) {
+	return marshalAppend(m, dst)
+}
+
+func marshalAppend(m sizedMarshaler, dst []byte) ([]byte, error) {
+	size := m.Size()
+	start := len(dst)
+
+	if cap(dst)-start < size {
+		grown := make([]byte, start, start+size)
+		copy(grown, dst)
+		dst = grown
+	}
+	dst = dst[:start+size]
+
+	// the generated code encodes backwards from the end of the buffer
+	n, err := m.MarshalToSizedBuffer(dst[start:])
+	if err != nil {
+		return nil, err
+	}
+
+	return dst[:start+n], nil
+}
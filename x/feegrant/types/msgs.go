@@ -0,0 +1,373 @@
+package types
+
+import (
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+// feegrant message types
+const (
+	TypeMsgGrantFeeAllowance  = "grant_fee_allowance"
+	TypeMsgRevokeFeeAllowance = "revoke_fee_allowance"
+	TypeMsgUpdateAllowance    = "update_allowance"
+	TypeMsgGrantAllowances    = "grant_allowances"
+
+	TypeMsgRevokeAllowancesByGranter = "revoke_allowances_by_granter"
+)
+
+// MaxAllowanceBytes is the maximum size of the encoded allowance carried by a
+// MsgGrantFeeAllowance or MsgUpdateAllowance.
+//
+// Note that Unmarshal never allocates more than the size of its input: a
+// declared length running past the end of the buffer fails with
+// io.ErrUnexpectedEOF. This bound limits what a valid message can store.
+const MaxAllowanceBytes = 16 * 1024
+
+// MaxGrantees is the maximum number of grantees of a MsgGrantAllowances.
+const MaxGrantees = 100
+
+var (
+	_, _, _, _, _ sdk.Msg = &MsgGrantFeeAllowance{}, &MsgRevokeFeeAllowance{}, &MsgUpdateAllowance{}, &MsgGrantAllowances{},
+		&MsgRevokeAllowancesByGranter{}
+	_, _, _ types.UnpackInterfacesMessage = &MsgGrantFeeAllowance{}, &MsgUpdateAllowance{}, &MsgGrantAllowances{}
+)
+
+// NewMsgGrantFeeAllowance creates a new MsgGrantFeeAllowance.
+//nolint:interfacer
+func NewMsgGrantFeeAllowance(feeAllowance FeeAllowanceI, granter, grantee sdk.AccAddress) (*MsgGrantFeeAllowance, error) {
+	msg, ok := feeAllowance.(proto.Message)
+	if !ok {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrPackAny, "cannot proto marshal %T", feeAllowance)
+	}
+	any, err := types.NewAnyWithValue(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MsgGrantFeeAllowance{
+		Granter:   granter.String(),
+		Grantee:   grantee.String(),
+		Allowance: any,
+	}, nil
+}
+
+// Route implements the sdk.Msg interface.
+func (msg MsgGrantFeeAllowance) Route() string {
+	return RouterKey
+}
+
+// Type implements the sdk.Msg interface.
+func (msg MsgGrantFeeAllowance) Type() string {
+	return TypeMsgGrantFeeAllowance
+}
+
+// ValidateBasic implements the sdk.Msg interface.
+func (msg MsgGrantFeeAllowance) ValidateBasic() error {
+	if _, err := sdk.AccAddressFromBech32(msg.Granter); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid granter address (%s)", err)
+	}
+	if _, err := sdk.AccAddressFromBech32(msg.Grantee); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid grantee address (%s)", err)
+	}
+	if msg.Grantee == msg.Granter {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, "cannot self-grant fee authorization")
+	}
+	if msg.Allowance == nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "missing fee allowance")
+	}
+
+	return validateAllowanceSize(msg.Allowance)
+}
+
+// GetSignBytes implements the sdk.Msg interface.
+func (msg MsgGrantFeeAllowance) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(&msg))
+}
+
+// GetSigners gets the granter account associated with an allowance
+func (msg MsgGrantFeeAllowance) GetSigners() []sdk.AccAddress {
+	granter, err := sdk.AccAddressFromBech32(msg.Granter)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{granter}
+}
+
+// GetFeeAllowanceI returns the unpacked allowance being granted.
+func (msg MsgGrantFeeAllowance) GetFeeAllowanceI() (FeeAllowanceI, error) {
+	allowance, ok := msg.Allowance.GetCachedValue().(FeeAllowanceI)
+	if !ok {
+		return nil, sdkerrors.Wrap(ErrNoAllowance, "failed to get allowance")
+	}
+
+	return allowance, nil
+}
+
+// UnpackInterfaces implements UnpackInterfacesMessage.UnpackInterfaces
+func (msg MsgGrantFeeAllowance) UnpackInterfaces(unpacker types.AnyUnpacker) error {
+	var allowance FeeAllowanceI
+	return unpacker.UnpackAny(msg.Allowance, &allowance)
+}
+
+// NewMsgRevokeFeeAllowance returns a message to revoke a fee allowance for a given
+// granter and grantee
+//nolint:interfacer
+func NewMsgRevokeFeeAllowance(granter sdk.AccAddress, grantee sdk.AccAddress) MsgRevokeFeeAllowance {
+	return MsgRevokeFeeAllowance{Granter: granter.String(), Grantee: grantee.String()}
+}
+
+// Route implements the sdk.Msg interface.
+func (msg MsgRevokeFeeAllowance) Route() string {
+	return RouterKey
+}
+
+// Type implements the sdk.Msg interface.
+func (msg MsgRevokeFeeAllowance) Type() string {
+	return TypeMsgRevokeFeeAllowance
+}
+
+// ValidateBasic implements the sdk.Msg interface.
+func (msg MsgRevokeFeeAllowance) ValidateBasic() error {
+	if _, err := sdk.AccAddressFromBech32(msg.Granter); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid granter address (%s)", err)
+	}
+	if _, err := sdk.AccAddressFromBech32(msg.Grantee); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid grantee address (%s)", err)
+	}
+	if msg.Grantee == msg.Granter {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, "addresses must be different")
+	}
+
+	return nil
+}
+
+// GetSignBytes implements the sdk.Msg interface.
+func (msg MsgRevokeFeeAllowance) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(&msg))
+}
+
+// GetSigners gets the granter address associated with an Allowance
+// to revoke.
+func (msg MsgRevokeFeeAllowance) GetSigners() []sdk.AccAddress {
+	granter, err := sdk.AccAddressFromBech32(msg.Granter)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{granter}
+}
+
+// NewMsgUpdateAllowance creates a new MsgUpdateAllowance.
+//nolint:interfacer
+func NewMsgUpdateAllowance(feeAllowance FeeAllowanceI, granter, grantee sdk.AccAddress) (*MsgUpdateAllowance, error) {
+	msg, ok := feeAllowance.(proto.Message)
+	if !ok {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrPackAny, "cannot proto marshal %T", feeAllowance)
+	}
+	any, err := types.NewAnyWithValue(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MsgUpdateAllowance{
+		Granter:   granter.String(),
+		Grantee:   grantee.String(),
+		Allowance: any,
+	}, nil
+}
+
+// Route implements the sdk.Msg interface.
+func (msg MsgUpdateAllowance) Route() string {
+	return RouterKey
+}
+
+// Type implements the sdk.Msg interface.
+func (msg MsgUpdateAllowance) Type() string {
+	return TypeMsgUpdateAllowance
+}
+
+// ValidateBasic implements the sdk.Msg interface.
+func (msg MsgUpdateAllowance) ValidateBasic() error {
+	if _, err := sdk.AccAddressFromBech32(msg.Granter); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid granter address (%s)", err)
+	}
+	if _, err := sdk.AccAddressFromBech32(msg.Grantee); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid grantee address (%s)", err)
+	}
+	if msg.Grantee == msg.Granter {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, "addresses must be different")
+	}
+	if msg.Allowance == nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "missing fee allowance")
+	}
+
+	return validateAllowanceSize(msg.Allowance)
+}
+
+// GetSignBytes implements the sdk.Msg interface.
+func (msg MsgUpdateAllowance) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(&msg))
+}
+
+// GetSigners gets the granter account associated with the updated allowance
+func (msg MsgUpdateAllowance) GetSigners() []sdk.AccAddress {
+	granter, err := sdk.AccAddressFromBech32(msg.Granter)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{granter}
+}
+
+// UnpackInterfaces implements UnpackInterfacesMessage.UnpackInterfaces
+func (msg MsgUpdateAllowance) UnpackInterfaces(unpacker types.AnyUnpacker) error {
+	var allowance FeeAllowanceI
+	return unpacker.UnpackAny(msg.Allowance, &allowance)
+}
+
+// NewMsgGrantAllowances creates a new MsgGrantAllowances.
+//nolint:interfacer
+func NewMsgGrantAllowances(feeAllowance FeeAllowanceI, granter sdk.AccAddress, grantees []sdk.AccAddress) (*MsgGrantAllowances, error) {
+	msg, ok := feeAllowance.(proto.Message)
+	if !ok {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrPackAny, "cannot proto marshal %T", feeAllowance)
+	}
+	any, err := types.NewAnyWithValue(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	granteeStrs := make([]string, len(grantees))
+	for i, grantee := range grantees {
+		granteeStrs[i] = grantee.String()
+	}
+
+	return &MsgGrantAllowances{
+		Granter:   granter.String(),
+		Grantees:  granteeStrs,
+		Allowance: any,
+	}, nil
+}
+
+// Route implements the sdk.Msg interface.
+func (msg MsgGrantAllowances) Route() string {
+	return RouterKey
+}
+
+// Type implements the sdk.Msg interface.
+func (msg MsgGrantAllowances) Type() string {
+	return TypeMsgGrantAllowances
+}
+
+// ValidateBasic implements the sdk.Msg interface.
+func (msg MsgGrantAllowances) ValidateBasic() error {
+	if _, err := sdk.AccAddressFromBech32(msg.Granter); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid granter address (%s)", err)
+	}
+	if len(msg.Grantees) == 0 {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "missing grantees")
+	}
+	if len(msg.Grantees) > MaxGrantees {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "%d grantees, maximum is %d", len(msg.Grantees), MaxGrantees)
+	}
+
+	seen := make(map[string]bool, len(msg.Grantees))
+	for _, grantee := range msg.Grantees {
+		if _, err := sdk.AccAddressFromBech32(grantee); err != nil {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid grantee address (%s)", err)
+		}
+		if grantee == msg.Granter {
+			return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, "cannot self-grant fee authorization")
+		}
+		if seen[grantee] {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "duplicate grantee %s", grantee)
+		}
+		seen[grantee] = true
+	}
+
+	if msg.Allowance == nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "missing fee allowance")
+	}
+
+	return validateAllowanceSize(msg.Allowance)
+}
+
+// GetSignBytes implements the sdk.Msg interface.
+func (msg MsgGrantAllowances) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(&msg))
+}
+
+// GetSigners gets the granter account associated with the allowances
+func (msg MsgGrantAllowances) GetSigners() []sdk.AccAddress {
+	granter, err := sdk.AccAddressFromBech32(msg.Granter)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{granter}
+}
+
+// GetFeeAllowanceI returns the unpacked allowance being granted.
+func (msg MsgGrantAllowances) GetFeeAllowanceI() (FeeAllowanceI, error) {
+	allowance, ok := msg.Allowance.GetCachedValue().(FeeAllowanceI)
+	if !ok {
+		return nil, sdkerrors.Wrap(ErrNoAllowance, "failed to get allowance")
+	}
+
+	return allowance, nil
+}
+
+// UnpackInterfaces implements UnpackInterfacesMessage.UnpackInterfaces
+func (msg MsgGrantAllowances) UnpackInterfaces(unpacker types.AnyUnpacker) error {
+	var allowance FeeAllowanceI
+	return unpacker.UnpackAny(msg.Allowance, &allowance)
+}
+
+// NewMsgRevokeAllowancesByGranter returns a message to revoke all fee
+// allowances of a given granter.
+//nolint:interfacer
+func NewMsgRevokeAllowancesByGranter(granter sdk.AccAddress) MsgRevokeAllowancesByGranter {
+	return MsgRevokeAllowancesByGranter{Granter: granter.String()}
+}
+
+// Route implements the sdk.Msg interface.
+func (msg MsgRevokeAllowancesByGranter) Route() string {
+	return RouterKey
+}
+
+// Type implements the sdk.Msg interface.
+func (msg MsgRevokeAllowancesByGranter) Type() string {
+	return TypeMsgRevokeAllowancesByGranter
+}
+
+// ValidateBasic implements the sdk.Msg interface.
+func (msg MsgRevokeAllowancesByGranter) ValidateBasic() error {
+	if _, err := sdk.AccAddressFromBech32(msg.Granter); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid granter address (%s)", err)
+	}
+
+	return nil
+}
+
+// GetSignBytes implements the sdk.Msg interface.
+func (msg MsgRevokeAllowancesByGranter) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(&msg))
+}
+
+// GetSigners gets the granter address whose allowances are revoked.
+func (msg MsgRevokeAllowancesByGranter) GetSigners() []sdk.AccAddress {
+	granter, err := sdk.AccAddressFromBech32(msg.Granter)
+	if err != nil {
+		panic(err)
+	}
+	return []sdk.AccAddress{granter}
+}
+
+// validateAllowanceSize checks the encoded allowance against MaxAllowanceBytes.
+func validateAllowanceSize(allowance *types.Any) error {
+	if size := allowance.Size(); size > MaxAllowanceBytes {
+		return sdkerrors.Wrapf(ErrAllowanceTooLarge, "%d bytes, maximum is %d", size, MaxAllowanceBytes)
+	}
+
+	return nil
+}
@@ -0,0 +1,413 @@
+package types_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+func TestMsgGrantFeeAllowance(t *testing.T) {
+	granter := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	grantee := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	allowance := &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 555))}
+
+	cases := map[string]struct {
+		granter   sdk.AccAddress
+		grantee   sdk.AccAddress
+		allowance types.FeeAllowanceI
+		valid     bool
+	}{
+		"valid": {
+			granter:   granter,
+			grantee:   grantee,
+			allowance: allowance,
+			valid:     true,
+		},
+		"no granter": {
+			grantee:   grantee,
+			allowance: allowance,
+		},
+		"no grantee": {
+			granter:   granter,
+			allowance: allowance,
+		},
+		"self grant": {
+			granter:   granter,
+			grantee:   granter,
+			allowance: allowance,
+		},
+		"no allowance": {
+			granter: granter,
+			grantee: grantee,
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			var msg *types.MsgGrantFeeAllowance
+			if tc.allowance != nil {
+				var err error
+				msg, err = types.NewMsgGrantFeeAllowance(tc.allowance, tc.granter, tc.grantee)
+				require.NoError(t, err)
+			} else {
+				msg = &types.MsgGrantFeeAllowance{Granter: tc.granter.String(), Grantee: tc.grantee.String()}
+			}
+
+			err := msg.ValidateBasic()
+			if tc.valid {
+				require.NoError(t, err)
+				require.Equal(t, []sdk.AccAddress{tc.granter}, msg.GetSigners())
+				require.NotPanics(t, func() { msg.GetSignBytes() })
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestMsgRevokeFeeAllowance(t *testing.T) {
+	granter := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	grantee := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+
+	cases := map[string]struct {
+		granter sdk.AccAddress
+		grantee sdk.AccAddress
+		valid   bool
+	}{
+		"valid": {
+			granter: granter,
+			grantee: grantee,
+			valid:   true,
+		},
+		"no granter": {
+			grantee: grantee,
+		},
+		"no grantee": {
+			granter: granter,
+		},
+		"same address": {
+			granter: granter,
+			grantee: granter,
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			msg := types.NewMsgRevokeFeeAllowance(tc.granter, tc.grantee)
+
+			err := msg.ValidateBasic()
+			if tc.valid {
+				require.NoError(t, err)
+				require.Equal(t, []sdk.AccAddress{tc.granter}, msg.GetSigners())
+				require.NotPanics(t, func() { msg.GetSignBytes() })
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestMsgGrantFeeAllowanceAminoJSON(t *testing.T) {
+	cdc := codec.NewLegacyAmino()
+	types.RegisterLegacyAminoCodec(cdc)
+
+	granter := sdk.AccAddress("granter_____________")
+	grantee := sdk.AccAddress("grantee_____________")
+	allowance := &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 555))}
+
+	msg, err := types.NewMsgGrantFeeAllowance(allowance, granter, grantee)
+	require.NoError(t, err)
+
+	bz, err := cdc.MarshalJSON(msg)
+	require.NoError(t, err)
+
+	var decoded types.MsgGrantFeeAllowance
+	require.NoError(t, cdc.UnmarshalJSON(bz, &decoded))
+	require.Equal(t, msg.Granter, decoded.Granter)
+	require.Equal(t, msg.Grantee, decoded.Grantee)
+	require.Equal(t, allowance, decoded.Allowance.GetCachedValue())
+	require.Equal(t, msg.Allowance.Value, decoded.Allowance.Value)
+
+	expSignBytes := `{"type":"cosmos-sdk/MsgGrantFeeAllowance","value":{"allowance":{"type":"cosmos-sdk/BasicAllowance","value":{"spend_limit":[{"amount":"555","denom":"atom"}]}},"grantee":"cosmos1vaexzmn5v4j47h6lta047h6lta047h6lwfkh0k","granter":"cosmos1vaexzmn5v4e97h6lta047h6lta047h6l3kck0u"}}`
+	require.Equal(t, expSignBytes, string(msg.GetSignBytes()))
+	require.Equal(t, expSignBytes, string(decoded.GetSignBytes()))
+}
+
+func TestMsgGrantFeeAllowanceUnpackInterfaces(t *testing.T) {
+	registry := codectypes.NewInterfaceRegistry()
+	types.RegisterInterfaces(registry)
+	cdc := codec.NewProtoCodec(registry)
+
+	granter := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	grantee := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	allowance := &types.PeriodicFeeAllowance{
+		Basic:            types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 555))},
+		Period:           time.Hour,
+		PeriodSpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 55)),
+		PeriodCanSpend:   sdk.NewCoins(sdk.NewInt64Coin("atom", 55)),
+	}
+
+	msg, err := types.NewMsgGrantFeeAllowance(allowance, granter, grantee)
+	require.NoError(t, err)
+
+	bz, err := cdc.MarshalBinaryBare(msg)
+	require.NoError(t, err)
+
+	var decoded types.MsgGrantFeeAllowance
+	require.NoError(t, cdc.UnmarshalBinaryBare(bz, &decoded))
+
+	feeAllowance, err := decoded.GetFeeAllowanceI()
+	require.NoError(t, err)
+	require.IsType(t, &types.PeriodicFeeAllowance{}, feeAllowance)
+	require.Equal(t, allowance.Basic, feeAllowance.(*types.PeriodicFeeAllowance).Basic)
+	require.Equal(t, allowance.PeriodSpendLimit, feeAllowance.(*types.PeriodicFeeAllowance).PeriodSpendLimit)
+	require.NoError(t, feeAllowance.ValidateBasic())
+
+	// without unpacking, the allowance is not available
+	var raw types.MsgGrantFeeAllowance
+	require.NoError(t, raw.Unmarshal(bz))
+	_, err = raw.GetFeeAllowanceI()
+	require.Error(t, err)
+}
+
+func TestMsgGrantFeeAllowanceSizeLimit(t *testing.T) {
+	granter := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	grantee := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+
+	// field 3 (allowance) claiming a length of 1 TiB
+	bz := []byte{0x1a, 0x80, 0x80, 0x80, 0x80, 0x80, 0x20}
+	var msg types.MsgGrantFeeAllowance
+	require.Equal(t, io.ErrUnexpectedEOF, msg.Unmarshal(bz))
+	require.Nil(t, msg.Allowance)
+
+	msgs := make([]string, types.MaxAllowanceBytes/32)
+	for i := range msgs {
+		msgs[i] = fmt.Sprintf("/cosmos.bank.v1beta1.MsgSend%04d", i)
+	}
+	allowance, err := types.NewAllowedMsgAllowance(&types.BasicAllowance{}, msgs)
+	require.NoError(t, err)
+
+	grant, err := types.NewMsgGrantFeeAllowance(allowance, granter, grantee)
+	require.NoError(t, err)
+	require.True(t, errors.Is(grant.ValidateBasic(), types.ErrAllowanceTooLarge))
+
+	update, err := types.NewMsgUpdateAllowance(allowance, granter, grantee)
+	require.NoError(t, err)
+	require.True(t, errors.Is(update.ValidateBasic(), types.ErrAllowanceTooLarge))
+
+	allowance.AllowedMessages = msgs[:10]
+	grant, err = types.NewMsgGrantFeeAllowance(allowance, granter, grantee)
+	require.NoError(t, err)
+	require.NoError(t, grant.ValidateBasic())
+}
+
+func TestMsgGrantFeeAllowanceNestedGroups(t *testing.T) {
+	// start and end group tags of the unknown field 99
+	start, end := []byte{0x9b, 0x06}, []byte{0x9c, 0x06}
+
+	// a million nested groups: skipTx only keeps a depth counter, so they are
+	// skipped in a single linear pass, without any recursion
+	const depth = 1 << 20
+	open := bytes.Repeat(start, depth)
+
+	var msg types.MsgGrantFeeAllowance
+	require.Equal(t, io.ErrUnexpectedEOF, msg.Unmarshal(open))
+
+	balanced := append(open, bytes.Repeat(end, depth)...)
+	msg = types.MsgGrantFeeAllowance{}
+	require.NoError(t, msg.Unmarshal(balanced))
+	require.Empty(t, msg.Granter)
+	require.Nil(t, msg.Allowance)
+
+	// an end group without a matching start is rejected
+	msg = types.MsgGrantFeeAllowance{}
+	require.Error(t, msg.Unmarshal(append(balanced, end...)))
+}
+
+func TestMsgGrantAllowances(t *testing.T) {
+	granter := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	allowance := &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 555))}
+
+	grantees := make([]sdk.AccAddress, types.MaxGrantees+1)
+	for i := range grantees {
+		grantees[i] = sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	}
+
+	cases := map[string]struct {
+		granter  sdk.AccAddress
+		grantees []sdk.AccAddress
+		valid    bool
+	}{
+		"valid": {
+			granter:  granter,
+			grantees: grantees[:3],
+			valid:    true,
+		},
+		"max grantees": {
+			granter:  granter,
+			grantees: grantees[:types.MaxGrantees],
+			valid:    true,
+		},
+		"too many grantees": {
+			granter:  granter,
+			grantees: grantees,
+		},
+		"no grantees": {
+			granter: granter,
+		},
+		"no granter": {
+			grantees: grantees[:3],
+		},
+		"self grant": {
+			granter:  granter,
+			grantees: []sdk.AccAddress{grantees[0], granter},
+		},
+		"duplicate grantee": {
+			granter:  granter,
+			grantees: []sdk.AccAddress{grantees[0], grantees[1], grantees[0]},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			msg, err := types.NewMsgGrantAllowances(allowance, tc.granter, tc.grantees)
+			require.NoError(t, err)
+
+			err = msg.ValidateBasic()
+			if tc.valid {
+				require.NoError(t, err)
+				require.Equal(t, []sdk.AccAddress{tc.granter}, msg.GetSigners())
+				require.NotPanics(t, func() { msg.GetSignBytes() })
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestMsgRevokeAllowancesByGranter(t *testing.T) {
+	granter := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+
+	msg := types.NewMsgRevokeAllowancesByGranter(granter)
+	require.NoError(t, msg.ValidateBasic())
+	require.Equal(t, []sdk.AccAddress{granter}, msg.GetSigners())
+	require.NotPanics(t, func() { msg.GetSignBytes() })
+
+	msg = types.NewMsgRevokeAllowancesByGranter(nil)
+	require.Error(t, msg.ValidateBasic())
+}
+
+func TestMsgLegacySigning(t *testing.T) {
+	granter := sdk.AccAddress("granter_____________")
+	grantee := sdk.AccAddress("grantee_____________")
+	allowance := &types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 555))}
+
+	grant, err := types.NewMsgGrantFeeAllowance(allowance, granter, grantee)
+	require.NoError(t, err)
+	revoke := types.NewMsgRevokeFeeAllowance(granter, grantee)
+
+	cases := map[string]struct {
+		msg     sdk.Msg
+		decoded sdk.Msg
+		msgType string
+		expSign string
+	}{
+		"grant": {
+			msg:     grant,
+			decoded: &types.MsgGrantFeeAllowance{},
+			msgType: types.TypeMsgGrantFeeAllowance,
+			expSign: `{"type":"cosmos-sdk/MsgGrantFeeAllowance","value":{"allowance":{"type":"cosmos-sdk/BasicAllowance","value":{"spend_limit":[{"amount":"555","denom":"atom"}]}},"grantee":"cosmos1vaexzmn5v4j47h6lta047h6lta047h6lwfkh0k","granter":"cosmos1vaexzmn5v4e97h6lta047h6lta047h6l3kck0u"}}`,
+		},
+		"revoke": {
+			msg:     &revoke,
+			decoded: &types.MsgRevokeFeeAllowance{},
+			msgType: types.TypeMsgRevokeFeeAllowance,
+			expSign: `{"type":"cosmos-sdk/MsgRevokeFeeAllowance","value":{"grantee":"cosmos1vaexzmn5v4j47h6lta047h6lta047h6lwfkh0k","granter":"cosmos1vaexzmn5v4e97h6lta047h6lta047h6l3kck0u"}}`,
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, types.RouterKey, tc.msg.Route())
+			require.Equal(t, tc.msgType, tc.msg.Type())
+			require.Equal(t, []sdk.AccAddress{granter}, tc.msg.GetSigners())
+
+			// the sign bytes do not depend on how the msg was obtained
+			require.Equal(t, tc.expSign, string(tc.msg.GetSignBytes()))
+			require.NoError(t, types.ModuleCdc.UnmarshalJSON(tc.msg.GetSignBytes(), tc.decoded))
+			require.Equal(t, tc.expSign, string(tc.decoded.GetSignBytes()))
+		})
+	}
+}
+
+func TestMsgMarshalAppend(t *testing.T) {
+	granter := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	grantee := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+
+	msg, err := types.NewMsgGrantFeeAllowance(&types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 555))}, granter, grantee)
+	require.NoError(t, err)
+
+	expected, err := msg.Marshal()
+	require.NoError(t, err)
+
+	// appends to the existing contents, growing the buffer when needed
+	bz, err := msg.MarshalAppend([]byte("prefix"))
+	require.NoError(t, err)
+	require.Equal(t, append([]byte("prefix"), expected...), bz)
+
+	// reuses the buffer when it is large enough
+	buf := make([]byte, 0, len(expected))
+	bz, err = msg.MarshalAppend(buf)
+	require.NoError(t, err)
+	require.Equal(t, expected, bz)
+	require.True(t, &buf[:1][0] == &bz[0])
+
+	revoke := types.NewMsgRevokeFeeAllowance(granter, grantee)
+	expected, err = revoke.Marshal()
+	require.NoError(t, err)
+	bz, err = revoke.MarshalAppend(nil)
+	require.NoError(t, err)
+	require.Equal(t, expected, bz)
+}
+
+func BenchmarkMsgGrantFeeAllowanceMarshal(b *testing.B) {
+	granter := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	grantee := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+
+	msg, err := types.NewMsgGrantFeeAllowance(&types.BasicAllowance{SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 555))}, granter, grantee)
+	require.NoError(b, err)
+
+	b.Run("Marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := msg.Marshal(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("MarshalAppend", func(b *testing.B) {
+		b.ReportAllocs()
+		var buf []byte
+		for i := 0; i < b.N; i++ {
+			if buf, err = msg.MarshalAppend(buf[:0]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
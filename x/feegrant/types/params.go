@@ -0,0 +1,113 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	paramtypes "github.com/cosmos/cosmos-sdk/x/params/types"
+)
+
+// Default parameter values
+const (
+	DefaultMaxGrantsPerGranter         uint64 = 1000
+	DefaultAllowGrantToUnknownAccounts        = true
+	DefaultExpiryWarningWindow                = time.Duration(0)
+)
+
+// Parameter keys
+var (
+	KeyMaxGrantsPerGranter         = []byte("MaxGrantsPerGranter")
+	KeyAllowGrantToUnknownAccounts = []byte("AllowGrantToUnknownAccounts")
+	KeyExpiryWarningWindow         = []byte("ExpiryWarningWindow")
+)
+
+var _ paramtypes.ParamSet = &Params{}
+
+// NewParams creates a new Params object
+func NewParams(maxGrantsPerGranter uint64, allowGrantToUnknownAccounts bool, expiryWarningWindow time.Duration) Params {
+	return Params{
+		MaxGrantsPerGranter:         maxGrantsPerGranter,
+		AllowGrantToUnknownAccounts: allowGrantToUnknownAccounts,
+		ExpiryWarningWindow:         expiryWarningWindow,
+	}
+}
+
+// ParamKeyTable for feegrant module
+func ParamKeyTable() paramtypes.KeyTable {
+	return paramtypes.NewKeyTable().RegisterParamSet(&Params{})
+}
+
+// ParamSetPairs implements the ParamSet interface and returns all the key/value pairs
+// pairs of feegrant module's parameters.
+func (p *Params) ParamSetPairs() paramtypes.ParamSetPairs {
+	return paramtypes.ParamSetPairs{
+		paramtypes.NewParamSetPair(KeyMaxGrantsPerGranter, &p.MaxGrantsPerGranter, validateMaxGrantsPerGranter),
+		paramtypes.NewParamSetPair(KeyAllowGrantToUnknownAccounts, &p.AllowGrantToUnknownAccounts, validateAllowGrantToUnknownAccounts),
+		paramtypes.NewParamSetPair(KeyExpiryWarningWindow, &p.ExpiryWarningWindow, validateExpiryWarningWindow),
+	}
+}
+
+// DefaultParams returns a default set of parameters.
+func DefaultParams() Params {
+	return Params{
+		MaxGrantsPerGranter:         DefaultMaxGrantsPerGranter,
+		AllowGrantToUnknownAccounts: DefaultAllowGrantToUnknownAccounts,
+		ExpiryWarningWindow:         DefaultExpiryWarningWindow,
+	}
+}
+
+// String implements the stringer interface.
+func (p Params) String() string {
+	out, _ := yaml.Marshal(p)
+	return string(out)
+}
+
+// Validate checks that the parameters have valid values.
+func (p Params) Validate() error {
+	if err := validateMaxGrantsPerGranter(p.MaxGrantsPerGranter); err != nil {
+		return err
+	}
+
+	if err := validateAllowGrantToUnknownAccounts(p.AllowGrantToUnknownAccounts); err != nil {
+		return err
+	}
+
+	return validateExpiryWarningWindow(p.ExpiryWarningWindow)
+}
+
+func validateMaxGrantsPerGranter(i interface{}) error {
+	v, ok := i.(uint64)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+
+	if v == 0 {
+		return fmt.Errorf("invalid max grants per granter: %d", v)
+	}
+
+	return nil
+}
+
+func validateAllowGrantToUnknownAccounts(i interface{}) error {
+	_, ok := i.(bool)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+
+	return nil
+}
+
+func validateExpiryWarningWindow(i interface{}) error {
+	v, ok := i.(time.Duration)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+
+	if v < 0 {
+		return fmt.Errorf("expiry warning window must not be negative: %s", v)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package types_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+func TestParamsValidate(t *testing.T) {
+	require.NoError(t, types.DefaultParams().Validate())
+	require.NoError(t, types.NewParams(1, true, 0).Validate())
+	require.NoError(t, types.NewParams(1, false, time.Hour).Validate())
+	require.Error(t, types.NewParams(0, true, 0).Validate())
+	require.Error(t, types.NewParams(1, true, -time.Second).Validate())
+
+	// the param set validators also reject values of the wrong type
+	params := types.DefaultParams()
+	pairs := params.ParamSetPairs()
+	require.Len(t, pairs, 3)
+
+	require.NoError(t, pairs[0].ValidatorFn(params.MaxGrantsPerGranter))
+	require.Error(t, pairs[0].ValidatorFn(uint64(0)))
+	require.Error(t, pairs[0].ValidatorFn(int64(-1)))
+
+	require.NoError(t, pairs[1].ValidatorFn(false))
+	require.Error(t, pairs[1].ValidatorFn("false"))
+
+	require.NoError(t, pairs[2].ValidatorFn(time.Hour))
+	require.Error(t, pairs[2].ValidatorFn(-time.Hour))
+	require.Error(t, pairs[2].ValidatorFn(int64(3600)))
+}
@@ -0,0 +1,268 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+var _ FeeAllowanceI = (*PeriodicFeeAllowance)(nil)
+
+// Accept can use fee payment requested as well as timestamp of the current block
+// to determine whether or not to process this.
+//
+// If it returns an error, the fee payment is rejected, otherwise it is accepted.
+// The FeeAllowance implementation is expected to update it's internal state
+// and will be saved again after an acceptance.
+//
+// If remove is true (regardless of the error), the FeeAllowance will be deleted from storage
+// (eg. when it is used up).
+func (a *PeriodicFeeAllowance) Accept(ctx sdk.Context, fee sdk.Coins, _ []sdk.Msg) (bool, error) {
+	blockTime := ctx.BlockTime()
+
+	if a.Basic.isExpired(ctx.BlockHeight(), blockTime) {
+		return true, sdkerrors.Wrap(ErrFeeLimitExpired, "absolute limit")
+	}
+
+	// an empty fee neither spends nor resets the period
+	if fee.IsZero() {
+		return false, nil
+	}
+
+	a.tryResetPeriod(blockTime)
+
+	// deduct from both the current period and the max amount
+	periodLeft, isNeg := a.PeriodCanSpend.SafeSub(fee)
+	if isNeg {
+		return false, sdkerrors.Wrap(ErrFeeLimitExceeded, "period limit")
+	}
+
+	// an empty basic spend limit means there is no overall cap
+	if a.Basic.SpendLimit.Empty() {
+		a.PeriodCanSpend = periodLeft
+		return false, nil
+	}
+
+	basicLeft, isNeg := a.Basic.SpendLimit.SafeSub(fee)
+	if isNeg {
+		return false, sdkerrors.Wrap(ErrFeeLimitExceeded, "absolute limit")
+	}
+
+	a.PeriodCanSpend = periodLeft
+	a.Basic.SpendLimit = basicLeft
+
+	return basicLeft.IsZero(), nil
+}
+
+// tryResetPeriod will check if the PeriodReset has been hit. If not, it is a no-op.
+// If we hit the reset period, it will top up the PeriodCanSpend amount to
+// min(PeriodSpendLimit, Basic.SpendLimit) so it is never more than the maximum allowed.
+// With Carryover, the coins left unspent are kept instead, see carryover.
+// It will also update the PeriodReset. If we are within one Period, it will update from the
+// last PeriodReset (eg. if you always do one tx per day, it will always reset the same time)
+// If we are more then one period out (eg. no activity in a week), reset is one Period from the execution of this method,
+// unless the allowance is Aligned, see alignPeriodReset.
+func (a *PeriodicFeeAllowance) tryResetPeriod(blockTime time.Time) {
+	if blockTime.Before(a.PeriodReset) {
+		return
+	}
+
+	if a.Carryover {
+		a.PeriodCanSpend = a.carryover(blockTime)
+	} else if _, isNeg := a.Basic.SpendLimit.SafeSub(a.PeriodSpendLimit); isNeg && !a.Basic.SpendLimit.Empty() {
+		// set PeriodCanSpend to the lesser of Basic.SpendLimit and PeriodSpendLimit
+		a.PeriodCanSpend = a.Basic.SpendLimit
+	} else {
+		a.PeriodCanSpend = a.PeriodSpendLimit
+	}
+
+	if a.Aligned {
+		a.alignPeriodReset(blockTime)
+		return
+	}
+
+	// If we are within the period, step from expiration (eg. if you always do one tx per day, it will always reset the same time)
+	// If we are more then one period out (eg. no activity in a week), reset is one period from this time
+	a.PeriodReset = a.PeriodReset.Add(a.Period)
+	if blockTime.After(a.PeriodReset) {
+		a.PeriodReset = blockTime.Add(a.Period)
+	}
+}
+
+// alignPeriodReset moves PeriodReset forward by whole periods to the first
+// boundary after blockTime, so that resets keep happening at the same offset
+// from the original PeriodReset however many periods went by.
+func (a *PeriodicFeeAllowance) alignPeriodReset(blockTime time.Time) {
+	for !blockTime.Before(a.PeriodReset) {
+		// skip all the elapsed periods at once, the difference saturating for
+		// very large gaps, which then take a few more iterations
+		if periods := blockTime.Sub(a.PeriodReset) / a.Period; periods > 0 {
+			a.PeriodReset = a.PeriodReset.Add(periods * a.Period)
+			continue
+		}
+
+		a.PeriodReset = a.PeriodReset.Add(a.Period)
+	}
+}
+
+// carryover returns the PeriodCanSpend of the period starting at blockTime when
+// the unspent coins are carried over: PeriodSpendLimit is added once for the
+// period ending at PeriodReset and once for every full period since, and the
+// result is capped by PeriodCarryoverLimit and Basic.SpendLimit.
+func (a *PeriodicFeeAllowance) carryover(blockTime time.Time) sdk.Coins {
+	periods := 1 + int64(blockTime.Sub(a.PeriodReset)/a.Period)
+
+	canSpend := a.PeriodCanSpend
+	for _, coin := range a.PeriodSpendLimit {
+		canSpend = canSpend.Add(sdk.NewCoin(coin.Denom, coin.Amount.MulRaw(periods)))
+	}
+
+	canSpend = capCoins(canSpend, a.PeriodCarryoverLimit)
+	if !a.Basic.SpendLimit.Empty() {
+		canSpend = capCoins(canSpend, a.Basic.SpendLimit)
+	}
+
+	return canSpend
+}
+
+// capCoins returns coins with every amount lowered to at most its amount in
+// limit, dropping the denoms which are not in limit.
+func capCoins(coins, limit sdk.Coins) sdk.Coins {
+	capped := sdk.NewCoins()
+	for _, coin := range coins {
+		capped = capped.Add(sdk.NewCoin(coin.Denom, sdk.MinInt(coin.Amount, limit.AmountOf(coin.Denom))))
+	}
+	return capped
+}
+
+// CanAccept checks whether Accept would accept fee, see FeeAllowanceI.
+func (a *PeriodicFeeAllowance) CanAccept(ctx sdk.Context, fee sdk.Coins, msgs []sdk.Msg) error {
+	// accept on a copy, Accept only replaces the fields it updates
+	period := *a
+	_, err := period.Accept(ctx, fee, msgs)
+	return err
+}
+
+// ExpiresAt returns the expiry time of the PeriodicFeeAllowance.
+func (a *PeriodicFeeAllowance) ExpiresAt() (*time.Time, error) {
+	return a.Basic.ExpiresAt()
+}
+
+// ExpiresAtHeight returns the expiration height of the PeriodicFeeAllowance.
+func (a *PeriodicFeeAllowance) ExpiresAtHeight() (int64, error) {
+	return a.Basic.ExpiresAtHeight()
+}
+
+// Remaining returns what can still be spent in the period of the block of ctx,
+// taking a period reset into account, or empty coins once expired.
+func (a *PeriodicFeeAllowance) Remaining(ctx sdk.Context) (sdk.Coins, error) {
+	if a.Basic.isExpired(ctx.BlockHeight(), ctx.BlockTime()) {
+		return sdk.Coins{}, nil
+	}
+
+	// reset a copy, the allowance itself is only updated on Accept
+	period := *a
+	period.tryResetPeriod(ctx.BlockTime())
+
+	if a.Basic.SpendLimit.Empty() {
+		return period.PeriodCanSpend, nil
+	}
+
+	return capCoins(period.PeriodCanSpend, period.Basic.SpendLimit), nil
+}
+
+// RemainingSpendLimit returns the SpendLimit of the basic allowance, see
+// FeeAllowanceI.
+func (a *PeriodicFeeAllowance) RemainingSpendLimit() (sdk.Coins, error) {
+	return a.Basic.SpendLimit, nil
+}
+
+// ValidateBasic implements FeeAllowance and enforces basic sanity checks
+func (a PeriodicFeeAllowance) ValidateBasic() error {
+	if err := a.Basic.ValidateBasic(); err != nil {
+		return err
+	}
+
+	if !a.PeriodSpendLimit.IsValid() {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidCoins, "spend amount is invalid: %s", a.PeriodSpendLimit)
+	}
+	if !a.PeriodSpendLimit.IsAllPositive() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, "spend limit must be positive")
+	}
+	if !a.PeriodCanSpend.IsValid() {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidCoins, "can spend amount is invalid: %s", a.PeriodCanSpend)
+	}
+	// We allow 0 for CanSpend
+	if a.PeriodCanSpend.IsAnyNegative() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, "can spend must not be negative")
+	}
+
+	// ensure PeriodSpendLimit can be subtracted from total (same coin types)
+	if !a.Basic.SpendLimit.Empty() && !a.PeriodSpendLimit.DenomsSubsetOf(a.Basic.SpendLimit) {
+		return sdkerrors.Wrap(ErrFeeLimitExceeded, "period spend limit has different currency than basic spend limit")
+	}
+
+	if a.Carryover {
+		if !a.PeriodCarryoverLimit.IsValid() {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidCoins, "carryover limit is invalid: %s", a.PeriodCarryoverLimit)
+		}
+		if !a.PeriodCarryoverLimit.IsAllGTE(a.PeriodSpendLimit) {
+			return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, "carryover limit must be at least the period spend limit")
+		}
+	} else if !a.PeriodCarryoverLimit.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "carryover limit requires carryover")
+	}
+
+	// check times
+	if a.Period <= 0 {
+		return sdkerrors.Wrap(ErrInvalidDuration, "non-positive period")
+	}
+	if a.Aligned && a.PeriodReset.Before(MinExpiration) {
+		return sdkerrors.Wrapf(ErrInvalidDuration, "aligned period reset must not be before %s", MinExpiration)
+	}
+
+	return nil
+}
+
+// validateSpendLimits checks that PeriodSpendLimit does not exceed the basic
+// SpendLimit, if there is one. This is not part of ValidateBasic, as spending
+// from the allowance brings its SpendLimit below the PeriodSpendLimit.
+func (a PeriodicFeeAllowance) validateSpendLimits() error {
+	if !a.Basic.SpendLimit.Empty() && !a.Basic.SpendLimit.IsAllGTE(a.PeriodSpendLimit) {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidCoins, "period spend limit %s exceeds basic spend limit %s", a.PeriodSpendLimit, a.Basic.SpendLimit)
+	}
+
+	return nil
+}
+
+// String implements the Stringer interface, e.g.
+// PeriodicFeeAllowance{basic: BasicAllowance{...}, period: 24h0m0s,
+// period_spend_limit: 10uatom, period_can_spend: 5uatom, period_reset: 2024-01-01T00:00:00Z}.
+// The carryover and alignment options are only shown when enabled.
+func (a PeriodicFeeAllowance) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "PeriodicFeeAllowance{basic: %s, period: %s, period_spend_limit: %s, period_can_spend: %s, period_reset: %s",
+		a.Basic, a.Period, a.PeriodSpendLimit, formatCoins(a.PeriodCanSpend), a.PeriodReset.UTC().Format(time.RFC3339Nano))
+
+	if a.Carryover {
+		fmt.Fprintf(&b, ", period_carryover_limit: %s", a.PeriodCarryoverLimit)
+	}
+	if a.Aligned {
+		b.WriteString(", aligned")
+	}
+	b.WriteString("}")
+
+	return b.String()
+}
+
+// formatCoins formats coins, writing empty coins as 0 rather than as nothing.
+func formatCoins(coins sdk.Coins) string {
+	if coins.Empty() {
+		return "0"
+	}
+
+	return coins.String()
+}
@@ -0,0 +1,653 @@
+package types_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+func TestPeriodicFeeValidAllow(t *testing.T) {
+	app := simapp.Setup(false)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{
+		Time: time.Now(),
+	})
+
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	smallAtom := sdk.NewCoins(sdk.NewInt64Coin("atom", 43))
+	leftAtom := sdk.NewCoins(sdk.NewInt64Coin("atom", 512))
+	oneAtom := sdk.NewCoins(sdk.NewInt64Coin("atom", 1))
+	eth := sdk.NewCoins(sdk.NewInt64Coin("eth", 1))
+
+	now := ctx.BlockTime()
+	oneHour := now.Add(1 * time.Hour)
+	twoHours := now.Add(2 * time.Hour)
+	tenMinutes := time.Duration(10) * time.Minute
+
+	cases := map[string]struct {
+		allow types.PeriodicFeeAllowance
+		// all other checks are ignored if valid=false
+		fee           sdk.Coins
+		blockTime     time.Time
+		valid         bool // all other checks are ignored if valid=false
+		accept        bool
+		remove        bool
+		remains       sdk.Coins
+		remainsPeriod sdk.Coins
+		periodReset   time.Time
+	}{
+		"empty": {
+			allow: types.PeriodicFeeAllowance{},
+			valid: false,
+		},
+		"only basic": {
+			allow: types.PeriodicFeeAllowance{
+				Basic: types.BasicAllowance{
+					SpendLimit: atom,
+					Expiration: &oneHour,
+				},
+			},
+			valid: false,
+		},
+		"unlimited basic": {
+			allow: types.PeriodicFeeAllowance{
+				Period:           tenMinutes,
+				PeriodSpendLimit: smallAtom,
+				PeriodReset:      now,
+			},
+			valid:         true,
+			fee:           oneAtom,
+			blockTime:     now,
+			accept:        true,
+			remove:        false,
+			remainsPeriod: smallAtom.Sub(oneAtom),
+			remains:       nil,
+			periodReset:   now.Add(tenMinutes),
+		},
+		"mismatched currencies": {
+			allow: types.PeriodicFeeAllowance{
+				Basic: types.BasicAllowance{
+					SpendLimit: atom,
+					Expiration: &oneHour,
+				},
+				Period:           tenMinutes,
+				PeriodSpendLimit: eth,
+			},
+			valid: false,
+		},
+		"negative period": {
+			allow: types.PeriodicFeeAllowance{
+				Basic: types.BasicAllowance{
+					SpendLimit: atom,
+				},
+				Period:           -tenMinutes,
+				PeriodSpendLimit: smallAtom,
+			},
+			valid: false,
+		},
+		"same period": {
+			allow: types.PeriodicFeeAllowance{
+				Basic: types.BasicAllowance{
+					SpendLimit: atom,
+					Expiration: &twoHours,
+				},
+				Period:           tenMinutes,
+				PeriodReset:      now.Add(1 * time.Hour),
+				PeriodSpendLimit: leftAtom,
+				PeriodCanSpend:   smallAtom,
+			},
+			valid:         true,
+			fee:           smallAtom,
+			blockTime:     now,
+			accept:        true,
+			remove:        false,
+			remainsPeriod: nil,
+			remains:       leftAtom,
+			periodReset:   now.Add(1 * time.Hour),
+		},
+		"step one period": {
+			allow: types.PeriodicFeeAllowance{
+				Basic: types.BasicAllowance{
+					SpendLimit: atom,
+					Expiration: &twoHours,
+				},
+				Period:           tenMinutes,
+				PeriodReset:      now,
+				PeriodSpendLimit: leftAtom,
+			},
+			valid:         true,
+			fee:           leftAtom,
+			blockTime:     now.Add(1 * time.Hour),
+			accept:        true,
+			remove:        false,
+			remainsPeriod: nil,
+			remains:       smallAtom,
+			periodReset:   oneHour.Add(tenMinutes), // one step from last reset, not now
+		},
+		"step limited by global allowance": {
+			allow: types.PeriodicFeeAllowance{
+				Basic: types.BasicAllowance{
+					SpendLimit: smallAtom,
+					Expiration: &twoHours,
+				},
+				Period:           tenMinutes,
+				PeriodReset:      now,
+				PeriodSpendLimit: atom,
+			},
+			valid:         true,
+			fee:           oneAtom,
+			blockTime:     oneHour,
+			accept:        true,
+			remove:        false,
+			remainsPeriod: smallAtom.Sub(oneAtom),
+			remains:       smallAtom.Sub(oneAtom),
+			periodReset:   oneHour.Add(tenMinutes), // one step from last reset, not now
+		},
+		"period reset no spend limit": {
+			allow: types.PeriodicFeeAllowance{
+				Basic: types.BasicAllowance{
+					SpendLimit: atom,
+				},
+				Period:           tenMinutes,
+				PeriodReset:      now,
+				PeriodSpendLimit: atom,
+			},
+			valid:         true,
+			fee:           atom,
+			blockTime:     oneHour,
+			accept:        true,
+			remove:        true,
+			remainsPeriod: nil,
+			remains:       nil,
+			periodReset:   oneHour.Add(tenMinutes),
+		},
+		"expired": {
+			allow: types.PeriodicFeeAllowance{
+				Basic: types.BasicAllowance{
+					SpendLimit: atom,
+					Expiration: &now,
+				},
+				Period:           time.Hour,
+				PeriodSpendLimit: smallAtom,
+			},
+			valid:     true,
+			fee:       smallAtom,
+			blockTime: oneHour,
+			accept:    false,
+			remove:    true,
+		},
+		"over period limit": {
+			allow: types.PeriodicFeeAllowance{
+				Basic: types.BasicAllowance{
+					SpendLimit: atom,
+					Expiration: &now,
+				},
+				Period:           time.Hour,
+				PeriodReset:      now.Add(1 * time.Hour),
+				PeriodSpendLimit: leftAtom,
+				PeriodCanSpend:   smallAtom,
+			},
+			valid:     true,
+			fee:       leftAtom,
+			blockTime: now,
+			accept:    false,
+			remove:    false,
+		},
+		"fee exceeds period but fits total": {
+			allow: types.PeriodicFeeAllowance{
+				Basic: types.BasicAllowance{
+					SpendLimit: atom,
+				},
+				Period:           time.Hour,
+				PeriodReset:      oneHour,
+				PeriodSpendLimit: smallAtom,
+				PeriodCanSpend:   smallAtom,
+			},
+			valid:     true,
+			fee:       leftAtom,
+			blockTime: now,
+			accept:    false,
+			remove:    false,
+		},
+	}
+
+	for name, stc := range cases {
+		tc := stc // to make scopelint happy
+		t.Run(name, func(t *testing.T) {
+			err := tc.allow.ValidateBasic()
+			if !tc.valid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			ctx := app.BaseApp.NewContext(false, tmproto.Header{}).WithBlockTime(tc.blockTime)
+			// now try to deduct
+			remove, err := tc.allow.Accept(ctx, tc.fee, []sdk.Msg{})
+			if !tc.accept {
+				require.Error(t, err)
+				require.Equal(t, tc.remove, remove)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Equal(t, tc.remove, remove)
+			if !remove {
+				assert.Equal(t, tc.remains, tc.allow.Basic.SpendLimit)
+				assert.Equal(t, tc.remainsPeriod, tc.allow.PeriodCanSpend)
+				assert.Equal(t, tc.periodReset.String(), tc.allow.PeriodReset.String())
+			}
+		})
+	}
+}
+
+func TestPeriodicFeeRollover(t *testing.T) {
+	app := simapp.Setup(false)
+	now := time.Now()
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{}).WithBlockTime(now)
+
+	hour := time.Hour
+	perPeriod := sdk.NewCoins(sdk.NewInt64Coin("atom", 10))
+	spend := sdk.NewCoins(sdk.NewInt64Coin("atom", 7))
+
+	allow := types.PeriodicFeeAllowance{
+		Basic: types.BasicAllowance{
+			SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 100)),
+		},
+		Period:           hour,
+		PeriodSpendLimit: perPeriod,
+		PeriodCanSpend:   perPeriod,
+		PeriodReset:      now.Add(hour),
+	}
+	require.NoError(t, allow.ValidateBasic())
+
+	// first period: 7 of 10 is fine, a second 7 exceeds what is left
+	remove, err := allow.Accept(ctx, spend, nil)
+	require.NoError(t, err)
+	require.False(t, remove)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("atom", 3)), allow.PeriodCanSpend)
+
+	_, err = allow.Accept(ctx, spend, nil)
+	require.Error(t, err)
+
+	// second period: the period allowance is topped up again
+	ctx = ctx.WithBlockTime(now.Add(hour))
+	remove, err = allow.Accept(ctx, spend, nil)
+	require.NoError(t, err)
+	require.False(t, remove)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("atom", 3)), allow.PeriodCanSpend)
+	require.Equal(t, now.Add(2*hour), allow.PeriodReset)
+
+	// third period: the reset steps from the previous reset time
+	ctx = ctx.WithBlockTime(now.Add(2*hour + time.Minute))
+	remove, err = allow.Accept(ctx, spend, nil)
+	require.NoError(t, err)
+	require.False(t, remove)
+	require.Equal(t, now.Add(3*hour), allow.PeriodReset)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("atom", 79)), allow.Basic.SpendLimit)
+}
+
+func TestPeriodicFeeCarryover(t *testing.T) {
+	app := simapp.Setup(false)
+	now := time.Now()
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: now})
+
+	atoms := func(amount int64) sdk.Coins {
+		return sdk.NewCoins(sdk.NewInt64Coin("atom", amount))
+	}
+	period := 10 * time.Minute
+
+	// each step spends fee at the given time, expecting what can still be spent
+	// in the period afterwards
+	type step struct {
+		blockTime   time.Time
+		fee         sdk.Coins
+		expCanSpend sdk.Coins
+	}
+
+	cases := map[string]struct {
+		allow types.PeriodicFeeAllowance
+		steps []step
+	}{
+		"no carryover resets to the period limit": {
+			allow: types.PeriodicFeeAllowance{
+				Period:           period,
+				PeriodSpendLimit: atoms(100),
+				PeriodCanSpend:   atoms(100),
+				PeriodReset:      now.Add(period),
+			},
+			steps: []step{
+				{now, atoms(30), atoms(70)},
+				{now.Add(period), atoms(1), atoms(99)},
+				{now.Add(2 * period), atoms(1), atoms(99)},
+			},
+		},
+		"carryover adds the unspent coins to the next periods": {
+			allow: types.PeriodicFeeAllowance{
+				Period:               period,
+				PeriodSpendLimit:     atoms(100),
+				PeriodCanSpend:       atoms(100),
+				PeriodReset:          now.Add(period),
+				Carryover:            true,
+				PeriodCarryoverLimit: atoms(1000),
+			},
+			steps: []step{
+				{now, atoms(30), atoms(70)},
+				{now.Add(period), atoms(1), atoms(169)},
+				{now.Add(2 * period), atoms(1), atoms(268)},
+			},
+		},
+		"carryover is capped by the carryover limit": {
+			allow: types.PeriodicFeeAllowance{
+				Period:               period,
+				PeriodSpendLimit:     atoms(100),
+				PeriodCanSpend:       atoms(100),
+				PeriodReset:          now.Add(period),
+				Carryover:            true,
+				PeriodCarryoverLimit: atoms(250),
+			},
+			steps: []step{
+				{now, atoms(30), atoms(70)},
+				{now.Add(period), atoms(1), atoms(169)},
+				{now.Add(2 * period), atoms(1), atoms(249)},
+			},
+		},
+		"carryover covers every elapsed period": {
+			allow: types.PeriodicFeeAllowance{
+				Period:               period,
+				PeriodSpendLimit:     atoms(100),
+				PeriodCanSpend:       atoms(100),
+				PeriodReset:          now.Add(period),
+				Carryover:            true,
+				PeriodCarryoverLimit: atoms(1000),
+			},
+			steps: []step{
+				{now, atoms(30), atoms(70)},
+				{now.Add(3*period + time.Minute), atoms(1), atoms(369)},
+			},
+		},
+		"carryover is capped by the basic spend limit": {
+			allow: types.PeriodicFeeAllowance{
+				Basic:                types.BasicAllowance{SpendLimit: atoms(150)},
+				Period:               period,
+				PeriodSpendLimit:     atoms(100),
+				PeriodCanSpend:       atoms(100),
+				PeriodReset:          now.Add(period),
+				Carryover:            true,
+				PeriodCarryoverLimit: atoms(1000),
+			},
+			steps: []step{
+				{now, atoms(10), atoms(90)},
+				{now.Add(period), atoms(1), atoms(139)},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, tc.allow.ValidateBasic())
+
+			for _, s := range tc.steps {
+				remove, err := tc.allow.Accept(ctx.WithBlockTime(s.blockTime), s.fee, nil)
+				require.NoError(t, err)
+				require.False(t, remove)
+				require.Equal(t, s.expCanSpend, tc.allow.PeriodCanSpend)
+			}
+		})
+	}
+}
+
+func TestPeriodicFeeCarryoverValidateBasic(t *testing.T) {
+	allowance := types.PeriodicFeeAllowance{
+		Period:           time.Hour,
+		PeriodSpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 100)),
+	}
+
+	// carryover requires a limit covering the period spend limit
+	allowance.Carryover = true
+	require.Error(t, allowance.ValidateBasic())
+	allowance.PeriodCarryoverLimit = sdk.NewCoins(sdk.NewInt64Coin("atom", 99))
+	require.Error(t, allowance.ValidateBasic())
+	allowance.PeriodCarryoverLimit = sdk.NewCoins(sdk.NewInt64Coin("atom", 100))
+	require.NoError(t, allowance.ValidateBasic())
+
+	// a carryover limit requires carryover
+	allowance.Carryover = false
+	require.Error(t, allowance.ValidateBasic())
+}
+
+func TestPeriodicFeeValidateNewAllowance(t *testing.T) {
+	perPeriod := sdk.NewCoins(sdk.NewInt64Coin("atom", 100))
+
+	cases := map[string]struct {
+		spendLimit sdk.Coins
+		valid      bool
+	}{
+		"within limit": {
+			spendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 100)),
+			valid:      true,
+		},
+		"exceeds limit": {
+			spendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 99)),
+			valid:      false,
+		},
+		"unlimited basic": {
+			valid: true,
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			allowance := &types.PeriodicFeeAllowance{
+				Basic:            types.BasicAllowance{SpendLimit: tc.spendLimit},
+				Period:           time.Hour,
+				PeriodSpendLimit: perPeriod,
+			}
+			// a granted allowance spends its limit down below the period limit
+			require.NoError(t, allowance.ValidateBasic())
+
+			wrapped, err := types.NewAllowedMsgAllowance(allowance, []string{"/cosmos.bank.v1beta1.MsgSend"})
+			require.NoError(t, err)
+
+			for _, a := range []types.FeeAllowanceI{allowance, wrapped} {
+				err := types.ValidateNewAllowance(a)
+				if tc.valid {
+					require.NoError(t, err)
+				} else {
+					require.True(t, errors.Is(err, sdkerrors.ErrInvalidCoins))
+				}
+			}
+		})
+	}
+}
+
+func TestPeriodicFeeEmptyFee(t *testing.T) {
+	app := simapp.Setup(false)
+	now := time.Now()
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: now})
+
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	perPeriod := sdk.NewCoins(sdk.NewInt64Coin("atom", 10))
+	canSpend := sdk.NewCoins(sdk.NewInt64Coin("atom", 3))
+
+	for _, fee := range []sdk.Coins{nil, {}, {sdk.NewInt64Coin("atom", 0)}} {
+		allow := types.PeriodicFeeAllowance{
+			Basic:            types.BasicAllowance{SpendLimit: atom},
+			Period:           time.Hour,
+			PeriodSpendLimit: perPeriod,
+			PeriodCanSpend:   canSpend,
+			PeriodReset:      now.Add(-time.Minute),
+		}
+
+		// the period is due for a reset, which an empty fee must not trigger
+		remove, err := allow.Accept(ctx, fee, nil)
+		require.NoError(t, err)
+		require.False(t, remove)
+		require.Equal(t, now.Add(-time.Minute), allow.PeriodReset)
+		require.Equal(t, canSpend, allow.PeriodCanSpend)
+		require.Equal(t, atom, allow.Basic.SpendLimit)
+	}
+}
+
+func TestPeriodicFeeExpirationHeight(t *testing.T) {
+	app := simapp.Setup(false)
+	now := time.Now()
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: now, Height: 100})
+
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	fee := sdk.NewCoins(sdk.NewInt64Coin("atom", 5))
+	allow := types.PeriodicFeeAllowance{
+		Basic:            types.BasicAllowance{SpendLimit: atom, ExpirationHeight: 100},
+		Period:           time.Hour,
+		PeriodSpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 10)),
+		PeriodCanSpend:   sdk.NewCoins(sdk.NewInt64Coin("atom", 10)),
+		PeriodReset:      now.Add(time.Hour),
+	}
+	require.NoError(t, allow.ValidateBasic())
+
+	remove, err := allow.Accept(ctx, fee, nil)
+	require.NoError(t, err)
+	require.False(t, remove)
+
+	height, err := allow.ExpiresAtHeight()
+	require.NoError(t, err)
+	require.Equal(t, int64(100), height)
+
+	remaining, err := allow.Remaining(ctx)
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("atom", 5)), remaining)
+
+	remaining, err = allow.Remaining(ctx.WithBlockHeight(101))
+	require.NoError(t, err)
+	require.Empty(t, remaining)
+
+	remove, err = allow.Accept(ctx.WithBlockHeight(101), fee, nil)
+	require.True(t, errors.Is(err, types.ErrFeeLimitExpired))
+	require.True(t, remove)
+}
+
+func TestPeriodicFeeAligned(t *testing.T) {
+	app := simapp.Setup(false)
+	atoms := func(amount int64) sdk.Coins {
+		return sdk.NewCoins(sdk.NewInt64Coin("atom", amount))
+	}
+
+	// granted mid-day, with resets anchored at midnight UTC
+	day := 24 * time.Hour
+	midnight := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	granted := midnight.Add(-10 * time.Hour)
+
+	// each step spends fee at the given time, expecting what can still be spent
+	// in the period and the next reset afterwards
+	type step struct {
+		blockTime   time.Time
+		fee         sdk.Coins
+		expCanSpend sdk.Coins
+		expReset    time.Time
+	}
+
+	cases := map[string]struct {
+		aligned bool
+		steps   []step
+	}{
+		"aligned": {
+			aligned: true,
+			steps: []step{
+				{granted, atoms(30), atoms(70), midnight},
+				{midnight.Add(3 * time.Hour), atoms(1), atoms(99), midnight.Add(day)},
+				// several periods without activity
+				{midnight.Add(4*day + 5*time.Hour), atoms(1), atoms(99), midnight.Add(5 * day)},
+				// right on a boundary
+				{midnight.Add(5 * day), atoms(1), atoms(99), midnight.Add(6 * day)},
+			},
+		},
+		"not aligned": {
+			steps: []step{
+				{granted, atoms(30), atoms(70), midnight},
+				{midnight.Add(3 * time.Hour), atoms(1), atoms(99), midnight.Add(day)},
+				{midnight.Add(4*day + 5*time.Hour), atoms(1), atoms(99), midnight.Add(5*day + 5*time.Hour)},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			allow := types.PeriodicFeeAllowance{
+				Period:           day,
+				PeriodSpendLimit: atoms(100),
+				PeriodCanSpend:   atoms(100),
+				PeriodReset:      midnight,
+				Aligned:          tc.aligned,
+			}
+			require.NoError(t, allow.ValidateBasic())
+
+			for i, s := range tc.steps {
+				ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: s.blockTime})
+				remove, err := allow.Accept(ctx, s.fee, nil)
+				require.NoError(t, err, "step %d", i)
+				require.False(t, remove, "step %d", i)
+				require.Equal(t, s.expCanSpend, allow.PeriodCanSpend, "step %d", i)
+				require.True(t, s.expReset.Equal(allow.PeriodReset), "step %d: %s", i, allow.PeriodReset)
+			}
+		})
+	}
+}
+
+func TestPeriodicFeeAlignedLargeGap(t *testing.T) {
+	app := simapp.Setup(false)
+	blockTime := time.Date(2400, 1, 1, 0, 0, 0, 1, time.UTC)
+	ctx := app.BaseApp.NewContext(false, tmproto.Header{Time: blockTime})
+
+	// the gap does not fit in a time.Duration
+	allow := types.PeriodicFeeAllowance{
+		Period:           time.Second,
+		PeriodSpendLimit: sdk.NewCoins(sdk.NewInt64Coin("atom", 100)),
+		PeriodReset:      types.MinExpiration,
+		Aligned:          true,
+	}
+	require.NoError(t, allow.ValidateBasic())
+
+	_, err := allow.Accept(ctx, sdk.NewCoins(sdk.NewInt64Coin("atom", 1)), nil)
+	require.NoError(t, err)
+	require.True(t, blockTime.Truncate(time.Second).Add(time.Second).Equal(allow.PeriodReset), allow.PeriodReset)
+
+	// aligned resets need an anchor
+	allow.PeriodReset = time.Time{}
+	require.Error(t, allow.ValidateBasic())
+}
+
+func TestPeriodicFeeString(t *testing.T) {
+	reset := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	atoms := func(amount int64) sdk.Coins {
+		return sdk.NewCoins(sdk.NewInt64Coin("uatom", amount))
+	}
+
+	allowance := types.PeriodicFeeAllowance{
+		Basic:            types.BasicAllowance{SpendLimit: atoms(100)},
+		Period:           24 * time.Hour,
+		PeriodSpendLimit: atoms(10),
+		PeriodReset:      reset,
+	}
+	require.Equal(t, "PeriodicFeeAllowance{basic: BasicAllowance{spend_limit: 100uatom, expiration: never}, period: 24h0m0s, "+
+		"period_spend_limit: 10uatom, period_can_spend: 0, period_reset: 2024-01-01T00:00:00Z}", allowance.String())
+
+	allowance.PeriodCanSpend = atoms(5)
+	allowance.Carryover = true
+	allowance.PeriodCarryoverLimit = atoms(30)
+	allowance.Aligned = true
+	require.Equal(t, "PeriodicFeeAllowance{basic: BasicAllowance{spend_limit: 100uatom, expiration: never}, period: 24h0m0s, "+
+		"period_spend_limit: 10uatom, period_can_spend: 5uatom, period_reset: 2024-01-01T00:00:00Z, "+
+		"period_carryover_limit: 30uatom, aligned}", fmt.Sprint(&allowance))
+}
@@ -0,0 +1,133 @@
+package types
+
+import (
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/cosmos/cosmos-sdk/codec/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+var (
+	_ types.UnpackInterfacesMessage = &QueryAllowanceResponse{}
+	_ types.UnpackInterfacesMessage = &QueryAllowancesResponse{}
+	_ types.UnpackInterfacesMessage = &QueryAllowancesByGranterResponse{}
+	_ types.UnpackInterfacesMessage = &QueryAllowancesExpiringBeforeResponse{}
+	_ types.UnpackInterfacesMessage = &QueryAllowancesByFilterResponse{}
+	_ types.UnpackInterfacesMessage = &QueryAllowancesStreamResponse{}
+)
+
+// UnpackInterfaces implements UnpackInterfacesMessage.UnpackInterfaces
+func (m *QueryAllowanceResponse) UnpackInterfaces(unpacker types.AnyUnpacker) error {
+	if m.Allowance == nil {
+		return nil
+	}
+
+	return m.Allowance.UnpackInterfaces(unpacker)
+}
+
+// UnpackInterfaces implements UnpackInterfacesMessage.UnpackInterfaces
+func (m *QueryAllowancesResponse) UnpackInterfaces(unpacker types.AnyUnpacker) error {
+	for _, allowance := range m.Allowances {
+		if err := allowance.UnpackInterfaces(unpacker); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnpackInterfaces implements UnpackInterfacesMessage.UnpackInterfaces
+func (m *QueryAllowancesByGranterResponse) UnpackInterfaces(unpacker types.AnyUnpacker) error {
+	for _, allowance := range m.Allowances {
+		if err := allowance.UnpackInterfaces(unpacker); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnpackInterfaces implements UnpackInterfacesMessage.UnpackInterfaces
+func (m *QueryAllowancesExpiringBeforeResponse) UnpackInterfaces(unpacker types.AnyUnpacker) error {
+	for _, allowance := range m.Allowances {
+		if err := allowance.UnpackInterfaces(unpacker); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnpackInterfaces implements UnpackInterfacesMessage.UnpackInterfaces
+func (m *QueryAllowancesByFilterResponse) UnpackInterfaces(unpacker types.AnyUnpacker) error {
+	for _, allowance := range m.Allowances {
+		if err := allowance.UnpackInterfaces(unpacker); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnpackInterfaces implements UnpackInterfacesMessage.UnpackInterfaces
+func (m *QueryAllowancesStreamResponse) UnpackInterfaces(unpacker types.AnyUnpacker) error {
+	for _, allowance := range m.Allowances {
+		if err := allowance.UnpackInterfaces(unpacker); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewAllowanceBreakdown lays out the fields of allowance, and of the allowances
+// it wraps, in an AllowanceBreakdown. An allowance of a type defined outside of
+// this module only has its type set.
+func NewAllowanceBreakdown(allowance FeeAllowanceI) (*AllowanceBreakdown, error) {
+	msg, ok := allowance.(proto.Message)
+	if !ok {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidType, "%T is not a proto message", allowance)
+	}
+
+	breakdown := &AllowanceBreakdown{Type: "/" + proto.MessageName(msg)}
+
+	switch a := allowance.(type) {
+	case *BasicAllowance:
+		breakdown.SpendLimit = a.SpendLimit
+		breakdown.Expiration = a.Expiration
+		breakdown.ExpirationHeight = a.ExpirationHeight
+	case *PeriodicFeeAllowance:
+		period, periodReset := a.Period, a.PeriodReset
+		breakdown.SpendLimit = a.Basic.SpendLimit
+		breakdown.Expiration = a.Basic.Expiration
+		breakdown.ExpirationHeight = a.Basic.ExpirationHeight
+		breakdown.Period = &period
+		breakdown.PeriodSpendLimit = a.PeriodSpendLimit
+		breakdown.PeriodCanSpend = a.PeriodCanSpend
+		breakdown.PeriodReset = &periodReset
+		breakdown.Carryover = a.Carryover
+		breakdown.PeriodCarryoverLimit = a.PeriodCarryoverLimit
+	case *AllowedMsgAllowance:
+		breakdown.AllowedMessages = a.AllowedMessages
+	case *AllowedDenomAllowance:
+		breakdown.AllowedDenoms = a.AllowedDenoms
+	case *CappedFractionAllowance:
+		breakdown.Fraction = a.Fraction.String()
+	}
+
+	wrapper, ok := allowance.(wrappingAllowance)
+	if !ok {
+		return breakdown, nil
+	}
+
+	inner, err := wrapper.GetAllowance()
+	if err != nil {
+		return nil, err
+	}
+
+	if breakdown.Allowance, err = NewAllowanceBreakdown(inner); err != nil {
+		return nil, err
+	}
+
+	return breakdown, nil
+}
@@ -0,0 +1,168 @@
+package types_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/gateway"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/simapp"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant/types"
+)
+
+// TestQueryResponseJSONDeterministic checks that the JSON of a query response,
+// including its allowance Any, does not depend on the node encoding it.
+func TestQueryResponseJSONDeterministic(t *testing.T) {
+	granter := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	grantee := sdk.AccAddress(ed25519.GenPrivKey().PubKey().Address())
+	exp := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	coins := sdk.NewCoins(sdk.NewInt64Coin("atom", 555), sdk.NewInt64Coin("eth", 123), sdk.NewInt64Coin("stake", 1))
+	periodic := &types.PeriodicFeeAllowance{
+		Basic:            types.BasicAllowance{SpendLimit: coins, Expiration: &exp},
+		Period:           time.Hour,
+		PeriodSpendLimit: coins,
+		PeriodCanSpend:   coins,
+		PeriodReset:      exp.Add(-time.Hour),
+	}
+	filtered, err := types.NewAllowedMsgAllowance(periodic, []string{"/cosmos.gov.v1beta1.MsgVote", "/cosmos.bank.v1beta1.MsgSend"})
+	require.NoError(t, err)
+
+	for name, allowance := range map[string]types.FeeAllowanceI{
+		"basic":    &types.BasicAllowance{SpendLimit: coins, Expiration: &exp},
+		"periodic": periodic,
+		"filtered": filtered,
+	} {
+		allowance := allowance
+		t.Run(name, func(t *testing.T) {
+			// every call builds its own codec, as two different nodes would
+			marshal := func() ([]byte, []byte) {
+				encCfg := simapp.MakeTestEncodingConfig()
+
+				grant, err := types.NewFeeAllowanceGrant(granter, grantee, allowance)
+				require.NoError(t, err)
+				res := &types.QueryAllowanceResponse{Allowance: &grant}
+
+				bz, err := encCfg.Marshaler.MarshalJSON(res)
+				require.NoError(t, err)
+
+				// the gRPC gateway marshaler, configured as by the API server
+				gw := &gateway.JSONPb{EmitDefaults: true, Indent: "  ", OrigName: true, AnyResolver: encCfg.InterfaceRegistry}
+				gwBz, err := gw.Marshal(res)
+				require.NoError(t, err)
+
+				return bz, gwBz
+			}
+
+			bz1, gwBz1 := marshal()
+			bz2, gwBz2 := marshal()
+			require.Equal(t, string(bz1), string(bz2))
+			require.Equal(t, string(gwBz1), string(gwBz2))
+
+			// the coins are always listed in their sorted order
+			require.Regexp(t, `(?s)"atom".*"eth".*"stake"`, string(gwBz1))
+		})
+	}
+}
+
+func TestNewAllowanceBreakdown(t *testing.T) {
+	exp := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+	reset := exp.Add(-time.Hour)
+	period := time.Hour
+	atom := sdk.NewCoins(sdk.NewInt64Coin("atom", 555))
+	small := sdk.NewCoins(sdk.NewInt64Coin("atom", 10))
+
+	basic := &types.BasicAllowance{SpendLimit: atom, Expiration: &exp}
+	basicBreakdown := &types.AllowanceBreakdown{
+		Type:       "/cosmos.feegrant.v1beta1.BasicAllowance",
+		SpendLimit: atom,
+		Expiration: &exp,
+	}
+
+	periodic := &types.PeriodicFeeAllowance{
+		Basic:                types.BasicAllowance{SpendLimit: atom, ExpirationHeight: 100},
+		Period:               period,
+		PeriodSpendLimit:     small,
+		PeriodCanSpend:       small,
+		PeriodReset:          reset,
+		Carryover:            true,
+		PeriodCarryoverLimit: small.Add(small...),
+	}
+	periodicBreakdown := &types.AllowanceBreakdown{
+		Type:                 "/cosmos.feegrant.v1beta1.PeriodicFeeAllowance",
+		SpendLimit:           atom,
+		ExpirationHeight:     100,
+		Period:               &period,
+		PeriodSpendLimit:     small,
+		PeriodCanSpend:       small,
+		PeriodReset:          &reset,
+		Carryover:            true,
+		PeriodCarryoverLimit: small.Add(small...),
+	}
+
+	filtered, err := types.NewAllowedMsgAllowance(periodic, []string{"/cosmos.gov.v1beta1.MsgVote"})
+	require.NoError(t, err)
+	denoms, err := types.NewAllowedDenomAllowance(basic, []string{"atom"})
+	require.NoError(t, err)
+	fraction, err := types.NewCappedFractionAllowance(filtered, sdk.NewDecWithPrec(5, 1))
+	require.NoError(t, err)
+
+	filteredBreakdown := &types.AllowanceBreakdown{
+		Type:            "/cosmos.feegrant.v1beta1.AllowedMsgAllowance",
+		AllowedMessages: []string{"/cosmos.gov.v1beta1.MsgVote"},
+		Allowance:       periodicBreakdown,
+	}
+
+	cases := map[string]struct {
+		allowance types.FeeAllowanceI
+		expected  *types.AllowanceBreakdown
+	}{
+		"basic": {
+			allowance: basic,
+			expected:  basicBreakdown,
+		},
+		"periodic": {
+			allowance: periodic,
+			expected:  periodicBreakdown,
+		},
+		"filtered": {
+			allowance: filtered,
+			expected:  filteredBreakdown,
+		},
+		"denoms": {
+			allowance: denoms,
+			expected: &types.AllowanceBreakdown{
+				Type:          "/cosmos.feegrant.v1beta1.AllowedDenomAllowance",
+				AllowedDenoms: []string{"atom"},
+				Allowance:     basicBreakdown,
+			},
+		},
+		"fraction": {
+			allowance: fraction,
+			expected: &types.AllowanceBreakdown{
+				Type:      "/cosmos.feegrant.v1beta1.CappedFractionAllowance",
+				Fraction:  "0.500000000000000000",
+				Allowance: filteredBreakdown,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			breakdown, err := types.NewAllowanceBreakdown(tc.allowance)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, breakdown)
+
+			// the gateway serves the breakdown as plain JSON, without any Any
+			gw := &gateway.JSONPb{EmitDefaults: true, OrigName: true}
+			bz, err := gw.Marshal(&types.QueryAllowanceTypedResponse{Allowance: breakdown})
+			require.NoError(t, err)
+			require.NotContains(t, string(bz), "@type")
+			require.Contains(t, string(bz), `"type":"`+tc.expected.Type+`"`)
+		})
+	}
+}
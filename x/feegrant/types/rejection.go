@@ -0,0 +1,35 @@
+package types
+
+import (
+	"errors"
+)
+
+// NewSponsorshipRejection returns the record of a fee allowance refusing the
+// fees of a tx run at height with err, e.g. as returned by UseGrantedFees.
+func NewSponsorshipRejection(err error, height int64, simulated bool) SponsorshipRejection {
+	return SponsorshipRejection{
+		Reason:    RejectionReasonOf(err),
+		Log:       err.Error(),
+		Height:    height,
+		Simulated: simulated,
+	}
+}
+
+// RejectionReasonOf returns the reason why a fee allowance refused the fees
+// of a tx with err.
+func RejectionReasonOf(err error) RejectionReason {
+	switch {
+	case errors.Is(err, ErrNoAllowance):
+		return RejectionNoAllowance
+	case errors.Is(err, ErrFeeLimitExpired):
+		return RejectionExpired
+	case errors.Is(err, ErrFeeLimitExceeded):
+		return RejectionExhausted
+	case errors.Is(err, ErrMessageNotAllowed):
+		return RejectionMsgNotAllowed
+	case errors.Is(err, ErrDenomNotAllowed):
+		return RejectionDenomNotAllowed
+	default:
+		return RejectionUnspecified
+	}
+}